@@ -23,6 +23,24 @@ func TapKubernetesPermissions(ctx context.Context, embedFS embed.FS, kubernetesP
 		filePath = "permissionFiles/permissions-all-namespaces-tap.yaml"
 	}
 
+	permissionsExist := checkPermissionFile(ctx, embedFS, kubernetesProvider, filePath)
+
+	if !config.Config.IsNsRestrictedMode() {
+		isOpenShift, err := kubernetesProvider.IsOpenShift()
+		if err != nil {
+			log.Printf("%v error while checking if the cluster is OpenShift, err: %v", fmt.Sprintf(utils.Red, "✗"), err)
+			return false
+		}
+
+		if isOpenShift {
+			permissionsExist = checkPermissionFile(ctx, embedFS, kubernetesProvider, "permissionFiles/permissions-all-namespaces-openshift.yaml") && permissionsExist
+		}
+	}
+
+	return permissionsExist
+}
+
+func checkPermissionFile(ctx context.Context, embedFS embed.FS, kubernetesProvider *kubernetes.Provider, filePath string) bool {
 	data, err := embedFS.ReadFile(filePath)
 	if err != nil {
 		log.Printf("%v error while checking kubernetes permissions, err: %v", fmt.Sprintf(utils.Red, "✗"), err)
@@ -17,6 +17,7 @@ func ServerConnection(kubernetesProvider *kubernetes.Provider) bool {
 
 	serverUrl := kubernetes.GetLocalhostOnPort(config.Config.Hub.PortForward.SrcPort)
 
+	// A running tap serves the hub behind its own token, so only an unguarded tunnel is found here
 	connector := connect.NewConnector(serverUrl, 1, connect.DefaultTimeout)
 	if err := connector.TestConnection(""); err == nil {
 		log.Printf("%v found Kubeshark server tunnel available and connected successfully to Hub", fmt.Sprintf(utils.Green, "√"))
@@ -25,7 +26,7 @@ func ServerConnection(kubernetesProvider *kubernetes.Provider) bool {
 
 	connectedToHub := false
 
-	if err := checkProxy(serverUrl, kubernetesProvider); err != nil {
+	if err := checkProxy(kubernetesProvider); err != nil {
 		log.Printf("%v couldn't connect to Hub using proxy, err: %v", fmt.Sprintf(utils.Red, "✗"), err)
 	} else {
 		connectedToHub = true
@@ -42,16 +43,21 @@ func ServerConnection(kubernetesProvider *kubernetes.Provider) bool {
 	return connectedToHub
 }
 
-func checkProxy(serverUrl string, kubernetesProvider *kubernetes.Provider) error {
+func checkProxy(kubernetesProvider *kubernetes.Provider) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	httpServer, err := kubernetes.StartProxy(kubernetesProvider, config.Config.Tap.ProxyHost, config.Config.Hub.PortForward.SrcPort, config.Config.Hub.PortForward.DstPort, config.Config.ResourcesNamespace, kubernetes.HubServiceName, cancel)
+	proxyOptions, err := connect.GetProxyOptions()
 	if err != nil {
 		return err
 	}
 
-	connector := connect.NewConnector(serverUrl, connect.DefaultRetries, connect.DefaultTimeout)
+	httpServer, err := kubernetes.StartProxy(kubernetesProvider, proxyOptions, config.Config.Hub.PortForward.SrcPort, config.Config.Hub.PortForward.DstPort, config.Config.ResourcesNamespace, kubernetes.HubServiceName, cancel)
+	if err != nil {
+		return err
+	}
+
+	connector := connect.NewProxyConnector(config.Config.Hub.PortForward.SrcPort, connect.DefaultRetries, connect.DefaultTimeout)
 	if err := connector.TestConnection(""); err != nil {
 		return err
 	}
@@ -67,7 +73,7 @@ func checkPortForward(serverUrl string, kubernetesProvider *kubernetes.Provider)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	podRegex, _ := regexp.Compile(kubernetes.HubPodName)
+	podRegex := regexp.MustCompile(fmt.Sprintf("^%s$", regexp.QuoteMeta(kubernetes.HubPodName)))
 	forwarder, err := kubernetes.NewPortForward(kubernetesProvider, config.Config.ResourcesNamespace, podRegex, config.Config.Tap.GuiPort, config.Config.Tap.GuiPort, ctx, cancel)
 	if err != nil {
 		return err
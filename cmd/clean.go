@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"log"
+
+	"github.com/creasty/defaults"
+	"github.com/kubeshark/kubeshark/config/configStructs"
 	"github.com/spf13/cobra"
 )
 
@@ -15,4 +19,15 @@ var cleanCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(cleanCmd)
+
+	defaultCleanConfig := configStructs.CleanConfig{}
+	if err := defaults.Set(&defaultCleanConfig); err != nil {
+		log.Print(err)
+	}
+
+	cleanCmd.Flags().Bool(configStructs.KeepDataCleanName, defaultCleanConfig.KeepData, "Keep the persistent volume claim with the recorded traffic, so a later tap session can reuse it")
+	cleanCmd.Flags().Bool(configStructs.DryRunCleanName, defaultCleanConfig.DryRun, "List the resources that would be removed, without removing them")
+	cleanCmd.Flags().Bool(configStructs.AllInstancesCleanName, defaultCleanConfig.AllInstances, "Remove the resources of all instances, instead of only those of --instance")
+	cleanCmd.Flags().Bool(configStructs.ForceCleanName, defaultCleanConfig.Force, "Remove the sessions held by others as well")
+	cleanCmd.Flags().Bool(configStructs.AllNamespacesCleanName, defaultCleanConfig.AllNamespaces, "Remove the resources left in all namespaces, including those of sessions that used another resources namespace")
 }
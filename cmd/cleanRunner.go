@@ -1,7 +1,15 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"log"
+
 	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/kubeshark/kubeshark/resources"
+	"github.com/kubeshark/kubeshark/utils"
 )
 
 func performCleanCommand() {
@@ -10,5 +18,31 @@ func performCleanCommand() {
 		return
 	}
 
-	finishKubesharkExecution(kubernetesProvider, config.Config.IsNsRestrictedMode(), config.Config.ResourcesNamespace)
+	cleanUpOptions := resources.CleanUpOptions{
+		KeepData:      config.Config.Clean.KeepData,
+		DryRun:        config.Config.Clean.DryRun,
+		AllNamespaces: config.Config.Clean.AllNamespaces,
+		AllInstances:  config.Config.Clean.AllInstances,
+	}
+
+	if !config.Config.Clean.Force {
+		ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+
+		sessionHolder := resources.NewSessionHolder(kubernetesProvider, false)
+		foreignSessions, err := resources.ListForeignSessions(ctx, kubernetesProvider, config.Config.ResourcesNamespace, cleanUpOptions, sessionHolder)
+		if err != nil {
+			log.Printf(utils.Warning, fmt.Sprintf("Failed to check the holders of the Kubeshark sessions: %v", errormessage.FormatError(err)))
+		} else if len(foreignSessions) > 0 {
+			for _, session := range foreignSessions {
+				log.Printf(utils.Warning, fmt.Sprintf("Kubeshark instance %s in namespace %s is held by %s", session.Instance, session.Namespace, session.Holder))
+			}
+			if !cleanUpOptions.DryRun {
+				log.Printf("Not removing the sessions of others, use --%s to remove them anyway", configStructs.ForceCleanName)
+				return
+			}
+		}
+	}
+
+	finishKubesharkExecution(kubernetesProvider, config.Config.IsNsRestrictedMode(), config.Config.ResourcesNamespace, cleanUpOptions)
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"path"
 	"regexp"
 	"time"
@@ -23,39 +24,204 @@ import (
 	"github.com/kubeshark/kubeshark/kubernetes"
 )
 
-func startProxyReportErrorIfAny(kubernetesProvider *kubernetes.Provider, ctx context.Context, cancel context.CancelFunc, serviceName string, srcPort uint16, dstPort uint16, healthCheck string) {
-	httpServer, err := kubernetes.StartProxy(kubernetesProvider, config.Config.Tap.ProxyHost, srcPort, dstPort, config.Config.ResourcesNamespace, serviceName, cancel)
+// proxyHealthCheckInterval is how often the auto connection checks the API server proxy, to port-forward once
+// it fails.
+const proxyHealthCheckInterval = 30 * time.Second
+
+// startProxyReportErrorIfAny connects a local port to a service with the configured connection strategy,
+// the port-forward strategy forwards to podName instead, since services can't be port-forwarded. Both
+// strategies serve the local port behind the guard of the proxy options, srcPortHint tells how to pick
+// another local port. The auto connection keeps checking the proxy and port-forwards once it fails.
+func startProxyReportErrorIfAny(kubernetesProvider *kubernetes.Provider, ctx context.Context, cancel context.CancelFunc, serviceName string, podName string, srcPort uint16, dstPort uint16, healthCheck string, srcPortHint string) {
+	proxyOptions, err := connect.GetProxyOptions()
 	if err != nil {
-		log.Printf(utils.Error, fmt.Sprintf("Error occured while running k8s proxy %v\n"+
-			"Try setting different port by using --%s", errormessage.FormatError(err), configStructs.GuiPortTapName))
+		log.Printf(utils.Error, fmt.Sprintf("Error occured while preparing the k8s proxy %v", errormessage.FormatError(err)))
 		cancel()
 		return
 	}
 
-	connector := connect.NewConnector(kubernetes.GetLocalhostOnPort(srcPort), connect.DefaultRetries, connect.DefaultTimeout)
-	if err := connector.TestConnection(healthCheck); err != nil {
+	useProxy, usePortForward := getConnectionStrategies(config.Config.Connection)
+	if useProxy {
+		httpServer, err := kubernetes.StartProxy(kubernetesProvider, proxyOptions, srcPort, dstPort, config.Config.ResourcesNamespace, serviceName, cancel)
+		if err != nil {
+			log.Printf(utils.Error, fmt.Sprintf("Error occured while running k8s proxy %v\n"+
+				"Try setting different port by using %s", errormessage.FormatError(err), srcPortHint))
+			cancel()
+			return
+		}
+
+		connector := connect.NewProxyConnector(srcPort, connect.DefaultRetries, connect.DefaultTimeout)
+		if err := connector.TestConnection(healthCheck); err == nil {
+			if usePortForward {
+				go followProxy(ctx, httpServer, connector, healthCheck, func() {
+					startPortForwardReportErrorIfAny(kubernetesProvider, ctx, cancel, proxyOptions, serviceName, podName, srcPort, dstPort, healthCheck, srcPortHint)
+				})
+			}
+			return
+		}
+
+		if !usePortForward {
+			log.Printf(utils.Error, fmt.Sprintf("Couldn't connect to [%s] using proxy, try setting --%s %s=%s", serviceName, config.SetCommandName, config.ConnectionConfigName, config.ConnectionPortForward))
+			cancel()
+			return
+		}
+
 		log.Printf("Couldn't connect using proxy, stopping proxy and trying to create port-forward")
 		if err := httpServer.Shutdown(ctx); err != nil {
 			log.Printf("Error occurred while stopping proxy %v", errormessage.FormatError(err))
 		}
+	}
 
-		podRegex, _ := regexp.Compile(kubernetes.HubPodName)
-		if _, err := kubernetes.NewPortForward(kubernetesProvider, config.Config.ResourcesNamespace, podRegex, srcPort, dstPort, ctx, cancel); err != nil {
-			log.Printf(utils.Error, fmt.Sprintf("Error occured while running port forward [%s] %v\n"+
-				"Try setting different port by using --%s", podRegex, errormessage.FormatError(err), configStructs.GuiPortTapName))
-			cancel()
+	startPortForwardReportErrorIfAny(kubernetesProvider, ctx, cancel, proxyOptions, serviceName, podName, srcPort, dstPort, healthCheck, srcPortHint)
+}
+
+// followProxy health checks the API server proxy while ctx isn't done, the API server can stop proxying during
+// a session, e.g. when it's restarted, so a failing proxy is stopped and portForward takes over its port.
+func followProxy(ctx context.Context, httpServer *http.Server, connector *connect.Connector, healthCheck string, portForward func()) {
+	healthCheckTicker := time.NewTicker(proxyHealthCheckInterval)
+	defer healthCheckTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = httpServer.Close()
 			return
-		}
+		case <-healthCheckTicker.C:
+			err := connector.CheckConnection(healthCheck)
+			if err == nil {
+				continue
+			} else if ctx.Err() != nil {
+				return
+			}
 
-		connector = connect.NewConnector(kubernetes.GetLocalhostOnPort(srcPort), connect.DefaultRetries, connect.DefaultTimeout)
-		if err := connector.TestConnection(healthCheck); err != nil {
-			log.Printf(utils.Error, fmt.Sprintf("Couldn't connect to [%s].", serviceName))
-			cancel()
+			log.Printf("The proxy is unhealthy, stopping proxy and trying to create port-forward, err: %v", err)
+			if err := httpServer.Shutdown(ctx); err != nil {
+				log.Printf("Error occurred while stopping proxy %v", errormessage.FormatError(err))
+			}
+			portForward()
 			return
 		}
 	}
 }
 
+// startPortForwardReportErrorIfAny serves srcPort behind the guard of the proxy options with a port-forward to
+// podName.
+func startPortForwardReportErrorIfAny(kubernetesProvider *kubernetes.Provider, ctx context.Context, cancel context.CancelFunc, proxyOptions *kubernetes.ProxyOptions, serviceName string, podName string, srcPort uint16, dstPort uint16, healthCheck string, srcPortHint string) {
+	// The port-forward listens on an internal port, so its requests pass the guard too
+	forwardPort, err := kubernetes.FindFreePort(kubernetes.LocalhostIp)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Error occured while picking a port to port forward to [%s] %v", podName, errormessage.FormatError(err)))
+		cancel()
+		return
+	}
+
+	podRegex := regexp.MustCompile(fmt.Sprintf("^%s$", podName))
+	if err := kubernetes.StartPodPortForward(ctx, kubernetesProvider, kubernetes.LocalhostIp, config.Config.ResourcesNamespace, podRegex, forwardPort, dstPort); err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Error occured while running port forward [%s] %v", podRegex, errormessage.FormatError(err)))
+		cancel()
+		return
+	}
+
+	httpServer, err := kubernetes.StartReverseProxy(proxyOptions, srcPort, fmt.Sprintf("http://%s:%d", kubernetes.LocalhostIp, forwardPort), cancel)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Error occured while running the port forward proxy %v\n"+
+			"Try setting different port by using %s", errormessage.FormatError(err), srcPortHint))
+		cancel()
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		_ = httpServer.Close()
+	}()
+
+	connector := connect.NewProxyConnector(srcPort, connect.DefaultRetries, connect.DefaultTimeout)
+	if err := connector.TestConnection(healthCheck); err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Couldn't connect to [%s].", serviceName))
+		cancel()
+		return
+	}
+}
+
+// getConnectionStrategies returns whether a connection tries the API server proxy and whether it port-forwards,
+// the auto connection port-forwards only when the proxy doesn't connect.
+func getConnectionStrategies(connection string) (useProxy bool, usePortForward bool) {
+	switch connection {
+	case config.ConnectionProxy:
+		return true, false
+	case config.ConnectionPortForward:
+		return false, true
+	default:
+		return true, true
+	}
+}
+
+// resolveLocalPort returns port when it's free on the proxy host, a busy port is replaced with a free one
+// unless the user pinned it.
+func resolveLocalPort(name string, port uint16, pinned bool) (uint16, error) {
+	if kubernetes.IsPortFree(config.Config.Tap.ProxyHost, port) {
+		return port, nil
+	}
+
+	if pinned {
+		return 0, fmt.Errorf("the %s port %d is in use, free it or pick another port", name, port)
+	}
+
+	freePort, err := kubernetes.FindFreePort(config.Config.Tap.ProxyHost)
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("The %s port %d is in use, using port %d instead", name, port, freePort)
+	return freePort, nil
+}
+
+// resolveLocalPorts replaces a busy local port of the front unless the user pinned it, frontPortHint tells how
+// to pick another one. The front reaches the hub on its local port, so the hub port of a run serving the front
+// is checked but never replaced.
+func resolveLocalPorts(resolveHub bool, frontPortHint string) error {
+	if resolveHub {
+		if _, err := resolveLocalPort("hub", config.Config.Hub.PortForward.SrcPort, true); err != nil {
+			return fmt.Errorf("%w, the front reaches the hub on it", err)
+		}
+	}
+
+	// The --gui-port of tap and view sets the front port
+	isFrontPortPinned := config.IsSet(config.FrontSrcPortConfigName) ||
+		config.IsSet("tap."+configStructs.GuiPortTapName) ||
+		config.IsSet("view."+configStructs.GuiPortViewName)
+	frontPort, err := resolveLocalPort("front", config.Config.Front.PortForward.SrcPort, isFrontPortPinned)
+	if err != nil {
+		return fmt.Errorf("%w with %s", err, frontPortHint)
+	}
+	config.Config.Front.PortForward.SrcPort = frontPort
+	return nil
+}
+
+// getSetHint returns how to set a config key with --set.
+func getSetHint(key string) string {
+	return fmt.Sprintf("--%s %s=<port>", config.SetCommandName, key)
+}
+
+const exposedUrlTimeout = 2 * time.Minute
+
+// getExposedUrl returns the url of a service exposed through an ingress, load balancer or node port,
+// or an empty string when it has to be reached through a proxy.
+func getExposedUrl(ctx context.Context, kubernetesProvider *kubernetes.Provider, serviceName string, ingressName string) string {
+	exposedCtx, cancel := context.WithTimeout(ctx, exposedUrlTimeout)
+	defer cancel()
+
+	url, err := kubernetesProvider.GetExposedUrl(exposedCtx, config.Config.ResourcesNamespace, serviceName, ingressName)
+	if err != nil {
+		log.Printf(utils.Warning, fmt.Sprintf("Failed to get the exposed address of service %s, falling back to a proxy: %v", serviceName, errormessage.FormatError(err)))
+		return ""
+	}
+
+	return url
+}
+
+func printAuthLoginHint(url string) {
+	log.Printf("Authentication is enabled, sign up at %s to create a user", url)
+}
+
 func getKubernetesProviderForCli() (*kubernetes.Provider, error) {
 	kubernetesProvider, err := kubernetes.NewProvider(config.Config.KubeConfigPath(), config.Config.KubeContext)
 	if err != nil {
@@ -91,11 +257,11 @@ func handleKubernetesProviderError(err error) {
 	}
 }
 
-func finishKubesharkExecution(kubernetesProvider *kubernetes.Provider, isNsRestrictedMode bool, kubesharkResourcesNamespace string) {
+func finishKubesharkExecution(kubernetesProvider *kubernetes.Provider, isNsRestrictedMode bool, kubesharkResourcesNamespace string, cleanUpOptions resources.CleanUpOptions) {
 	removalCtx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
 	defer cancel()
 	dumpLogsIfNeeded(removalCtx, kubernetesProvider)
-	resources.CleanUpKubesharkResources(removalCtx, cancel, kubernetesProvider, isNsRestrictedMode, kubesharkResourcesNamespace)
+	resources.CleanUpKubesharkResources(removalCtx, cancel, kubernetesProvider, isNsRestrictedMode, kubesharkResourcesNamespace, cleanUpOptions)
 }
 
 func dumpLogsIfNeeded(ctx context.Context, kubernetesProvider *kubernetes.Provider) {
@@ -117,3 +283,72 @@ func getSerializedTapConfig(conf *models.Config) (string, error) {
 
 	return string(serializedConfig), nil
 }
+
+const podReadyPollInterval = time.Second
+
+// deployHubWithoutWorkers deploys the hub and front of a session that captures nothing, for traffic recorded
+// elsewhere. The session is held and cleaned up as a tap session is. waitForRestore holds basenine back until
+// a snapshot is restored into its data directory.
+func deployHubWithoutWorkers(ctx context.Context, kubernetesProvider *kubernetes.Provider, serializedKubesharkConfig string, waitForRestore bool) error {
+	state.startTime = time.Now()
+
+	log.Printf("Deploying Kubeshark without workers in namespace %s...", config.Config.ResourcesNamespace)
+	state.sessionHolder = resources.NewSessionHolder(kubernetesProvider, false)
+	var err error
+	state.kubesharkServiceAccountExists, err = resources.CreateTapKubesharkResources(ctx, kubernetesProvider, state.sessionHolder, serializedKubesharkConfig, config.Config.IsNsRestrictedMode(), config.Config.ResourcesNamespace, config.Config.Tap.MaxEntriesDBSizeBytes(), config.Config.Tap.HubResources, config.Config.Tap.GetBasenineResources(), config.Config.Tap.FrontResources, config.Config.ImagePullPolicy(), config.Config.LogLevel(), config.Config.Tap.Profiler, waitForRestore)
+	return err
+}
+
+func waitForPodRunning(ctx context.Context, kubernetesProvider *kubernetes.Provider, podName string) error {
+	podExactRegex := regexp.MustCompile(fmt.Sprintf("^%s$", podName))
+	hubTimeoutSec := config.GetIntEnvConfig(config.HubTimeoutSec, 120)
+	timeout := time.After(time.Duration(hubTimeoutSec) * time.Second)
+
+	ticker := time.NewTicker(podReadyPollInterval)
+	defer ticker.Stop()
+	for {
+		pods, err := kubernetesProvider.ListAllRunningPodsMatchingRegex(ctx, podExactRegex, []string{config.Config.ResourcesNamespace})
+		if err != nil {
+			return err
+		}
+		if len(pods) > 0 {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return fmt.Errorf("%s isn't running after %d seconds", podName, hubTimeoutSec)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// waitForInitContainerRunning waits until an init container of a pod is running.
+func waitForInitContainerRunning(ctx context.Context, kubernetesProvider *kubernetes.Provider, podName string, containerName string) error {
+	hubTimeoutSec := config.GetIntEnvConfig(config.HubTimeoutSec, 120)
+	timeout := time.After(time.Duration(hubTimeoutSec) * time.Second)
+
+	ticker := time.NewTicker(podReadyPollInterval)
+	defer ticker.Stop()
+	for {
+		pod, err := kubernetesProvider.GetPod(ctx, config.Config.ResourcesNamespace, podName)
+		if err != nil {
+			return err
+		}
+		for _, status := range pod.Status.InitContainerStatuses {
+			if status.Name == containerName && status.State.Running != nil {
+				return nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-timeout:
+			return fmt.Errorf("%s of %s isn't running after %d seconds", containerName, podName, hubTimeoutSec)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
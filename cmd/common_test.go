@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/kubeshark/kubeshark/config"
+)
+
+func TestGetConnectionStrategies(t *testing.T) {
+	tests := []struct {
+		connection     string
+		useProxy       bool
+		usePortForward bool
+	}{
+		{config.ConnectionAuto, true, true},
+		{config.ConnectionProxy, true, false},
+		{config.ConnectionPortForward, false, true},
+	}
+	for _, test := range tests {
+		if useProxy, usePortForward := getConnectionStrategies(test.connection); useProxy != test.useProxy || usePortForward != test.usePortForward {
+			t.Errorf("unexpected strategies of %s connection - proxy: %t, port-forward: %t", test.connection, useProxy, usePortForward)
+		}
+	}
+}
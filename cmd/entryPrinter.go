@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+	"github.com/kubeshark/kubeshark/utils"
+)
+
+const entryTimeFormat = "2006-01-02 15:04:05.000"
+
+// entryPrinter prints entries as they come, so the same printer serves paged queries and live streams. The
+// table has fixed width columns for that reason.
+type entryPrinter struct {
+	out           io.Writer
+	output        string
+	headerPrinted bool
+}
+
+func newEntryPrinter(out io.Writer, output string) *entryPrinter {
+	return &entryPrinter{out: out, output: output}
+}
+
+// print prints an entry, with its request and response when the full entry is given.
+func (printer *entryPrinter) print(entry *hub.BaseEntry, fullEntry *hub.EntryWrapper) error {
+	switch printer.output {
+	case configStructs.OutputJson:
+		return printer.printJson(entry, fullEntry)
+	case configStructs.OutputYaml:
+		return printer.printYaml(entry, fullEntry)
+	default:
+		return printer.printRow(entry, fullEntry)
+	}
+}
+
+func getPrintedEntry(entry *hub.BaseEntry, fullEntry *hub.EntryWrapper) interface{} {
+	if fullEntry != nil && fullEntry.Data != nil {
+		return fullEntry.Data
+	}
+	return entry
+}
+
+func (printer *entryPrinter) printJson(entry *hub.BaseEntry, fullEntry *hub.EntryWrapper) error {
+	line, err := json.Marshal(getPrintedEntry(entry, fullEntry))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(printer.out, "%s\n", line)
+	return err
+}
+
+func (printer *entryPrinter) printYaml(entry *hub.BaseEntry, fullEntry *hub.EntryWrapper) error {
+	document, err := toYaml(getPrintedEntry(entry, fullEntry))
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(printer.out, "---\n%s", document)
+	return err
+}
+
+// toYaml converts through json, so the yaml keys are the json keys of the hub API.
+func toYaml(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return "", err
+	}
+	return utils.PrettyYaml(generic)
+}
+
+const entryRowFormat = "%-23s  %-6s  %-7s  %-6s  %-28s  %-28s  %s\n"
+
+func (printer *entryPrinter) printRow(entry *hub.BaseEntry, fullEntry *hub.EntryWrapper) error {
+	if !printer.headerPrinted {
+		if _, err := fmt.Fprintf(printer.out, entryRowFormat, "TIME", "PROTO", "METHOD", "STATUS", "SOURCE", "DESTINATION", "SUMMARY"); err != nil {
+			return err
+		}
+		printer.headerPrinted = true
+	}
+
+	status := ""
+	if entry.Status != 0 {
+		status = fmt.Sprint(entry.Status)
+	}
+	if _, err := fmt.Fprintf(printer.out, entryRowFormat,
+		time.UnixMilli(entry.Timestamp).Local().Format(entryTimeFormat),
+		entry.Protocol.Abbreviation,
+		entry.Method,
+		status,
+		getEndpointName(entry.Source),
+		getEndpointName(entry.Destination),
+		entry.Summary); err != nil {
+		return err
+	}
+
+	if fullEntry == nil || fullEntry.Data == nil {
+		return nil
+	}
+	return printer.printExpanded(fullEntry.Data)
+}
+
+func (printer *entryPrinter) printExpanded(entry *hub.Entry) error {
+	for _, part := range []struct {
+		name  string
+		value map[string]interface{}
+	}{{name: "Request", value: entry.Request}, {name: "Response", value: entry.Response}} {
+		if len(part.value) == 0 {
+			continue
+		}
+
+		document, err := toYaml(part.value)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(printer.out, "  %s:\n%s", part.name, indent(document, "    ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getEndpointName(endpoint *hub.TCP) string {
+	if endpoint == nil {
+		return ""
+	}
+	if endpoint.Name != "" {
+		return endpoint.Name
+	}
+	return fmt.Sprintf("%s:%s", endpoint.IP, endpoint.Port)
+}
+
+func indent(text string, prefix string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
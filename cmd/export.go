@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/creasty/defaults"
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the captured traffic to a HAR, pcapng or JSON lines file, or to a script replaying it",
+	Long: `Export the captured traffic that matches a KFL filter to a HAR 1.2, pcapng or JSON lines file.
+HAR and pcapng hold HTTP entries only, the Kubernetes metadata of every entry is written in comments.
+The redaction rules of the tap are applied to the exported entries when redaction is enabled. They cover
+HTTP entries only, so JSON lines files leave out the entries of other protocols while redaction is enabled.
+
+The curl, k6 and go-test formats write scripts that replay the HTTP requests in the order and with the
+delays they were captured with. The hosts of the requests can be rewritten to targets, and auth headers
+are read from environment variables by default, e.g. AUTHORIZATION.
+
+Example:
+  kubeshark export --filter 'http and dst.name == "checkout"' --format pcapng -o checkout.pcapng
+  kubeshark export --filter 'http and response.status >= 500' --format k6 --target checkout=http://localhost:8080`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Config.Export.Validate(); err != nil {
+			return errormessage.FormatError(err)
+		}
+
+		runKubesharkExport()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	defaultExportConfig := configStructs.ExportConfig{}
+	if err := defaults.Set(&defaultExportConfig); err != nil {
+		log.Print(err)
+	}
+
+	exportCmd.Flags().StringP(configStructs.FilterExportName, "f", defaultExportConfig.Filter, "A KFL filter selecting the exported entries, all the entries by default")
+	exportCmd.Flags().String(configStructs.FormatExportName, defaultExportConfig.Format, "The format of the export, har, pcapng, jsonl, curl, k6 or go-test")
+	exportCmd.Flags().StringP(configStructs.OutputExportName, "o", defaultExportConfig.Output, "Path of the exported file, or - for the standard output (default current <pwd>/kubeshark_export.<format>)")
+	exportCmd.Flags().StringSlice(configStructs.TargetExportName, defaultExportConfig.Targets, "Rewrite the host of replayed requests as host=url, a host of * rewrites all the hosts")
+	exportCmd.Flags().String(configStructs.AuthExportName, defaultExportConfig.Auth, "How scripts handle auth headers, template reads them from environment variables, strip or keep")
+	exportCmd.Flags().Bool(configStructs.TimingExportName, defaultExportConfig.Timing, "Keep the delays between replayed requests as they were captured")
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/kubeshark/kubeshark/kubeshark"
+	"github.com/kubeshark/kubeshark/pkg/export"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+	"github.com/kubeshark/kubeshark/utils"
+)
+
+func runKubesharkExport() {
+	kubernetesProvider, err := getKubernetesProviderForCli()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := connectToHub(ctx, kubernetesProvider, cancel)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to connect to the hub: %v", errormessage.FormatError(err)))
+		return
+	}
+
+	filePath := config.Config.Export.FilePath()
+	if config.Config.Export.Output == configStructs.ExportStdout {
+		if _, err := exportEntries(ctx, client, os.Stdout); err != nil {
+			log.Printf(utils.Error, fmt.Sprintf("Failed to export the entries: %v", errormessage.FormatError(err)))
+		}
+		return
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to create %s: %v", filePath, err))
+		return
+	}
+
+	result, err := exportEntries(ctx, client, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to export the entries: %v", errormessage.FormatError(err)))
+		_ = os.Remove(filePath)
+		return
+	}
+
+	log.Printf("Exported %d entries to %s", result.Written, fmt.Sprintf(utils.Purple, filePath))
+}
+
+func exportEntries(ctx context.Context, client *hub.Client, out io.Writer) (*export.Result, error) {
+	writer, err := export.NewWriter(config.Config.Export.Format, out, export.Options{
+		CreatorName:    "kubeshark",
+		CreatorVersion: kubeshark.Ver,
+		Redactor:       getExportRedactor(),
+		Script:         getExportScriptOptions(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := export.Export(ctx, client, config.Config.Export.Filter, writer)
+	if err != nil {
+		return nil, err
+	}
+	if result.Skipped > 0 && config.Config.Export.Format == export.FormatJsonl {
+		log.Printf(utils.Warning, fmt.Sprintf("Skipped %d entries that aren't HTTP, redaction covers HTTP entries only", result.Skipped))
+	} else if result.Skipped > 0 {
+		log.Printf(utils.Warning, fmt.Sprintf("Skipped %d entries %s can't hold", result.Skipped, config.Config.Export.Format))
+	}
+	return result, writer.Close()
+}
+
+func getExportScriptOptions() export.ScriptOptions {
+	// The targets were validated with the config
+	targets, _ := config.Config.Export.GetTargets()
+	return export.ScriptOptions{
+		Targets: targets,
+		Auth:    config.Config.Export.Auth,
+		Timing:  config.Config.Export.Timing,
+	}
+}
+
+// getExportRedactor applies the redaction rules of the tap to exports, when redaction is enabled.
+func getExportRedactor() *export.Redactor {
+	if !config.Config.Tap.EnableRedaction {
+		return nil
+	}
+
+	patterns := config.Config.Tap.RedactPatterns
+	return &export.Redactor{
+		RequestHeaders:     patterns.RequestHeaders,
+		ResponseHeaders:    patterns.ResponseHeaders,
+		RequestBody:        patterns.RequestBody,
+		ResponseBody:       patterns.ResponseBody,
+		RequestQueryParams: patterns.RequestQueryParams,
+	}
+}
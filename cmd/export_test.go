@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubeshark/kubeshark/config"
+)
+
+func TestExportFlagsReachConfig(t *testing.T) {
+	if err := exportCmd.ParseFlags([]string{"--format", "k6", "--target", "checkout=http://localhost:8080", "--target", "*=http://staging"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.InitConfig(exportCmd); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"checkout=http://localhost:8080", "*=http://staging"}
+	if config.Config.Export.Format != "k6" || !reflect.DeepEqual(config.Config.Export.Targets, expected) {
+		t.Errorf("unexpected export config %+v", config.Config.Export)
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/internal/connect"
+	"github.com/kubeshark/kubeshark/kubernetes"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+// connectToHub returns a client of the hub of the instance, through its exposed address or through a proxy
+// this run starts. A tap running on this host keeps the default port, so a free port is picked then.
+func connectToHub(ctx context.Context, kubernetesProvider *kubernetes.Provider, cancel context.CancelFunc) (*hub.Client, error) {
+	exists, err := kubernetesProvider.DoesServiceExist(ctx, config.Config.ResourcesNamespace, kubernetes.HubServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the %s service, %w", kubernetes.HubServiceName, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("%s service not found, you should run `kubeshark tap` command first", kubernetes.HubServiceName)
+	}
+
+	if config.Config.Hub.Expose.IsExposed() {
+		if exposedUrl := getExposedUrl(ctx, kubernetesProvider, kubernetes.HubServiceName, kubernetes.HubIngressName); exposedUrl != "" {
+			return hub.NewClient(exposedUrl)
+		}
+	}
+
+	hubPort, err := resolveLocalPort("hub", config.Config.Hub.PortForward.SrcPort, config.IsSet(config.HubSrcPortConfigName))
+	if err != nil {
+		return nil, err
+	}
+	config.Config.Hub.PortForward.SrcPort = hubPort
+
+	startProxyReportErrorIfAny(kubernetesProvider, ctx, cancel, kubernetes.HubServiceName, kubernetes.HubPodName, hubPort, config.Config.Hub.PortForward.DstPort, "/echo", getSetHint(config.HubSrcPortConfigName))
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("couldn't connect to the %s service", kubernetes.HubServiceName)
+	}
+
+	return connect.NewProxyHubClient(hubPort)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/creasty/defaults"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import the traffic of a HAR or pcap file into Kubeshark",
+	Long: `Import the HTTP traffic of a HAR file, or of a pcap or pcapng capture, into the running hub.
+TCP connections of captures are reassembled and their HTTP/1.x requests and responses are imported.
+Addresses that belong to pods and services of the cluster are given their namespace.
+
+With --namespace, a hub without workers is deployed in the namespace for the imported traffic only,
+and removed once the command exits.
+
+Example:
+  kubeshark import checkout.har
+  kubeshark import capture.pcapng --namespace incident-42`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runKubesharkImport(args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	defaultImportConfig := configStructs.ImportConfig{}
+	if err := defaults.Set(&defaultImportConfig); err != nil {
+		log.Print(err)
+	}
+
+	importCmd.Flags().StringP(configStructs.NamespaceImportName, "n", defaultImportConfig.Namespace, "Deploy a hub without workers in this namespace for the imported traffic, instead of importing into the running hub")
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+
+	"github.com/kubeshark/kubeshark/cmd/goUtils"
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/kubeshark/kubeshark/kubernetes"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+	"github.com/kubeshark/kubeshark/pkg/importer"
+	"github.com/kubeshark/kubeshark/utils"
+	core "k8s.io/api/core/v1"
+)
+
+func runKubesharkImport(filePath string) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to open %s: %v", filePath, err))
+		return
+	}
+	defer file.Close()
+
+	reader, format, err := importer.NewReader(file)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to read %s: %v", filePath, errormessage.FormatError(err)))
+		return
+	}
+
+	kubernetesProvider, err := getKubernetesProviderForCli()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	resolver, err := newIpResolver(ctx, kubernetesProvider)
+	if err != nil {
+		log.Printf(utils.Warning, fmt.Sprintf("Failed to list the pods and services of the cluster, the imported traffic is given no namespace: %v", errormessage.FormatError(err)))
+	}
+
+	if config.Config.Import.IsNewHub() {
+		config.Config.ResourcesNamespace = config.Config.Import.Namespace
+		if err := resolveLocalPorts(true, getSetHint(config.FrontSrcPortConfigName)); err != nil {
+			log.Printf(utils.Error, errormessage.FormatError(err))
+			return
+		}
+
+		serializedKubesharkConfig, err := getSerializedTapConfig(getTapConfig())
+		if err != nil {
+			log.Printf(utils.Error, fmt.Sprintf("Error serializing kubeshark config: %v", errormessage.FormatError(err)))
+			return
+		}
+
+		if err := deployHubWithoutWorkers(ctx, kubernetesProvider, serializedKubesharkConfig, false); err != nil {
+			printSessionError(err)
+			return
+		}
+		defer finishTapExecution(kubernetesProvider)
+		go goUtils.HandleExcWrapper(watchHubEvents, ctx, kubernetesProvider, cancel)
+		go goUtils.HandleExcWrapper(renewLease, ctx, kubernetesProvider, cancel)
+
+		if err := waitForPodRunning(ctx, kubernetesProvider, kubernetes.HubPodName); err != nil {
+			log.Printf(utils.Error, fmt.Sprintf("Kubeshark Hub was not ready: %v", errormessage.FormatError(err)))
+			return
+		}
+	}
+
+	client, err := connectToHub(ctx, kubernetesProvider, cancel)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to connect to the hub: %v", errormessage.FormatError(err)))
+		return
+	}
+
+	imported, err := importPairs(ctx, client, reader, resolver, format)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to import %s after %d pairs: %v", filePath, imported, errormessage.FormatError(err)))
+		return
+	}
+	log.Printf("Imported %d pairs from %s", imported, fmt.Sprintf(utils.Purple, filePath))
+
+	if !config.Config.Import.IsNewHub() {
+		return
+	}
+
+	if err := waitForPodRunning(ctx, kubernetesProvider, kubernetes.FrontPodName); err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Kubeshark front was not ready: %v", errormessage.FormatError(err)))
+		return
+	}
+	postFrontStarted(ctx, kubernetesProvider, cancel)
+	log.Printf("Kubeshark in namespace %s is removed once this command exits", config.Config.ResourcesNamespace)
+
+	utils.WaitForFinish(ctx, cancel)
+}
+
+// importPairs sends the pairs of a file to the hub as the workers send the pairs they capture.
+func importPairs(ctx context.Context, client *hub.Client, reader importer.Reader, resolver ipResolver, capture string) (int, error) {
+	stream, err := client.OpenTapperStream(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	imported := 0
+	for {
+		pair, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			_ = stream.Close()
+			return imported, err
+		}
+
+		connectionInfo := &hub.ConnectionInfo{
+			ClientIP:   pair.ClientIP,
+			ClientPort: pair.ClientPort,
+			ServerIP:   pair.ServerIP,
+			ServerPort: pair.ServerPort,
+		}
+		entry := hub.NewHttpTappedEntry(connectionInfo, resolver.getNamespace(pair), capture, pair.StartTime, pair.Elapsed, pair.Request, pair.Response)
+		if err := stream.Send(entry); err != nil {
+			_ = stream.Close()
+			return imported, err
+		}
+		imported++
+	}
+
+	return imported, stream.Close()
+}
+
+// ipResolver maps the IPs of the pods and services of the cluster to their namespace. It leaves out the names and
+// labels of the pods, a tapped entry has no field for them, as the workers don't send them either. The hub resolves
+// the names of the addresses itself, as it does for the traffic the workers capture.
+type ipResolver map[string]string
+
+func newIpResolver(ctx context.Context, kubernetesProvider *kubernetes.Provider) (ipResolver, error) {
+	resolver := ipResolver{}
+
+	pods, err := kubernetesProvider.ListAllPodsMatchingRegex(ctx, regexp.MustCompile(".*"), []string{kubernetes.K8sAllNamespaces})
+	if err != nil {
+		return resolver, err
+	}
+	for _, pod := range pods {
+		// Pods on the host network share the IP of their node
+		if pod.Spec.HostNetwork {
+			continue
+		}
+		for _, podIp := range pod.Status.PodIPs {
+			resolver[podIp.IP] = pod.Namespace
+		}
+	}
+
+	services, err := kubernetesProvider.ListServices(ctx, kubernetes.K8sAllNamespaces)
+	if err != nil {
+		return resolver, err
+	}
+	for _, service := range services {
+		for _, clusterIp := range service.Spec.ClusterIPs {
+			if clusterIp != core.ClusterIPNone {
+				resolver[clusterIp] = service.Namespace
+			}
+		}
+	}
+
+	return resolver, nil
+}
+
+// getNamespace returns the namespace of the server of a pair, or of its client when the server isn't in the
+// cluster.
+func (resolver ipResolver) getNamespace(pair *importer.Pair) string {
+	if namespace, ok := resolver[pair.ServerIP]; ok {
+		return namespace
+	}
+	return resolver[pair.ClientIP]
+}
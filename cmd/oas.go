@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/creasty/defaults"
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/spf13/cobra"
+)
+
+var oasCmd = &cobra.Command{
+	Use:   "oas",
+	Short: "Get the OpenAPI specs the hub infers from the traffic",
+}
+
+var oasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the services the hub has OpenAPI specs for",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runKubesharkOasList()
+		return nil
+	},
+}
+
+var oasExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the OpenAPI 3 spec the hub inferred for a service",
+	Long: `Export the OpenAPI 3 spec the hub inferred from the traffic of a service, as YAML, or as JSON when the
+output ends with .json. Without --service, the specs of all the services are exported to a directory.
+
+Example:
+  kubeshark oas export --service checkout -o openapi.yaml
+  kubeshark oas export -o specs/`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{config.ConfigKeyAnnotation: configStructs.OasExportConfigKey},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runKubesharkOasExport()
+		return nil
+	},
+}
+
+var oasDiffCmd = &cobra.Command{
+	Use:   "diff FILE",
+	Short: "Compare the observed traffic of a service to its documented OpenAPI 3 spec",
+	Long: `Compare the OpenAPI spec the hub inferred from the traffic of a service to a documented OpenAPI 3 spec,
+and report the undocumented endpoints, query parameters, responses, content types and body properties,
+and the body values whose type differs from the documented one. The command fails when there are any.
+
+The service is picked by the servers of the documented spec, or named with --service.
+
+Example:
+  kubeshark oas diff api/openapi.yaml --service checkout`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	Annotations:  map[string]string{config.ConfigKeyAnnotation: configStructs.OasDiffConfigKey},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runKubesharkOasDiff(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(oasCmd)
+	oasCmd.AddCommand(oasListCmd)
+	oasCmd.AddCommand(oasExportCmd)
+	oasCmd.AddCommand(oasDiffCmd)
+
+	defaultOasExportConfig := configStructs.OASExportConfig{}
+	if err := defaults.Set(&defaultOasExportConfig); err != nil {
+		log.Print(err)
+	}
+	defaultOasDiffConfig := configStructs.OASDiffConfig{}
+	if err := defaults.Set(&defaultOasDiffConfig); err != nil {
+		log.Print(err)
+	}
+
+	oasExportCmd.Flags().StringP(configStructs.ServiceOasName, "s", defaultOasExportConfig.Service, "The service whose spec is exported, all the services by default")
+	oasExportCmd.Flags().StringP(configStructs.OutputOasName, "o", defaultOasExportConfig.Output, "Path of the spec, or - for the standard output, a directory when exporting all the services (default current <pwd>/<service>.openapi.yaml)")
+	oasDiffCmd.Flags().StringP(configStructs.ServiceOasName, "s", defaultOasDiffConfig.Service, "The service whose observed traffic is compared, picked by the servers of the spec by default")
+}
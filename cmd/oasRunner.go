@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+	"github.com/kubeshark/kubeshark/pkg/oas"
+	"github.com/kubeshark/kubeshark/utils"
+)
+
+// connectToOasHub connects to the hub, and warns when the hub doesn't infer specs.
+func connectToOasHub(ctx context.Context, cancel context.CancelFunc) (*hub.Client, error) {
+	kubernetesProvider, err := getKubernetesProviderForCli()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := connectToHub(ctx, kubernetesProvider, cancel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the hub, %w", err)
+	}
+
+	if !config.Config.OAS.Enable {
+		log.Printf(utils.Warning, "The hub doesn't infer OpenAPI specs, tap with --set oas.enabled=true")
+	}
+	return client, nil
+}
+
+func runKubesharkOasList() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := connectToOasHub(ctx, cancel)
+	if err != nil {
+		log.Printf(utils.Error, errormessage.FormatError(err))
+		return
+	}
+
+	services, err := client.GetOASServices(ctx)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to list the services: %v", errormessage.FormatError(err)))
+		return
+	}
+	if len(services) == 0 {
+		log.Printf("The hub has no OpenAPI specs yet, they're inferred from the HTTP traffic of the tapped pods")
+		return
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Service < services[j].Service
+	})
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "SERVICE\tENTRIES")
+	for _, service := range services {
+		fmt.Fprintf(writer, "%s\t%d\n", service.Service, service.Entries)
+	}
+	_ = writer.Flush()
+}
+
+func runKubesharkOasExport() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := connectToOasHub(ctx, cancel)
+	if err != nil {
+		log.Printf(utils.Error, errormessage.FormatError(err))
+		return
+	}
+
+	exportConfig := config.Config.OASExport
+	if exportConfig.Service != "" {
+		spec, err := client.GetOASSpec(ctx, exportConfig.Service)
+		if err != nil {
+			log.Printf(utils.Error, fmt.Sprintf("Failed to get the spec of %s: %v", exportConfig.Service, errormessage.FormatError(err)))
+			return
+		}
+		if err := writeOasSpec(spec, exportConfig.FilePath(exportConfig.Service)); err != nil {
+			log.Printf(utils.Error, fmt.Sprintf("Failed to export the spec of %s: %v", exportConfig.Service, err))
+		}
+		return
+	}
+
+	if exportConfig.Output == configStructs.OasStdout {
+		log.Printf(utils.Error, fmt.Sprintf("Name a --%s to export its spec to the standard output", configStructs.ServiceOasName))
+		return
+	}
+	if exportConfig.Output != "" {
+		if err := os.MkdirAll(exportConfig.Output, 0755); err != nil {
+			log.Printf(utils.Error, fmt.Sprintf("Failed to create %s: %v", exportConfig.Output, err))
+			return
+		}
+	}
+
+	specs, err := client.GetAllOASSpecs(ctx)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to get the specs: %v", errormessage.FormatError(err)))
+		return
+	}
+	if len(specs) == 0 {
+		log.Printf("The hub has no OpenAPI specs yet, they're inferred from the HTTP traffic of the tapped pods")
+		return
+	}
+	for service, spec := range specs {
+		if err := writeOasSpec(spec, exportConfig.FilePath(service)); err != nil {
+			log.Printf(utils.Error, fmt.Sprintf("Failed to export the spec of %s: %v", service, err))
+		}
+	}
+}
+
+// writeOasSpec writes a spec in the format of its file, or as YAML to the standard output.
+func writeOasSpec(spec json.RawMessage, filePath string) error {
+	if filePath == configStructs.OasStdout {
+		formatted, err := oas.Format(spec, oas.FormatYaml)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(formatted)
+		return err
+	}
+
+	formatted, err := oas.Format(spec, oas.GetFormat(filePath))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filePath, formatted, 0644); err != nil {
+		return err
+	}
+	log.Printf("Exported the spec to %s", fmt.Sprintf(utils.Purple, filePath))
+	return nil
+}
+
+// runKubesharkOasDiff returns an error when the observed traffic differs from the documented spec, so the
+// command fails.
+func runKubesharkOasDiff(filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	documented, err := oas.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to read %s, %w", filePath, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := connectToOasHub(ctx, cancel)
+	if err != nil {
+		return errormessage.FormatError(err)
+	}
+
+	service := config.Config.OASDiff.Service
+	if service == "" {
+		if service, err = getDocumentedService(ctx, client, documented); err != nil {
+			return err
+		}
+	}
+
+	observedSpec, err := client.GetOASSpec(ctx, service)
+	if err != nil {
+		return fmt.Errorf("failed to get the spec of %s, %w", service, errormessage.FormatError(err))
+	}
+	observed, err := oas.Parse(observedSpec)
+	if err != nil {
+		return fmt.Errorf("invalid spec of %s, %w", service, err)
+	}
+
+	result, err := oas.Diff(documented, observed)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Compared the traffic of %s to %s", fmt.Sprintf(utils.Purple, service), filePath)
+	for _, difference := range result.Differences {
+		log.Printf(utils.Yellow, difference.String())
+	}
+	if len(result.Unobserved) > 0 {
+		log.Printf("%d documented endpoints weren't observed:", len(result.Unobserved))
+		for _, endpoint := range result.Unobserved {
+			log.Printf("  %s", endpoint)
+		}
+	}
+
+	if len(result.Differences) > 0 {
+		return fmt.Errorf("the traffic of %s differs from %s in %d places", service, filePath, len(result.Differences))
+	}
+	log.Printf(utils.Green, "The observed traffic matches the spec")
+	return nil
+}
+
+// getDocumentedService returns the service the hub has a spec for whose host is a server of the documented spec.
+func getDocumentedService(ctx context.Context, client *hub.Client, documented *oas.Spec) (string, error) {
+	services, err := client.GetOASServices(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list the services, %w", errormessage.FormatError(err))
+	}
+
+	var names, matches []string
+	for _, service := range services {
+		names = append(names, service.Service)
+		serviceHost := getOasServiceHost(service.Service)
+		for _, host := range documented.Hosts() {
+			if serviceHost == host || strings.HasPrefix(serviceHost, host+".") || strings.HasPrefix(host, serviceHost+".") {
+				matches = append(matches, service.Service)
+				break
+			}
+		}
+	}
+
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("the servers of the spec don't match a single service, name one with --%s: %s", configStructs.ServiceOasName, strings.Join(names, ", "))
+}
+
+// getOasServiceHost returns the host of a service the hub has a spec for, services are named by their url.
+func getOasServiceHost(service string) string {
+	if serviceUrl, err := url.Parse(service); err == nil && serviceUrl.Hostname() != "" {
+		return serviceUrl.Hostname()
+	}
+	return strings.SplitN(service, ":", 2)[0]
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/creasty/defaults"
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/spf13/cobra"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query [KFL]",
+	Short: "Search the captured traffic",
+	Long: `Search the captured traffic with a KFL filter and print the matching entries.
+An empty filter matches all the entries.
+
+Example:
+  kubeshark query 'http and response.status >= 500 and dst.name == "checkout"' --since 10m`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Config.Query.Validate(); err != nil {
+			return errormessage.FormatError(err)
+		}
+
+		var query string
+		if len(args) > 0 {
+			query = args[0]
+		}
+		runKubesharkQuery(query)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(queryCmd)
+
+	defaultQueryConfig := configStructs.QueryConfig{}
+	if err := defaults.Set(&defaultQueryConfig); err != nil {
+		log.Print(err)
+	}
+
+	queryCmd.Flags().String(configStructs.SinceQueryName, defaultQueryConfig.Since, "Only match the entries captured in this duration, e.g. 10m")
+	queryCmd.Flags().IntP(configStructs.LimitQueryName, "l", defaultQueryConfig.Limit, "The maximum number of entries to print, the newest entries are printed")
+	queryCmd.Flags().StringP(configStructs.OutputQueryName, "o", defaultQueryConfig.Output, "Print the entries as a table, json lines or yaml")
+	queryCmd.Flags().BoolP(configStructs.ExpandQueryName, "e", defaultQueryConfig.Expand, "Print the request and response of every entry")
+}
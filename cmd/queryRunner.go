@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+	"github.com/kubeshark/kubeshark/utils"
+)
+
+func runKubesharkQuery(query string) {
+	kubernetesProvider, err := getKubernetesProviderForCli()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := connectToHub(ctx, kubernetesProvider, cancel)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to connect to the hub: %v", errormessage.FormatError(err)))
+		return
+	}
+
+	query = withSince(query, config.Config.Query.GetSince())
+	entries, err := queryEntries(ctx, client, query, config.Config.Query.Limit)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to query %q: %v", query, errormessage.FormatError(err)))
+		return
+	}
+
+	printer := newEntryPrinter(os.Stdout, config.Config.Query.Output)
+	for _, entry := range entries {
+		var fullEntry *hub.EntryWrapper
+		if config.Config.Query.Expand {
+			if fullEntry, err = client.GetEntry(ctx, entry.Id, query); err != nil {
+				log.Printf(utils.Warning, fmt.Sprintf("Failed to get entry %s: %v", entry.Id, errormessage.FormatError(err)))
+			}
+		}
+
+		if err := printer.print(entry, fullEntry); err != nil {
+			log.Printf(utils.Error, fmt.Sprintf("Failed to print entry %s: %v", entry.Id, err))
+			return
+		}
+	}
+
+	if len(entries) == 0 {
+		log.Printf("No entries match %q", query)
+	}
+}
+
+// withSince narrows a KFL filter to the entries captured in the last since, a zero since keeps it as is.
+func withSince(query string, since time.Duration) string {
+	if since == 0 {
+		return query
+	}
+
+	sinceFilter := fmt.Sprintf("timestamp >= %d", time.Now().Add(-since).UnixMilli())
+	if query == "" {
+		return sinceFilter
+	}
+	return fmt.Sprintf("(%s) and %s", query, sinceFilter)
+}
+
+// queryEntries pages back from the newest entry until limit entries matched, and returns them oldest first.
+func queryEntries(ctx context.Context, client *hub.Client, query string, limit int) ([]*hub.BaseEntry, error) {
+	var entries []*hub.BaseEntry
+	var leftOff string
+	for len(entries) < limit {
+		response, err := client.QueryEntries(ctx, hub.EntriesQuery{
+			Query:     query,
+			LeftOff:   leftOff,
+			Direction: hub.DirectionBackward,
+			Limit:     limit - len(entries),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, response.Data...)
+		if len(response.Data) == 0 || response.Meta.LeftOff == "" || response.Meta.LeftOff == leftOff {
+			break
+		}
+		leftOff = response.Meta.LeftOff
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp < entries[j].Timestamp
+	})
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return entries, nil
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWithSince(t *testing.T) {
+	since := 10 * time.Minute
+	tests := []struct {
+		name     string
+		query    string
+		since    time.Duration
+		expected string
+	}{
+		{"no since", `http and dst.name == "checkout"`, 0, `http and dst.name == "checkout"`},
+		{"no filter", "", since, "timestamp >= %d"},
+		{"filter and since", `http or grpc`, since, "(http or grpc) and timestamp >= %d"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before := time.Now().Add(-test.since).UnixMilli()
+			actual := withSince(test.query, test.since)
+			after := time.Now().Add(-test.since).UnixMilli()
+
+			if test.since == 0 {
+				if actual != test.expected {
+					t.Errorf("unexpected query - expected: %s, actual: %s", test.expected, actual)
+				}
+				return
+			}
+
+			var timestamp int64
+			if _, err := fmt.Sscanf(actual, test.expected, &timestamp); err != nil || fmt.Sprintf(test.expected, timestamp) != actual {
+				t.Fatalf("unexpected query - expected: %s, actual: %s", test.expected, actual)
+			}
+			if timestamp < before || timestamp > after {
+				t.Errorf("unexpected timestamp %d, expected between %d and %d", timestamp, before, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/creasty/defaults"
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/spf13/cobra"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Resend captured HTTP and gRPC requests, and compare the responses to the captured ones",
+	Long: `Resend the captured HTTP and gRPC requests that match a KFL filter, in the order they were captured,
+and compare every response to the captured one: the status, the content type and the body, JSON bodies
+are compared value by value.
+
+Requests are sent to the service or pod they were captured at, or to a target: another service or pod,
+e.g. a canary, or a url, e.g. a local port. Services are reached through the API server proxy, falling
+back to a port-forward when it fails, and pods through a port-forward. gRPC requests and requests carrying
+auth headers, which the API server would consume, are always port-forwarded. Set connection=port-forward
+to port-forward all the requests, or connection=proxy to proxy them without their auth headers.
+
+Example:
+  kubeshark replay --filter 'http and dst.name == "checkout"' --target svc/checkout-canary
+  kubeshark replay --filter 'http and response.status >= 500' --target localhost:8080 --header 'Authorization: Bearer dev'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Config.Replay.Validate(); err != nil {
+			return errormessage.FormatError(err)
+		}
+
+		runKubesharkReplay()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	defaultReplayConfig := configStructs.ReplayConfig{}
+	if err := defaults.Set(&defaultReplayConfig); err != nil {
+		log.Print(err)
+	}
+
+	replayCmd.Flags().StringP(configStructs.FilterReplayName, "f", defaultReplayConfig.Filter, "A KFL filter selecting the replayed requests")
+	replayCmd.Flags().StringP(configStructs.TargetReplayName, "t", defaultReplayConfig.Target, "Where requests are replayed to, svc/<name>[.<namespace>][:<port>], pod/<name>[.<namespace>][:<port>] or a url (default the service or pod they were captured at)")
+	replayCmd.Flags().Int(configStructs.RateReplayName, defaultReplayConfig.Rate, "The maximum number of requests replayed per second")
+	replayCmd.Flags().StringArray(configStructs.HeaderReplayName, defaultReplayConfig.Header, "Override a header of the replayed requests as \"Name: value\", \"Name:\" removes it")
+	replayCmd.Flags().Int(configStructs.LimitReplayName, defaultReplayConfig.Limit, "The maximum number of requests replayed, 0 replays all the selected requests")
+}
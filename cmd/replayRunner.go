@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/kubeshark/kubeshark/kubernetes"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+	"github.com/kubeshark/kubeshark/pkg/replay"
+	"github.com/kubeshark/kubeshark/utils"
+	"golang.org/x/time/rate"
+	core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// errReplayLimit stops the walk of the entries once the limit of replayed requests is reached.
+var errReplayLimit = errors.New("replay limit reached")
+
+type replaySummary struct {
+	matched  int
+	differed int
+	failed   int
+	skipped  int
+}
+
+func runKubesharkReplay() {
+	kubernetesProvider, err := getKubernetesProviderForCli()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := connectToHub(ctx, kubernetesProvider, cancel)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to connect to the hub: %v", errormessage.FormatError(err)))
+		return
+	}
+
+	// The headers and the target were validated with the config
+	headers, _ := config.Config.Replay.GetHeaders()
+	target, _ := config.Config.Replay.GetTarget()
+	router := newReplayRouter(kubernetesProvider, target)
+	limiter := rate.NewLimiter(rate.Limit(config.Config.Replay.Rate), 1)
+
+	summary := &replaySummary{}
+	err = client.WalkEntries(ctx, config.Config.Replay.Filter, func(entry *hub.Entry) error {
+		if config.Config.Replay.Limit > 0 && summary.matched+summary.differed+summary.failed >= config.Config.Replay.Limit {
+			return errReplayLimit
+		}
+		return replayEntry(ctx, entry, router, limiter, headers, summary)
+	})
+	if err != nil && !errors.Is(err, errReplayLimit) {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to replay the requests: %v", errormessage.FormatError(err)))
+	}
+
+	if summary.skipped > 0 {
+		log.Printf(utils.Warning, fmt.Sprintf("Skipped %d entries that aren't HTTP or gRPC", summary.skipped))
+	}
+	log.Printf("Replayed %d requests, %d matched the captured responses, %d differed and %d failed", summary.matched+summary.differed+summary.failed, summary.matched, summary.differed, summary.failed)
+}
+
+// replayEntry replays the request of an entry and prints how its response differs from the captured one, only the
+// errors of the hub and the context are returned.
+func replayEntry(ctx context.Context, entry *hub.Entry, router *replayRouter, limiter *rate.Limiter, headers []replay.Header, summary *replaySummary) error {
+	request, captured, err := replay.NewRequest(entry)
+	if err != nil {
+		summary.skipped++
+		return nil
+	}
+
+	target, err := router.getTarget(ctx, entry, useServiceProxy(config.Config.Connection, request, headers))
+	if err != nil {
+		summary.failed++
+		log.Printf(utils.Error, fmt.Sprintf("%s %s: %v", request.Method, request.Path, errormessage.FormatError(err)))
+		return ctx.Err()
+	}
+
+	if err := limiter.Wait(ctx); err != nil {
+		return err
+	}
+
+	response, err := target.Send(ctx, request, headers)
+	if target.fallback != nil && ctx.Err() == nil && isServiceProxyFailure(response, err) {
+		log.Printf(utils.Warning, fmt.Sprintf("Couldn't replay to %s through the API server proxy, trying a port-forward", target.description))
+		if target, err = router.fallBack(ctx, target); err != nil {
+			summary.failed++
+			log.Printf(utils.Error, fmt.Sprintf("%s %s: %v", request.Method, request.Path, errormessage.FormatError(err)))
+			return ctx.Err()
+		}
+		response, err = target.Send(ctx, request, headers)
+	}
+
+	line := fmt.Sprintf("%s %s → %s", request.Method, request.Path, target.description)
+	if err != nil {
+		summary.failed++
+		log.Printf(utils.Error, fmt.Sprintf("%s: %v", line, err))
+		return ctx.Err()
+	}
+
+	line = fmt.Sprintf("%s  %d → %d  %s → %s", line, captured.Status, response.Status, time.Duration(entry.ElapsedTime)*time.Millisecond, response.Elapsed.Round(time.Millisecond))
+	differences, err := replay.Diff(captured, response)
+	if err != nil {
+		summary.failed++
+		log.Printf(utils.Error, fmt.Sprintf("%s: failed comparing the responses, %v", line, err))
+		return nil
+	}
+
+	if len(differences) == 0 {
+		summary.matched++
+		log.Printf(utils.Green, line)
+		return nil
+	}
+
+	summary.differed++
+	log.Printf(utils.Yellow, line)
+	for _, difference := range differences {
+		log.Printf("    %s", difference)
+	}
+	return nil
+}
+
+// replayTarget is a target of the replayed requests, described for the output.
+type replayTarget struct {
+	*replay.Target
+	description string
+	key         string
+	// fallback connects to the service of a target reached through the API server proxy with a port-forward
+	fallback func(ctx context.Context) (*replayTarget, error)
+}
+
+// useServiceProxy returns whether a request is replayed to a service through the API server proxy. gRPC
+// requests need HTTP/2 end to end, and the API server consumes auth headers, so in auto mode the requests that
+// carry them are port-forwarded too, while the proxy mode drops them.
+func useServiceProxy(connection string, request *replay.Request, headers []replay.Header) bool {
+	switch {
+	case connection == config.ConnectionPortForward || request.Grpc:
+		return false
+	case connection == config.ConnectionProxy:
+		return true
+	default:
+		return !request.HasAuth(headers)
+	}
+}
+
+// isServiceProxyFailure returns whether a request failed to reach a service through the API server proxy, the
+// API server responds to the requests it fails to proxy with a status of its own.
+func isServiceProxyFailure(response *replay.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	if response.Status < http.StatusBadRequest {
+		return false
+	}
+
+	var status metav1.Status
+	if json.Unmarshal(response.Body, &status) != nil {
+		return false
+	}
+	return status.Kind == "Status" && status.APIVersion == "v1"
+}
+
+// replayRouter maps entries to the targets their requests are replayed to, targets are connected to once and
+// reused by the following entries.
+type replayRouter struct {
+	kubernetesProvider *kubernetes.Provider
+	target             *configStructs.ReplayTarget
+	targets            map[string]*replayTarget
+	failures           map[string]error
+}
+
+func newReplayRouter(kubernetesProvider *kubernetes.Provider, target *configStructs.ReplayTarget) *replayRouter {
+	return &replayRouter{
+		kubernetesProvider: kubernetesProvider,
+		target:             target,
+		targets:            map[string]*replayTarget{},
+		failures:           map[string]error{},
+	}
+}
+
+// getTarget returns the target of an entry, the target of the config, or the service or pod the entry was
+// captured at. Targets that name no namespace are in the namespace of the captured destination.
+func (router *replayRouter) getTarget(ctx context.Context, entry *hub.Entry, serviceProxy bool) (*replayTarget, error) {
+	if router.target != nil && router.target.Kind == configStructs.ReplayTargetUrl {
+		return router.connect(ctx, router.target, serviceProxy)
+	}
+
+	name, namespace := splitResolvedName(entry.Destination)
+	var port int64
+	if entry.Destination != nil {
+		port, _ = strconv.ParseInt(entry.Destination.Port, 10, 32)
+	}
+	if router.target == nil {
+		if namespace == "" {
+			return nil, fmt.Errorf("the destination %s wasn't resolved to a service or pod, set --%s", name, configStructs.TargetReplayName)
+		}
+		return router.connect(ctx, &configStructs.ReplayTarget{Name: name, Namespace: namespace, Port: int32(port)}, serviceProxy)
+	}
+
+	target := *router.target
+	if target.Namespace == "" {
+		target.Namespace = namespace
+	}
+	if target.Namespace == "" {
+		currentNamespace, err := router.kubernetesProvider.CurrentNamespace()
+		if err != nil {
+			return nil, err
+		}
+		target.Namespace = currentNamespace
+	}
+	if target.Port == 0 {
+		target.Port = int32(port)
+	}
+	return router.connect(ctx, &target, serviceProxy)
+}
+
+// connect returns the cached target of a service, pod or url, and connects to it the first time. A target
+// without a kind is the service by its name, or the pod when there's no such service.
+func (router *replayRouter) connect(ctx context.Context, target *configStructs.ReplayTarget, serviceProxy bool) (*replayTarget, error) {
+	key := fmt.Sprintf("%s/%s.%s:%d/%s/%t", target.Kind, target.Name, target.Namespace, target.Port, target.Url, serviceProxy)
+	if connected, ok := router.targets[key]; ok {
+		return connected, nil
+	}
+	if err, ok := router.failures[key]; ok {
+		return nil, err
+	}
+
+	connected, err := router.connectOnce(ctx, target, serviceProxy)
+	if err != nil {
+		router.failures[key] = err
+		return nil, err
+	}
+	connected.key = key
+	router.targets[key] = connected
+	return connected, nil
+}
+
+// fallBack replaces a target reached through the API server proxy with a port-forward to its service, for the
+// requests that follow too.
+func (router *replayRouter) fallBack(ctx context.Context, target *replayTarget) (*replayTarget, error) {
+	delete(router.targets, target.key)
+	forwarded, err := target.fallback(ctx)
+	if err != nil {
+		router.failures[target.key] = err
+		return nil, err
+	}
+	forwarded.key = target.key
+	router.targets[target.key] = forwarded
+	return forwarded, nil
+}
+
+func (router *replayRouter) connectOnce(ctx context.Context, target *configStructs.ReplayTarget, serviceProxy bool) (*replayTarget, error) {
+	if target.Kind == configStructs.ReplayTargetUrl {
+		return &replayTarget{Target: replay.NewTarget(target.Url, nil, true), description: target.Url}, nil
+	}
+
+	if target.Kind != configStructs.ReplayTargetPod {
+		service, err := router.kubernetesProvider.GetService(ctx, target.Namespace, target.Name)
+		if err == nil {
+			return router.connectService(ctx, target, service, serviceProxy)
+		}
+		if target.Kind == configStructs.ReplayTargetService || !k8serrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get service %s.%s, %w", target.Name, target.Namespace, err)
+		}
+	}
+
+	if target.Port <= 0 {
+		return nil, fmt.Errorf("no port to replay to pod %s.%s, name one as pod/%s.%s:<port>", target.Name, target.Namespace, target.Name, target.Namespace)
+	}
+	localUrl, err := router.startPortForward(ctx, target.Namespace, target.Name, uint16(target.Port))
+	if err != nil {
+		return nil, err
+	}
+	return &replayTarget{
+		Target:      replay.NewTarget(localUrl, nil, true),
+		description: fmt.Sprintf("pod/%s.%s:%d", target.Name, target.Namespace, target.Port),
+	}, nil
+}
+
+// connectService replays to a service through the API server proxy, or through a port-forward to one of its
+// pods. In auto mode, the proxy falls back to the port-forward when it fails.
+func (router *replayRouter) connectService(ctx context.Context, target *configStructs.ReplayTarget, service *core.Service, serviceProxy bool) (*replayTarget, error) {
+	servicePort, err := kubernetes.GetServicePort(service, target.Port)
+	if err != nil {
+		return nil, err
+	}
+	description := fmt.Sprintf("svc/%s.%s:%d", service.Name, service.Namespace, servicePort.Port)
+
+	if !serviceProxy {
+		return router.portForwardService(ctx, service, servicePort, description)
+	}
+
+	transport, proxyUrl, err := router.kubernetesProvider.GetServiceProxy(service.Namespace, service.Name, servicePort.Port)
+	if err != nil {
+		if config.Config.Connection == config.ConnectionProxy {
+			return nil, err
+		}
+		log.Printf(utils.Warning, fmt.Sprintf("Couldn't proxy to %s through the API server, trying a port-forward: %v", description, err))
+		return router.portForwardService(ctx, service, servicePort, description)
+	}
+
+	proxyTarget := &replayTarget{Target: replay.NewTarget(proxyUrl, transport, false), description: description}
+	proxyTarget.DropAuth = true
+	if config.Config.Connection != config.ConnectionProxy {
+		proxyTarget.fallback = func(ctx context.Context) (*replayTarget, error) {
+			return router.portForwardService(ctx, service, servicePort, description)
+		}
+	}
+	return proxyTarget, nil
+}
+
+// portForwardService replays to a service through a port-forward to one of its pods.
+func (router *replayRouter) portForwardService(ctx context.Context, service *core.Service, servicePort *core.ServicePort, description string) (*replayTarget, error) {
+	podName, podPort, err := router.kubernetesProvider.GetServiceBackend(ctx, service, servicePort)
+	if err != nil {
+		return nil, err
+	}
+	localUrl, err := router.startPortForward(ctx, service.Namespace, podName, podPort)
+	if err != nil {
+		return nil, err
+	}
+	return &replayTarget{Target: replay.NewTarget(localUrl, nil, true), description: fmt.Sprintf("%s via pod/%s", description, podName)}, nil
+}
+
+// startPortForward forwards a free local port to a port of a pod, for as long as ctx isn't done.
+func (router *replayRouter) startPortForward(ctx context.Context, namespace string, podName string, podPort uint16) (string, error) {
+	localPort, err := kubernetes.FindFreePort(kubernetes.LocalhostIp)
+	if err != nil {
+		return "", err
+	}
+
+	podRegex := regexp.MustCompile(fmt.Sprintf("^%s$", regexp.QuoteMeta(podName)))
+	if err := kubernetes.StartPodPortForward(ctx, router.kubernetesProvider, kubernetes.LocalhostIp, namespace, podRegex, localPort, podPort); err != nil {
+		return "", fmt.Errorf("failed to port-forward to pod %s.%s, %w", podName, namespace, err)
+	}
+	return fmt.Sprintf("http://%s:%d", kubernetes.LocalhostIp, localPort), nil
+}
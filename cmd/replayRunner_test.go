@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+	"github.com/kubeshark/kubeshark/pkg/replay"
+)
+
+func TestUseServiceProxy(t *testing.T) {
+	authorized := &replay.Request{Headers: hub.NameValues{{Name: "Authorization", Value: "Bearer app"}}}
+	anonymous := &replay.Request{}
+	removeAuth := []replay.Header{{Name: "Authorization"}}
+
+	tests := []struct {
+		connection string
+		request    *replay.Request
+		headers    []replay.Header
+		expected   bool
+	}{
+		{config.ConnectionAuto, anonymous, nil, true},
+		{config.ConnectionAuto, authorized, nil, false},
+		{config.ConnectionAuto, authorized, removeAuth, true},
+		{config.ConnectionAuto, &replay.Request{Grpc: true}, nil, false},
+		{config.ConnectionProxy, authorized, nil, true},
+		{config.ConnectionPortForward, anonymous, nil, false},
+	}
+	for _, test := range tests {
+		if actual := useServiceProxy(test.connection, test.request, test.headers); actual != test.expected {
+			t.Errorf("unexpected proxy use %t of %s request %+v with headers %v", actual, test.connection, test.request, test.headers)
+		}
+	}
+}
+
+func TestIsServiceProxyFailure(t *testing.T) {
+	apiServerStatus := []byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Unauthorized","code":401}`)
+	if !isServiceProxyFailure(&replay.Response{Status: 401, Body: apiServerStatus}, nil) {
+		t.Error("expected a status of the API server to fail")
+	}
+	if !isServiceProxyFailure(nil, errors.New("connection refused")) {
+		t.Error("expected an error to fail")
+	}
+	if isServiceProxyFailure(&replay.Response{Status: 401, Body: []byte(`{"error":"invalid token"}`)}, nil) {
+		t.Error("expected a response of the service not to fail")
+	}
+}
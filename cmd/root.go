@@ -7,6 +7,7 @@ import (
 
 	"github.com/creasty/defaults"
 	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/kubernetes"
 	"github.com/kubeshark/kubeshark/kubeshark/version"
 	"github.com/kubeshark/kubeshark/utils"
 	"github.com/spf13/cobra"
@@ -21,6 +22,7 @@ Further info is available at https://github.com/kubeshark/kubeshark`,
 		if err := config.InitConfig(cmd); err != nil {
 			log.Fatal(err)
 		}
+		kubernetes.SetInstance(config.Config.Instance)
 
 		return nil
 	},
@@ -33,6 +35,7 @@ func init() {
 	}
 
 	rootCmd.PersistentFlags().StringSlice(config.SetCommandName, []string{}, fmt.Sprintf("Override values using --%s", config.SetCommandName))
+	rootCmd.PersistentFlags().String(config.InstanceConfigName, defaultConfig.Instance, "Name of the Kubeshark instance to use, instances run side by side with their own resources")
 	rootCmd.PersistentFlags().String(config.ConfigFilePathCommandName, defaultConfig.ConfigFilePath, fmt.Sprintf("Override config file path using --%s", config.ConfigFilePathCommandName))
 }
 
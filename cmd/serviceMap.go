@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/creasty/defaults"
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/spf13/cobra"
+)
+
+var serviceMapCmd = &cobra.Command{
+	Use:   "servicemap",
+	Short: "Get the service map the hub builds from the traffic",
+}
+
+var serviceMapExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the service map as a Graphviz DOT, Mermaid or JSON graph",
+	Long: `Export the service map the hub builds from the traffic as a Graphviz DOT graph, a Mermaid flowchart
+or JSON. The nodes are grouped by namespace and annotated with the workload of their pods, and the edges
+with their protocol, request count, and the error rate and p95 latency of the entries the hub holds.
+
+Example:
+  kubeshark servicemap export --format mermaid -o docs/service-map.mmd
+  kubeshark servicemap export --since 1h | dot -Tsvg > service-map.svg`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{config.ConfigKeyAnnotation: configStructs.ServiceMapExportConfigKey},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Config.ServiceMapExport.Validate(); err != nil {
+			return errormessage.FormatError(err)
+		}
+
+		runKubesharkServiceMapExport()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serviceMapCmd)
+	serviceMapCmd.AddCommand(serviceMapExportCmd)
+
+	defaultServiceMapExportConfig := configStructs.ServiceMapExportConfig{}
+	if err := defaults.Set(&defaultServiceMapExportConfig); err != nil {
+		log.Print(err)
+	}
+
+	serviceMapExportCmd.Flags().String(configStructs.FormatServiceMapName, defaultServiceMapExportConfig.Format, "The format of the graph, dot, mermaid or json")
+	serviceMapExportCmd.Flags().StringP(configStructs.OutputServiceMapName, "o", defaultServiceMapExportConfig.Output, "Path of the graph, the standard output by default")
+	serviceMapExportCmd.Flags().String(configStructs.SinceServiceMapName, defaultServiceMapExportConfig.Since, "Compute the error rates and latencies from the entries of the last duration, e.g. 1h, all the entries by default")
+}
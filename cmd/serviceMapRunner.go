@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/kubeshark/kubeshark/kubernetes"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+	"github.com/kubeshark/kubeshark/pkg/servicemap"
+	"github.com/kubeshark/kubeshark/utils"
+)
+
+func runKubesharkServiceMapExport() {
+	kubernetesProvider, err := getKubernetesProviderForCli()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := connectToHub(ctx, kubernetesProvider, cancel)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to connect to the hub: %v", errormessage.FormatError(err)))
+		return
+	}
+
+	if !config.Config.ServiceMap {
+		log.Printf(utils.Warning, "The hub doesn't build the service map, tap with --set service-map=true")
+	}
+
+	serviceMap, err := client.GetServiceMap(ctx)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to get the service map: %v", errormessage.FormatError(err)))
+		return
+	}
+	if len(serviceMap.Nodes) == 0 {
+		log.Printf("The service map is empty, it's built from the traffic of the tapped pods")
+		return
+	}
+
+	graph := servicemap.NewGraph(serviceMap)
+
+	exportConfig := config.Config.ServiceMapExport
+	stats := servicemap.NewStats()
+	err = client.WalkBaseEntries(ctx, withSince("", exportConfig.GetSince()), func(entry *hub.BaseEntry) error {
+		stats.Add(entry)
+		return nil
+	})
+	if err != nil {
+		log.Printf(utils.Warning, fmt.Sprintf("Failed to read the entries, the edges have no error rates and latencies: %v", errormessage.FormatError(err)))
+	} else {
+		graph.SetStats(stats)
+	}
+
+	setServiceMapWorkloads(ctx, kubernetesProvider, graph)
+
+	if exportConfig.Output == "" {
+		if err := servicemap.Write(os.Stdout, graph, exportConfig.Format); err != nil {
+			log.Printf(utils.Error, fmt.Sprintf("Failed to export the service map: %v", err))
+		}
+		return
+	}
+
+	if err := writeServiceMap(graph, exportConfig.Format, exportConfig.Output); err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to export the service map: %v", err))
+		_ = os.Remove(exportConfig.Output)
+		return
+	}
+	log.Printf("Exported the service map of %d nodes and %d edges to %s", len(graph.Nodes), len(graph.Edges), fmt.Sprintf(utils.Purple, exportConfig.Output))
+}
+
+// setServiceMapWorkloads annotates the resolved nodes with the workloads of their pods, the nodes are left as
+// they are when the cluster can't be read.
+func setServiceMapWorkloads(ctx context.Context, kubernetesProvider *kubernetes.Provider, graph *servicemap.Graph) {
+	for _, node := range graph.Nodes {
+		if !node.Resolved || node.Namespace == "" {
+			continue
+		}
+
+		workload, err := kubernetesProvider.GetWorkload(ctx, node.Namespace, node.Name)
+		if err != nil {
+			log.Printf(utils.Warning, fmt.Sprintf("Failed to get the workloads of the nodes: %v", errormessage.FormatError(err)))
+			return
+		}
+		if workload != nil {
+			node.Workload = workload.String()
+		}
+	}
+}
+
+func writeServiceMap(graph *servicemap.Graph, format string, filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+
+	err = servicemap.Write(file, graph, format)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
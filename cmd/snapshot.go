@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/creasty/defaults"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/spf13/cobra"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save the recorded traffic to a file, or load a saved one into a new hub",
+}
+
+var snapshotSaveCmd = &cobra.Command{
+	Use:   "save",
+	Short: "Save the traffic the hub recorded, with the targets, config and time range of the session",
+	Long: `Save the traffic database of the hub to a gzipped tar archive, along with the session metadata:
+the tapped pods, the config the hub runs with and the time range of the recorded traffic.
+
+Example:
+  kubeshark snapshot save -o incident-42.tar.gz`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runKubesharkSnapshotSave()
+		return nil
+	},
+}
+
+var snapshotLoadCmd = &cobra.Command{
+	Use:   "load FILE",
+	Short: "Deploy a hub without workers that serves a saved snapshot",
+	Long: `Deploy a hub and front without workers in a namespace of their own, restore a saved snapshot into
+them, and open the UI to browse it. Kubeshark is removed from the namespace once the command exits.
+
+Example:
+  kubeshark snapshot load incident-42.tar.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runKubesharkSnapshotLoad(args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.AddCommand(snapshotSaveCmd)
+	snapshotCmd.AddCommand(snapshotLoadCmd)
+
+	defaultSnapshotConfig := configStructs.SnapshotConfig{}
+	if err := defaults.Set(&defaultSnapshotConfig); err != nil {
+		log.Print(err)
+	}
+
+	snapshotSaveCmd.Flags().StringP(configStructs.OutputSnapshotName, "o", defaultSnapshotConfig.Save.Output, "Path of the snapshot (default current <pwd>/kubeshark_snapshot.tar.gz)")
+	snapshotLoadCmd.Flags().StringP(configStructs.NamespaceSnapshotName, "n", defaultSnapshotConfig.Load.Namespace, "The namespace to deploy the hub serving the snapshot in")
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/kubeshark/kubeshark/cmd/goUtils"
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/kubeshark/kubeshark/kubernetes"
+	"github.com/kubeshark/kubeshark/kubeshark"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+	"github.com/kubeshark/kubeshark/pkg/snapshot"
+	"github.com/kubeshark/kubeshark/utils"
+	"github.com/kubeshark/worker/models"
+)
+
+func runKubesharkSnapshotSave() {
+	kubernetesProvider, err := getKubernetesProviderForCli()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := connectToHub(ctx, kubernetesProvider, cancel)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to connect to the hub: %v", errormessage.FormatError(err)))
+		return
+	}
+
+	metadata, err := getSnapshotMetadata(ctx, kubernetesProvider, client)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to get the session metadata: %v", errormessage.FormatError(err)))
+		return
+	}
+
+	filePath := config.Config.Snapshot.Save.FilePath()
+	file, err := os.Create(filePath)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to create %s: %v", filePath, err))
+		return
+	}
+
+	log.Printf("Saving %d entries recorded in namespace %s...", metadata.Entries, config.Config.ResourcesNamespace)
+	err = saveSnapshot(ctx, kubernetesProvider, metadata, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to save the snapshot: %v", errormessage.FormatError(err)))
+		_ = os.Remove(filePath)
+		return
+	}
+
+	log.Printf("Saved the snapshot to %s, load it with `kubeshark snapshot load %s`", fmt.Sprintf(utils.Purple, filePath), filePath)
+}
+
+func getSnapshotMetadata(ctx context.Context, kubernetesProvider *kubernetes.Provider, client *hub.Client) (*snapshot.Metadata, error) {
+	metadata := &snapshot.Metadata{
+		FormatVersion: snapshot.FormatVersion,
+		CliVersion:    kubeshark.Ver,
+		CreatedAt:     time.Now().UTC(),
+		Instance:      kubernetes.Instance,
+		Namespace:     config.Config.ResourcesNamespace,
+		Targets:       []snapshot.Target{},
+	}
+
+	tappedPods, err := client.GetTappedPods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range tappedPods {
+		metadata.Targets = append(metadata.Targets, snapshot.Target{Name: pod.Name, Namespace: pod.Namespace})
+	}
+
+	configMap, err := kubernetesProvider.GetConfigMap(ctx, config.Config.ResourcesNamespace, kubernetes.ConfigMapName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config map %s, %w", kubernetes.ConfigMapName, err)
+	}
+	if serializedConfig := configMap.Data[models.ConfigFileName]; serializedConfig != "" {
+		metadata.Config = json.RawMessage(serializedConfig)
+	}
+
+	last, err := client.QueryEntries(ctx, hub.EntriesQuery{Direction: hub.DirectionBackward, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	first, err := client.QueryEntries(ctx, hub.EntriesQuery{Direction: hub.DirectionForward, Limit: 1})
+	if err != nil {
+		return nil, err
+	}
+	if len(first.Data) > 0 && len(last.Data) > 0 {
+		metadata.From = time.UnixMilli(first.Data[0].Timestamp).UTC()
+		metadata.To = time.UnixMilli(last.Data[0].Timestamp).UTC()
+	}
+	metadata.Entries = last.Meta.Total
+
+	return metadata, nil
+}
+
+// saveSnapshot streams the data directory of basenine, tarred in its container, into the snapshot.
+func saveSnapshot(ctx context.Context, kubernetesProvider *kubernetes.Provider, metadata *snapshot.Metadata, out io.Writer) error {
+	data, dataWriter := io.Pipe()
+	go func() {
+		var stderr bytes.Buffer
+		err := kubernetesProvider.ExecInPod(ctx, config.Config.ResourcesNamespace, kubernetes.HubPodName, kubernetes.BasenineContainerName, []string{"tar", "cf", "-", "-C", models.DataDirPath, "."}, nil, dataWriter, &stderr)
+		if err != nil && stderr.Len() > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		dataWriter.CloseWithError(err)
+	}()
+
+	err := snapshot.Save(out, metadata, data)
+	_ = data.Close()
+	return err
+}
+
+func runKubesharkSnapshotLoad(filePath string) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to open %s: %v", filePath, err))
+		return
+	}
+	defer file.Close()
+
+	loadedSnapshot, err := snapshot.Open(file)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to read %s: %v", filePath, errormessage.FormatError(err)))
+		return
+	}
+	defer loadedSnapshot.Close()
+	printSnapshotMetadata(loadedSnapshot.Metadata)
+
+	kubernetesProvider, err := getKubernetesProviderForCli()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config.Config.ResourcesNamespace = config.Config.Snapshot.Load.Namespace
+	// The snapshot is restored into the persistent volume claim before basenine starts and loads it
+	config.Config.Tap.Storage.Enabled = true
+	if err := resolveLocalPorts(true, getSetHint(config.FrontSrcPortConfigName)); err != nil {
+		log.Printf(utils.Error, errormessage.FormatError(err))
+		return
+	}
+
+	serializedKubesharkConfig, err := getSnapshotTapConfig(loadedSnapshot.Metadata)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Error serializing kubeshark config: %v", errormessage.FormatError(err)))
+		return
+	}
+
+	if err := deployHubWithoutWorkers(ctx, kubernetesProvider, serializedKubesharkConfig, true); err != nil {
+		printSessionError(err)
+		return
+	}
+	defer finishTapExecution(kubernetesProvider)
+	go goUtils.HandleExcWrapper(watchHubEvents, ctx, kubernetesProvider, cancel)
+	go goUtils.HandleExcWrapper(renewLease, ctx, kubernetesProvider, cancel)
+
+	log.Printf("Restoring %d entries...", loadedSnapshot.Metadata.Entries)
+	if err := restoreSnapshot(ctx, kubernetesProvider, loadedSnapshot); err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to restore the snapshot: %v", errormessage.FormatError(err)))
+		return
+	}
+
+	if err := waitForPodRunning(ctx, kubernetesProvider, kubernetes.HubPodName); err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Kubeshark Hub was not ready: %v", errormessage.FormatError(err)))
+		return
+	}
+
+	if _, err := connectToHub(ctx, kubernetesProvider, cancel); err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to connect to the hub: %v", errormessage.FormatError(err)))
+		return
+	}
+	if err := waitForPodRunning(ctx, kubernetesProvider, kubernetes.FrontPodName); err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Kubeshark front was not ready: %v", errormessage.FormatError(err)))
+		return
+	}
+	postFrontStarted(ctx, kubernetesProvider, cancel)
+	log.Printf("Kubeshark in namespace %s is removed once this command exits", config.Config.ResourcesNamespace)
+
+	utils.WaitForFinish(ctx, cancel)
+}
+
+func printSnapshotMetadata(metadata *snapshot.Metadata) {
+	log.Printf("Snapshot of namespace %s, saved at %s with %d entries", metadata.Namespace, metadata.CreatedAt.Local().Format(entryTimeFormat), metadata.Entries)
+	if !metadata.From.IsZero() {
+		log.Printf("Recorded from %s to %s", metadata.From.Local().Format(entryTimeFormat), metadata.To.Local().Format(entryTimeFormat))
+	}
+	for _, target := range metadata.Targets {
+		log.Printf(utils.Green, fmt.Sprintf("+%s.%s", target.Name, target.Namespace))
+	}
+}
+
+// getSnapshotTapConfig returns the config the snapshot was recorded with, moved to the namespace it's loaded in.
+func getSnapshotTapConfig(metadata *snapshot.Metadata) (string, error) {
+	conf := getTapConfig()
+	if len(metadata.Config) > 0 {
+		if err := json.Unmarshal(metadata.Config, conf); err != nil {
+			return "", fmt.Errorf("invalid config in the snapshot, %w", err)
+		}
+		conf.KubesharkResourcesNamespace = config.Config.ResourcesNamespace
+		conf.PullPolicy = config.Config.ImagePullPolicyStr
+	}
+
+	return getSerializedTapConfig(conf)
+}
+
+// restoreSnapshot extracts the data of a snapshot in the data directory of basenine from the restore container of
+// the hub pod, which holds basenine back until the data is restored, so basenine loads it once it starts.
+func restoreSnapshot(ctx context.Context, kubernetesProvider *kubernetes.Provider, loadedSnapshot *snapshot.Snapshot) error {
+	if err := waitForInitContainerRunning(ctx, kubernetesProvider, kubernetes.HubPodName, kubernetes.RestoreContainerName); err != nil {
+		return err
+	}
+
+	data, dataWriter := io.Pipe()
+	go func() {
+		dataWriter.CloseWithError(loadedSnapshot.WriteData(dataWriter))
+	}()
+
+	command := fmt.Sprintf("tar xf - -C %s && touch %s", models.DataDirPath, path.Join(models.DataDirPath, kubernetes.RestoredFileName))
+	var stderr bytes.Buffer
+	err := kubernetesProvider.ExecInPod(ctx, config.Config.ResourcesNamespace, kubernetes.HubPodName, kubernetes.RestoreContainerName, []string{"sh", "-c", command}, data, nil, &stderr)
+	_ = data.Close()
+	if err != nil && stderr.Len() > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return err
+}
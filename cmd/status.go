@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the Kubeshark instances running in the cluster",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runKubesharkStatus(cmd.Flags().Changed(config.InstanceConfigName))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/kubeshark/kubeshark/kubernetes"
+	"github.com/kubeshark/kubeshark/utils"
+	core "k8s.io/api/core/v1"
+)
+
+type instanceStatus struct {
+	instance  string
+	namespace string
+	pods      []core.Pod
+}
+
+func runKubesharkStatus(onlyCurrentInstance bool) {
+	kubernetesProvider, err := getKubernetesProviderForCli()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pods, err := kubernetesProvider.ListManagedPods(ctx, kubernetes.K8sAllNamespaces)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to list the Kubeshark pods: %v", err))
+		return
+	}
+
+	statuses := groupPodsByInstance(pods, onlyCurrentInstance)
+	if len(statuses) == 0 {
+		if onlyCurrentInstance {
+			log.Printf("Kubeshark instance %s is not running", kubernetes.Instance)
+		} else {
+			log.Printf("No Kubeshark instance is running")
+		}
+		return
+	}
+
+	for _, status := range statuses {
+		log.Printf("Instance %s in namespace %s:", status.instance, status.namespace)
+		for _, pod := range status.pods {
+			log.Printf("  %s %s", pod.Name, getPodState(pod))
+		}
+	}
+}
+
+func groupPodsByInstance(pods []core.Pod, onlyCurrentInstance bool) []*instanceStatus {
+	statusesByKey := make(map[string]*instanceStatus)
+	for _, pod := range pods {
+		instance := kubernetes.GetInstance(pod.Labels)
+		if onlyCurrentInstance && instance != kubernetes.Instance {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s", pod.Namespace, instance)
+		status, ok := statusesByKey[key]
+		if !ok {
+			status = &instanceStatus{instance: instance, namespace: pod.Namespace}
+			statusesByKey[key] = status
+		}
+		status.pods = append(status.pods, pod)
+	}
+
+	statuses := make([]*instanceStatus, 0, len(statusesByKey))
+	for _, status := range statusesByKey {
+		sort.Slice(status.pods, func(i, j int) bool {
+			return status.pods[i].Name < status.pods[j].Name
+		})
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].instance != statuses[j].instance {
+			return statuses[i].instance < statuses[j].instance
+		}
+		return statuses[i].namespace < statuses[j].namespace
+	})
+
+	return statuses
+}
+
+func getPodState(pod core.Pod) string {
+	ready := 0
+	reasons := make([]string, 0)
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if containerStatus.Ready {
+			ready++
+		}
+		if containerStatus.State.Waiting != nil && containerStatus.State.Waiting.Reason != "" {
+			reasons = append(reasons, containerStatus.State.Waiting.Reason)
+		}
+	}
+
+	state := fmt.Sprintf("%s (%d/%d ready)", pod.Status.Phase, ready, len(pod.Spec.Containers))
+	if len(reasons) > 0 {
+		state = fmt.Sprintf("%s %s", state, strings.Join(reasons, ", "))
+	}
+	return state
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"log"
+
+	"github.com/creasty/defaults"
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/spf13/cobra"
+)
+
+var tailCmd = &cobra.Command{
+	Use:   "tail [KFL]",
+	Short: "Print the captured traffic as it arrives",
+	Long: `Print a line for every request and response pair the hub captures that matches a KFL filter.
+An empty filter matches all the entries.
+
+The line can be changed with a Go template, it's executed with the fields Time, Protocol, Method,
+Path, Status, Latency, Source, SourceNamespace, Destination, DestinationNamespace and Entry.
+
+Example:
+  kubeshark tail 'http and dst.name == "checkout"'
+  kubeshark tail --format '{{.Method}} {{.Path}} {{.Status}}'`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Config.Tail.Validate(); err != nil {
+			return errormessage.FormatError(err)
+		}
+
+		var query string
+		if len(args) > 0 {
+			query = args[0]
+		}
+		runKubesharkTail(query)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tailCmd)
+
+	defaultTailConfig := configStructs.TailConfig{}
+	if err := defaults.Set(&defaultTailConfig); err != nil {
+		log.Print(err)
+	}
+
+	tailCmd.Flags().String(configStructs.FormatTailName, defaultTailConfig.Format, "A Go template to print every entry with")
+	tailCmd.Flags().Bool(configStructs.RawTailName, defaultTailConfig.Raw, "Print the headers and bodies of the requests and responses")
+}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/errormessage"
+	"github.com/kubeshark/kubeshark/internal/connect"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+	"github.com/kubeshark/kubeshark/utils"
+)
+
+const tailReconnectDelay = 2 * time.Second
+
+// tailLine is what a tail --format template is executed with.
+type tailLine struct {
+	Time                 time.Time
+	Protocol             string
+	Method               string
+	Path                 string
+	Status               int
+	Latency              time.Duration
+	Source               string
+	SourceNamespace      string
+	Destination          string
+	DestinationNamespace string
+	Entry                *hub.BaseEntry
+}
+
+func newTailLine(entry *hub.BaseEntry) *tailLine {
+	line := &tailLine{
+		Time:     time.UnixMilli(entry.Timestamp),
+		Protocol: entry.Protocol.Abbreviation,
+		Method:   entry.Method,
+		Path:     entry.Summary,
+		Status:   entry.Status,
+		Latency:  time.Duration(entry.Latency) * time.Millisecond,
+		Entry:    entry,
+	}
+	line.Source, line.SourceNamespace = splitResolvedName(entry.Source)
+	line.Destination, line.DestinationNamespace = splitResolvedName(entry.Destination)
+	return line
+}
+
+// splitResolvedName splits the name the hub resolved an endpoint to, <name>.<namespace>, an unresolved
+// endpoint is named by its address.
+func splitResolvedName(endpoint *hub.TCP) (string, string) {
+	if endpoint == nil {
+		return "", ""
+	}
+	if endpoint.Name == "" {
+		return fmt.Sprintf("%s:%s", endpoint.IP, endpoint.Port), ""
+	}
+	parts := strings.SplitN(endpoint.Name, ".", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return endpoint.Name, ""
+}
+
+// tailPrinter prints a line for every entry, colorized when printed to a terminal.
+type tailPrinter struct {
+	out      io.Writer
+	template *template.Template
+	raw      bool
+	colorize bool
+	client   *hub.Client
+}
+
+func newTailPrinter(out *os.File, client *hub.Client) (*tailPrinter, error) {
+	printer := &tailPrinter{
+		out:      out,
+		raw:      config.Config.Tail.Raw,
+		colorize: isTerminal(out),
+		client:   client,
+	}
+
+	if config.Config.Tail.Format != "" {
+		tmpl, err := template.New("tail").Parse(config.Config.Tail.Format)
+		if err != nil {
+			return nil, err
+		}
+		printer.template = tmpl
+	}
+	return printer, nil
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func (printer *tailPrinter) color(format string, text string) string {
+	if !printer.colorize || text == "" {
+		return text
+	}
+	return fmt.Sprintf(format, text)
+}
+
+func (printer *tailPrinter) getStatusColor(status int) string {
+	switch {
+	case status >= 500:
+		return utils.Red
+	case status >= 400:
+		return utils.Yellow
+	case status >= 300:
+		return utils.Teal
+	default:
+		return utils.Green
+	}
+}
+
+func formatEndpoint(name string, namespace string) string {
+	if namespace == "" {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, namespace)
+}
+
+func (printer *tailPrinter) print(ctx context.Context, entry *hub.BaseEntry) error {
+	line := newTailLine(entry)
+
+	if printer.template != nil {
+		var builder strings.Builder
+		if err := printer.template.Execute(&builder, line); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(printer.out, builder.String()); err != nil {
+			return err
+		}
+	} else {
+		status := ""
+		if line.Status != 0 {
+			status = printer.color(printer.getStatusColor(line.Status), fmt.Sprint(line.Status))
+		}
+		if _, err := fmt.Fprintf(printer.out, "%s %s %s %s %s %s %s → %s\n",
+			line.Time.Local().Format(entryTimeFormat),
+			line.Protocol,
+			printer.color(utils.Purple, line.Method),
+			line.Path,
+			status,
+			line.Latency,
+			formatEndpoint(line.Source, line.SourceNamespace),
+			formatEndpoint(line.Destination, line.DestinationNamespace)); err != nil {
+			return err
+		}
+	}
+
+	if printer.raw {
+		return printer.printRaw(ctx, entry)
+	}
+	return nil
+}
+
+// printRaw prints the headers and bodies of an HTTP entry, and the request and response of other protocols
+// as they are.
+func (printer *tailPrinter) printRaw(ctx context.Context, entry *hub.BaseEntry) error {
+	fullEntry, err := printer.client.GetEntry(ctx, entry.Id, "")
+	if err != nil {
+		log.Printf(utils.Warning, fmt.Sprintf("Failed to get entry %s: %v", entry.Id, errormessage.FormatError(err)))
+		return nil
+	}
+	if fullEntry.Data == nil {
+		return nil
+	}
+
+	if !fullEntry.Data.IsHttp() {
+		return newEntryPrinter(printer.out, "").printExpanded(fullEntry.Data)
+	}
+
+	var raw strings.Builder
+	if request, err := fullEntry.Data.GetHttpRequest(); err == nil {
+		fmt.Fprintf(&raw, "> %s %s %s\n", request.Method, request.Url, request.HttpVersion)
+		for _, header := range request.Headers {
+			fmt.Fprintf(&raw, "> %s: %s\n", header.Name, header.Value)
+		}
+		if body := request.Body(); len(body) > 0 {
+			fmt.Fprintf(&raw, ">\n%s\n", indent(string(body), "> "))
+		}
+	}
+	if response, err := fullEntry.Data.GetHttpResponse(); err == nil {
+		fmt.Fprintf(&raw, "< %s %d %s\n", response.HttpVersion, response.Status, response.StatusText)
+		for _, header := range response.Headers {
+			fmt.Fprintf(&raw, "< %s: %s\n", header.Name, header.Value)
+		}
+		if body, err := response.Body(); err == nil && len(body) > 0 {
+			fmt.Fprintf(&raw, "<\n%s\n", indent(string(body), "< "))
+		}
+	}
+
+	_, err = io.WriteString(printer.out, raw.String())
+	return err
+}
+
+// tailEntries prints the entries that match query until ctx is done, reconnecting when the stream is lost,
+// e.g. because the hub restarted.
+func tailEntries(ctx context.Context, client *hub.Client, query string, printer *tailPrinter) {
+	for ctx.Err() == nil {
+		if err := streamEntries(ctx, client, query, printer); err != nil && ctx.Err() == nil {
+			log.Printf(utils.Warning, fmt.Sprintf("Lost the live stream of the hub, reconnecting: %v", errormessage.FormatError(err)))
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(tailReconnectDelay):
+		}
+	}
+}
+
+func streamEntries(ctx context.Context, client *hub.Client, query string, printer *tailPrinter) error {
+	stream, err := client.StreamEntries(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	for {
+		entry, err := stream.Next()
+		if err != nil {
+			return err
+		}
+		if err := printer.print(ctx, entry); err != nil {
+			return err
+		}
+	}
+}
+
+func runKubesharkTail(query string) {
+	kubernetesProvider, err := getKubernetesProviderForCli()
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := connectToHub(ctx, kubernetesProvider, cancel)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to connect to the hub: %v", errormessage.FormatError(err)))
+		return
+	}
+
+	printer, err := newTailPrinter(os.Stdout, client)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to parse the tail format: %v", err))
+		return
+	}
+
+	go tailEntries(ctx, client, query, printer)
+	utils.WaitForFinish(ctx, cancel)
+}
+
+// startTapTail prints the traffic of a tap session in the terminal, through the proxy the session started.
+func startTapTail(ctx context.Context) {
+	client, err := connect.NewProxyHubClient(config.Config.Hub.PortForward.SrcPort)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to create a hub client for --%s: %v", configStructs.TailTapName, errormessage.FormatError(err)))
+		return
+	}
+
+	printer, err := newTailPrinter(os.Stdout, client)
+	if err != nil {
+		log.Printf(utils.Error, fmt.Sprintf("Failed to parse the tail format: %v", err))
+		return
+	}
+
+	go tailEntries(ctx, client, "", printer)
+}
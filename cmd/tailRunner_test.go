@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+func newTailEntry(id string, status int) *hub.BaseEntry {
+	return &hub.BaseEntry{
+		Id:          id,
+		Protocol:    hub.Protocol{Abbreviation: "HTTP"},
+		Method:      "POST",
+		Summary:     "/orders",
+		Status:      status,
+		Timestamp:   1666166400000,
+		Latency:     12,
+		Source:      &hub.TCP{IP: "10.0.0.1", Port: "43210"},
+		Destination: &hub.TCP{IP: "10.0.0.2", Port: "80", Name: "checkout.shop"},
+	}
+}
+
+func TestNewTailLine(t *testing.T) {
+	line := newTailLine(newTailEntry("1", 201))
+
+	if line.Source != "10.0.0.1:43210" || line.SourceNamespace != "" {
+		t.Errorf("unexpected source of an unresolved endpoint %q, namespace %q", line.Source, line.SourceNamespace)
+	}
+	if line.Destination != "checkout" || line.DestinationNamespace != "shop" {
+		t.Errorf("unexpected destination %q, namespace %q", line.Destination, line.DestinationNamespace)
+	}
+	if line.Latency != 12*time.Millisecond || line.Time.UnixMilli() != 1666166400000 {
+		t.Errorf("unexpected latency %v or time %v", line.Latency, line.Time)
+	}
+}
+
+func TestTailPrinterPrint(t *testing.T) {
+	entry := newTailEntry("1", 201)
+	tests := []struct {
+		name     string
+		format   string
+		expected string
+	}{
+		{
+			name:     "default format",
+			expected: fmt.Sprintf("%s HTTP POST /orders 201 12ms 10.0.0.1:43210 → checkout (shop)\n", time.UnixMilli(entry.Timestamp).Local().Format(entryTimeFormat)),
+		},
+		{
+			name:     "template",
+			format:   "{{.Method}} {{.Path}} {{.Status}} {{.DestinationNamespace}}",
+			expected: "POST /orders 201 shop\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var out bytes.Buffer
+			printer := &tailPrinter{out: &out}
+			if test.format != "" {
+				printer.template = template.Must(template.New("tail").Parse(test.format))
+			}
+
+			if err := printer.print(context.Background(), entry); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != test.expected {
+				t.Errorf("unexpected line - expected: %q, actual: %q", test.expected, out.String())
+			}
+		})
+	}
+}
+
+// syncBuffer is written by the tail while the test reads it.
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (buffer *syncBuffer) Write(data []byte) (int, error) {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.buffer.Write(data)
+}
+
+func (buffer *syncBuffer) String() string {
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	return buffer.buffer.String()
+}
+
+func TestTailEntries(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		if _, query, err := conn.ReadMessage(); err != nil || string(query) != "http" {
+			t.Errorf("unexpected query %q, err: %v", query, err)
+			return
+		}
+		_ = conn.WriteJSON(map[string]interface{}{"messageType": hub.MessageTypeQueryMetadata, "data": map[string]interface{}{"current": 2}})
+		for _, entry := range []*hub.BaseEntry{newTailEntry("1", 201), newTailEntry("2", 500)} {
+			_ = conn.WriteJSON(map[string]interface{}{"messageType": hub.MessageTypeEntry, "data": entry})
+		}
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	client, err := hub.NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := &syncBuffer{}
+	printer := &tailPrinter{out: out, template: template.Must(template.New("tail").Parse("{{.Entry.Id}} {{.Status}}"))}
+	done := make(chan struct{})
+	go func() {
+		tailEntries(ctx, client, "http", printer)
+		close(done)
+	}()
+
+	expected := "1 201\n2 500\n"
+	for deadline := time.Now().Add(5 * time.Second); out.String() != expected && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if out.String() != expected {
+		t.Errorf("unexpected lines - expected: %q, actual: %q", expected, out.String())
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("expected the tail to stop once its context is done")
+	}
+}
+
+func TestTapTailFlagReachesConfig(t *testing.T) {
+	if err := tapCmd.ParseFlags([]string{"--" + configStructs.TailTapName}); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.InitConfig(tapCmd); err != nil {
+		t.Fatal(err)
+	}
+
+	if !config.Config.Tap.Tail {
+		t.Error("expected --tail to enable the tail of the tap")
+	}
+}
@@ -31,6 +31,16 @@ Supported protocols are HTTP and gRPC.`,
 			return errormessage.FormatError(err)
 		}
 
+		if config.Config.Tap.Tail {
+			if err := config.Config.Tail.Validate(); err != nil {
+				return errormessage.FormatError(err)
+			}
+		}
+
+		if cmd.Flags().Changed(configStructs.GuiPortTapName) {
+			config.Config.Front.PortForward.SrcPort = config.Config.Tap.GuiPort
+		}
+
 		log.Printf("Kubeshark will store up to %s of traffic, old traffic will be cleared once the limit is reached.", config.Config.Tap.HumanMaxEntriesDBSize)
 
 		return nil
@@ -56,4 +66,7 @@ func init() {
 	tapCmd.Flags().Bool(configStructs.TlsName, defaultTapConfig.Tls, "Record tls traffic")
 	tapCmd.Flags().Bool(configStructs.ProfilerName, defaultTapConfig.Profiler, "Run pprof server")
 	tapCmd.Flags().Int(configStructs.MaxLiveStreamsName, defaultTapConfig.MaxLiveStreams, "Maximum live tcp streams to handle concurrently")
+	tapCmd.Flags().Bool(configStructs.AttachTapName, defaultTapConfig.Attach, "Attach to a detached session, or to a session whose holder stopped renewing it, instead of deploying Kubeshark")
+	tapCmd.Flags().Bool(configStructs.DetachTapName, defaultTapConfig.Detach, "Leave Kubeshark running in the cluster and exit once it's deployed, remove it later with the clean command")
+	tapCmd.Flags().Bool(configStructs.TailTapName, defaultTapConfig.Tail, "Print the captured traffic in the terminal as it arrives, like the tail command")
 }
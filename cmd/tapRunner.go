@@ -15,7 +15,6 @@ import (
 
 	core "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/kubeshark/kubeshark/cmd/goUtils"
 	"github.com/kubeshark/kubeshark/config"
@@ -28,10 +27,16 @@ import (
 
 const cleanupTimeout = time.Minute
 
+// leaseRenewInterval leaves room for a couple of failed renewals within the shortest reaper grace period.
+const leaseRenewInterval = 15 * time.Second
+
 type tapState struct {
 	startTime                     time.Time
 	targetNamespaces              []string
 	kubesharkServiceAccountExists bool
+	sessionHolder                 *kubernetes.SessionHolder
+	detached                      bool
+	sessionLost                   bool
 }
 
 var state tapState
@@ -43,7 +48,12 @@ var proxyDone bool
 func RunKubesharkTap() {
 	state.startTime = time.Now()
 
-	connector = connect.NewConnector(kubernetes.GetLocalhostOnPort(config.Config.Hub.PortForward.SrcPort), connect.DefaultRetries, connect.DefaultTimeout)
+	if err := resolveLocalPorts(true, fmt.Sprintf("--%s", configStructs.GuiPortTapName)); err != nil {
+		log.Printf(utils.Error, errormessage.FormatError(err))
+		return
+	}
+
+	connector = connect.NewProxyConnector(config.Config.Hub.PortForward.SrcPort, connect.DefaultRetries, connect.DefaultTimeout)
 
 	kubernetesProvider, err := getKubernetesProviderForCli()
 	if err != nil {
@@ -87,17 +97,18 @@ func RunKubesharkTap() {
 		return
 	}
 
-	log.Printf("Waiting for Kubeshark deployment to finish...")
-	if state.kubesharkServiceAccountExists, err = resources.CreateTapKubesharkResources(ctx, kubernetesProvider, serializedKubesharkConfig, config.Config.IsNsRestrictedMode(), config.Config.ResourcesNamespace, config.Config.Tap.MaxEntriesDBSizeBytes(), config.Config.Tap.HubResources, config.Config.ImagePullPolicy(), config.Config.LogLevel(), config.Config.Tap.Profiler); err != nil {
-		var statusError *k8serrors.StatusError
-		if errors.As(err, &statusError) && (statusError.ErrStatus.Reason == metav1.StatusReasonAlreadyExists) {
-			log.Print("Kubeshark is already running in this namespace, change the `kubeshark-resources-namespace` configuration or run `kubeshark clean` to remove the currently running Kubeshark instance")
-		} else {
-			defer resources.CleanUpKubesharkResources(ctx, cancel, kubernetesProvider, config.Config.IsNsRestrictedMode(), config.Config.ResourcesNamespace)
-			log.Printf(utils.Error, fmt.Sprintf("Error creating resources: %v", errormessage.FormatError(err)))
+	state.sessionHolder = resources.NewSessionHolder(kubernetesProvider, config.Config.Tap.Detach)
+	if config.Config.Tap.Attach {
+		if err := attachTap(ctx, kubernetesProvider); err != nil {
+			printSessionError(err)
+			return
+		}
+	} else {
+		log.Printf("Waiting for Kubeshark deployment to finish...")
+		if state.kubesharkServiceAccountExists, err = resources.CreateTapKubesharkResources(ctx, kubernetesProvider, state.sessionHolder, serializedKubesharkConfig, config.Config.IsNsRestrictedMode(), config.Config.ResourcesNamespace, config.Config.Tap.MaxEntriesDBSizeBytes(), config.Config.Tap.HubResources, config.Config.Tap.GetBasenineResources(), config.Config.Tap.FrontResources, config.Config.ImagePullPolicy(), config.Config.LogLevel(), config.Config.Tap.Profiler, false); err != nil {
+			printSessionError(err)
+			return
 		}
-
-		return
 	}
 
 	defer finishTapExecution(kubernetesProvider)
@@ -105,13 +116,98 @@ func RunKubesharkTap() {
 	go goUtils.HandleExcWrapper(watchHubEvents, ctx, kubernetesProvider, cancel)
 	go goUtils.HandleExcWrapper(watchHubPod, ctx, kubernetesProvider, cancel)
 	go goUtils.HandleExcWrapper(watchFrontPod, ctx, kubernetesProvider, cancel)
+	if !config.Config.Tap.Detach {
+		go goUtils.HandleExcWrapper(renewLease, ctx, kubernetesProvider, cancel)
+	}
 
 	// block until exit signal or error
 	utils.WaitForFinish(ctx, cancel)
 }
 
 func finishTapExecution(kubernetesProvider *kubernetes.Provider) {
-	finishKubesharkExecution(kubernetesProvider, config.Config.IsNsRestrictedMode(), config.Config.ResourcesNamespace)
+	if state.detached || state.sessionLost {
+		return
+	}
+
+	// Someone may have taken over the session after this run stopped renewing it, e.g. while the machine slept
+	ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	if isHeld, err := resources.IsSessionHeldBy(ctx, kubernetesProvider, config.Config.ResourcesNamespace, state.sessionHolder); err != nil {
+		log.Printf(utils.Warning, fmt.Sprintf("Failed to check the holder of lease %s: %v", kubernetes.LeaseName, errormessage.FormatError(err)))
+	} else if !isHeld {
+		log.Printf(utils.Warning, "The session was taken over by someone else, leaving it running")
+		return
+	}
+
+	finishKubesharkExecution(kubernetesProvider, config.Config.IsNsRestrictedMode(), config.Config.ResourcesNamespace, resources.CleanUpOptions{KeepData: config.Config.Clean.KeepData})
+}
+
+func attachTap(ctx context.Context, kubernetesProvider *kubernetes.Provider) error {
+	log.Printf("Attaching to the Kubeshark session in namespace %s...", config.Config.ResourcesNamespace)
+	if err := resources.AttachTapSession(ctx, kubernetesProvider, config.Config.IsNsRestrictedMode(), config.Config.ResourcesNamespace, state.sessionHolder); err != nil {
+		return err
+	}
+
+	exists, err := kubernetesProvider.DoesServiceAccountExist(ctx, config.Config.ResourcesNamespace, kubernetes.ServiceAccountName)
+	if err != nil {
+		return err
+	}
+	state.kubesharkServiceAccountExists = exists
+	return nil
+}
+
+func printSessionError(err error) {
+	var sessionHeldErr *resources.SessionHeldError
+	if !errors.As(err, &sessionHeldErr) {
+		log.Printf(utils.Error, fmt.Sprintf("Error creating resources: %v", errormessage.FormatError(err)))
+		return
+	}
+
+	log.Printf(utils.Error, fmt.Sprintf("Kubeshark in namespace %s is held by %s", sessionHeldErr.Namespace, sessionHeldErr.Holder))
+	if sessionHeldErr.Holder.Detached {
+		log.Printf("Take it over with `kubeshark tap --%s`, or open it read-only with `kubeshark view`", configStructs.AttachTapName)
+	} else {
+		log.Printf("Open it read-only with `kubeshark view`, or run another instance side by side with --%s", config.InstanceConfigName)
+	}
+}
+
+func renewLease(ctx context.Context, kubernetesProvider *kubernetes.Provider, cancel context.CancelFunc) {
+	ticker := time.NewTicker(leaseRenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := kubernetesProvider.RenewLease(ctx, config.Config.ResourcesNamespace, kubernetes.LeaseName, state.sessionHolder.Identity())
+			var leaseLostErr *kubernetes.LeaseLostError
+			if errors.As(err, &leaseLostErr) {
+				log.Printf(utils.Error, fmt.Sprintf("Lost lease %s, %v. Exiting without cleaning up", kubernetes.LeaseName, leaseLostErr))
+				state.sessionLost = true
+				cancel()
+				return
+			} else if k8serrors.IsNotFound(err) {
+				// The reaper removes the lease with the rest of the instance, e.g. after the machine slept past the grace period
+				log.Printf(utils.Error, fmt.Sprintf("Lease %s was removed, Kubeshark was removed by the reaper or by `kubeshark clean`. Exiting without cleaning up", kubernetes.LeaseName))
+				state.sessionLost = true
+				cancel()
+				return
+			} else if err != nil {
+				log.Printf(utils.Warning, fmt.Sprintf("Failed to renew lease %s, the reaper removes Kubeshark if it isn't renewed within %s: %v", kubernetes.LeaseName, config.Config.Tap.Reaper.GracePeriod, errormessage.FormatError(err)))
+			}
+		case <-ctx.Done():
+			log.Printf("Renewing lease loop, ctx done")
+			return
+		}
+	}
+}
+
+// detachTap ends the run once the workers are deployed, leaving Kubeshark running in the cluster.
+func detachTap(cancel context.CancelFunc) {
+	state.detached = true
+	proxyDone = true
+	log.Printf("Kubeshark is running detached in namespace %s, open it with `kubeshark view` and remove it with `kubeshark clean`", config.Config.ResourcesNamespace)
+	log.Printf(utils.Warning, "The tapped pods are not updated while detached, matching pods that start later are not tapped")
+	cancel()
 }
 
 func getTapConfig() *models.Config {
@@ -188,6 +284,17 @@ func startTapperSyncer(ctx context.Context, cancel context.CancelFunc, provider
 				if err := connector.ReportTappedPods(tapperSyncer.CurrentlyTappedPods); err != nil {
 					log.Printf("[Error] failed update tapped pods %v", err)
 				}
+			case untappablePods, ok := <-tapperSyncer.UntappablePodsOut:
+				if !ok {
+					log.Print("kubesharkTapperSyncer untappable pods channel closed, ending listener loop")
+					return
+				}
+				for _, untappablePod := range untappablePods {
+					log.Printf(utils.Warning, fmt.Sprintf("Not tappable %s.%s: %s", untappablePod.Namespace, untappablePod.Name, untappablePod.Reason))
+				}
+				if err := connector.ReportUntappablePods(untappablePods); err != nil {
+					log.Printf("[Error] failed update untappable pods %v", err)
+				}
 			case tapperStatus, ok := <-tapperSyncer.TapperStatusChangedOut:
 				if !ok {
 					log.Print("kubesharkTapperSyncer tapper status changed channel closed, ending listener loop")
@@ -246,10 +353,8 @@ func watchHubPod(ctx context.Context, kubernetesProvider *kubernetes.Provider, c
 			switch wEvent.Type {
 			case kubernetes.EventAdded:
 				log.Printf("Watching Hub pod loop, added")
-			case kubernetes.EventDeleted:
-				log.Printf("%s removed", kubernetes.HubPodName)
-				cancel()
-				return
+				// An attached run finds the pod already running
+				fallthrough
 			case kubernetes.EventModified:
 				modifiedPod, err := wEvent.ToPod()
 				if err != nil {
@@ -270,6 +375,10 @@ func watchHubPod(ctx context.Context, kubernetesProvider *kubernetes.Provider, c
 					proxyDone = true
 					postFrontStarted(ctx, kubernetesProvider, cancel)
 				}
+			case kubernetes.EventDeleted:
+				log.Printf("%s removed", kubernetes.HubPodName)
+				cancel()
+				return
 			case kubernetes.EventBookmark:
 				break
 			case kubernetes.EventError:
@@ -315,10 +424,8 @@ func watchFrontPod(ctx context.Context, kubernetesProvider *kubernetes.Provider,
 			switch wEvent.Type {
 			case kubernetes.EventAdded:
 				log.Printf("Watching Hub pod loop, added")
-			case kubernetes.EventDeleted:
-				log.Printf("%s removed", kubernetes.FrontPodName)
-				cancel()
-				return
+				// An attached run finds the pod already running
+				fallthrough
 			case kubernetes.EventModified:
 				modifiedPod, err := wEvent.ToPod()
 				if err != nil {
@@ -338,6 +445,10 @@ func watchFrontPod(ctx context.Context, kubernetesProvider *kubernetes.Provider,
 					proxyDone = true
 					postFrontStarted(ctx, kubernetesProvider, cancel)
 				}
+			case kubernetes.EventDeleted:
+				log.Printf("%s removed", kubernetes.FrontPodName)
+				cancel()
+				return
 			case kubernetes.EventBookmark:
 				break
 			case kubernetes.EventError:
@@ -365,8 +476,7 @@ func watchFrontPod(ctx context.Context, kubernetesProvider *kubernetes.Provider,
 }
 
 func watchHubEvents(ctx context.Context, kubernetesProvider *kubernetes.Provider, cancel context.CancelFunc) {
-	podExactRegex := regexp.MustCompile(fmt.Sprintf("^%s", kubernetes.HubPodName))
-	eventWatchHelper := kubernetes.NewEventWatchHelper(kubernetesProvider, podExactRegex, "pod")
+	eventWatchHelper := kubernetes.NewEventWatchHelper(kubernetesProvider, kubernetes.HubEventRegex(), "pod")
 	eventChan, errorChan := kubernetes.FilteredWatch(ctx, eventWatchHelper, []string{config.Config.ResourcesNamespace}, eventWatchHelper)
 	for {
 		select {
@@ -417,22 +527,48 @@ func watchHubEvents(ctx context.Context, kubernetesProvider *kubernetes.Provider
 }
 
 func postHubStarted(ctx context.Context, kubernetesProvider *kubernetes.Provider, cancel context.CancelFunc) {
-	startProxyReportErrorIfAny(kubernetesProvider, ctx, cancel, kubernetes.HubServiceName, config.Config.Hub.PortForward.SrcPort, config.Config.Hub.PortForward.DstPort, "/echo")
+	startProxyReportErrorIfAny(kubernetesProvider, ctx, cancel, kubernetes.HubServiceName, kubernetes.HubPodName, config.Config.Hub.PortForward.SrcPort, config.Config.Hub.PortForward.DstPort, "/echo", getSetHint(config.HubSrcPortConfigName))
 
 	if err := startTapperSyncer(ctx, cancel, kubernetesProvider, state.targetNamespaces, state.startTime); err != nil {
 		log.Printf(utils.Error, fmt.Sprintf("Error starting kubeshark tapper syncer: %v", errormessage.FormatError(err)))
 		cancel()
+		return
+	}
+
+	if config.Config.Tap.Detach {
+		detachTap(cancel)
+		return
 	}
 
-	url := kubernetes.GetLocalhostOnPort(config.Config.Hub.PortForward.SrcPort)
+	url := connect.GetProxyBrowserUrl(config.Config.Hub.PortForward.SrcPort)
+	// The CLI keeps talking to the hub through the proxy, the exposed address is for the rest of the team
+	if config.Config.Hub.Expose.IsExposed() {
+		if exposedUrl := getExposedUrl(ctx, kubernetesProvider, kubernetes.HubServiceName, kubernetes.HubIngressName); exposedUrl != "" {
+			url = exposedUrl
+		}
+	}
 	log.Printf("Hub is available at %s", url)
+
+	if config.Config.Tap.Tail {
+		startTapTail(ctx)
+	}
 }
 
 func postFrontStarted(ctx context.Context, kubernetesProvider *kubernetes.Provider, cancel context.CancelFunc) {
-	startProxyReportErrorIfAny(kubernetesProvider, ctx, cancel, kubernetes.FrontServiceName, config.Config.Front.PortForward.SrcPort, config.Config.Front.PortForward.DstPort, "")
+	var url string
+	if config.Config.Front.Expose.IsExposed() {
+		url = getExposedUrl(ctx, kubernetesProvider, kubernetes.FrontServiceName, kubernetes.FrontIngressName)
+	}
+
+	if url == "" {
+		startProxyReportErrorIfAny(kubernetesProvider, ctx, cancel, kubernetes.FrontServiceName, kubernetes.FrontPodName, config.Config.Front.PortForward.SrcPort, config.Config.Front.PortForward.DstPort, "", fmt.Sprintf("--%s", configStructs.GuiPortTapName))
+		url = connect.GetProxyBrowserUrl(config.Config.Front.PortForward.SrcPort)
+	}
 
-	url := kubernetes.GetLocalhostOnPort(config.Config.Front.PortForward.SrcPort)
 	log.Printf("Kubeshark is available at %s", url)
+	if config.Config.Auth.Enabled {
+		printAuthLoginHint(url)
+	}
 	if !config.Config.HeadlessMode {
 		utils.OpenBrowser(url)
 	}
@@ -4,6 +4,7 @@ import (
 	"log"
 
 	"github.com/creasty/defaults"
+	"github.com/kubeshark/kubeshark/config"
 	"github.com/kubeshark/kubeshark/config/configStructs"
 	"github.com/spf13/cobra"
 )
@@ -12,6 +13,9 @@ var viewCmd = &cobra.Command{
 	Use:   "view",
 	Short: "Open GUI in browser",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if cmd.Flags().Changed(configStructs.GuiPortViewName) {
+			config.Config.Front.PortForward.SrcPort = config.Config.View.GuiPort
+		}
 		runKubesharkView()
 		return nil
 	},
@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/errormessage"
 	"github.com/kubeshark/kubeshark/internal/connect"
 	"github.com/kubeshark/kubeshark/utils"
 
@@ -37,18 +39,33 @@ func runKubesharkView() {
 			return
 		}
 
-		url = kubernetes.GetLocalhostOnPort(config.Config.Front.PortForward.SrcPort)
+		url = getExposedUrl(ctx, kubernetesProvider, kubernetes.FrontServiceName, kubernetes.FrontIngressName)
+	}
 
-		response, err := http.Get(fmt.Sprintf("%s/", url))
-		if err == nil && response.StatusCode == 200 {
-			log.Printf("Found a running service %s and open port %d", kubernetes.HubServiceName, config.Config.Front.PortForward.SrcPort)
+	var connector *connect.Connector
+	if url == "" {
+		// The proxy of a running tap only opens with the token that tap printed
+		response, err := http.Get(fmt.Sprintf("%s/", connect.GetProxyUrl(config.Config.Front.PortForward.SrcPort)))
+		if err == nil {
+			response.Body.Close()
+		}
+		if err == nil && response.StatusCode == http.StatusUnauthorized {
+			log.Printf("Found a running proxy of service %s on port %d, open the address its run printed", kubernetes.FrontServiceName, config.Config.Front.PortForward.SrcPort)
+			return
+		}
+		if err := resolveLocalPorts(false, fmt.Sprintf("--%s", configStructs.GuiPortViewName)); err != nil {
+			log.Printf(utils.Error, errormessage.FormatError(err))
 			return
 		}
+		url = connect.GetProxyBrowserUrl(config.Config.Front.PortForward.SrcPort)
+
 		log.Printf("Establishing connection to k8s cluster...")
-		startProxyReportErrorIfAny(kubernetesProvider, ctx, cancel, kubernetes.FrontServiceName, config.Config.Front.PortForward.SrcPort, config.Config.Front.PortForward.DstPort, "")
+		startProxyReportErrorIfAny(kubernetesProvider, ctx, cancel, kubernetes.FrontServiceName, kubernetes.FrontPodName, config.Config.Front.PortForward.SrcPort, config.Config.Front.PortForward.DstPort, "", fmt.Sprintf("--%s", configStructs.GuiPortViewName))
+		connector = connect.NewProxyConnector(config.Config.Front.PortForward.SrcPort, connect.DefaultRetries, connect.DefaultTimeout)
+	} else {
+		connector = connect.NewConnector(url, connect.DefaultRetries, connect.DefaultTimeout)
 	}
 
-	connector := connect.NewConnector(url, connect.DefaultRetries, connect.DefaultTimeout)
 	if err := connector.TestConnection(""); err != nil {
 		log.Printf(utils.Error, "Couldn't connect to Hub.")
 		return
@@ -56,6 +73,10 @@ func runKubesharkView() {
 
 	log.Printf("Kubeshark is available at %s", url)
 
+	if authEnabled, err := kubernetesProvider.DoesSecretExist(ctx, config.Config.ResourcesNamespace, kubernetes.AuthSecretName); err == nil && authEnabled {
+		printAuthLoginHint(url)
+	}
+
 	if !config.Config.HeadlessMode {
 		utils.OpenBrowser(url)
 	}
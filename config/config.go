@@ -24,26 +24,32 @@ const (
 	ReadonlyTag    = "readonly"
 )
 
+// ConfigKeyAnnotation names the config key of the flags of a command, when it isn't the command path.
+const ConfigKeyAnnotation = "kubeshark/config-key"
+
 var (
 	Config  = ConfigStruct{}
-	cmdName string
+	cmdPath []string
+	// setKeys are the keys set by the config file, --set or a flag, rather than left to their defaults
+	setKeys = map[string]bool{}
 )
 
 func InitConfig(cmd *cobra.Command) error {
 	Config.Hub = HubConfig{
-		PortForward{
-			8898,
-			80,
+		PortForward: PortForward{
+			SrcPort: 8898,
+			DstPort: 80,
 		},
 	}
 
 	Config.Front = FrontConfig{
-		PortForward{
-			8899,
-			80,
+		PortForward: PortForward{
+			SrcPort: 8899,
+			DstPort: 80,
 		},
 	}
-	cmdName = cmd.Name()
+	cmdPath = getCommandPath(cmd)
+	setKeys = map[string]bool{}
 
 	if err := defaults.Set(&Config); err != nil {
 		return err
@@ -70,6 +76,43 @@ func InitConfig(cmd *cobra.Command) error {
 	return nil
 }
 
+// getCommandPath returns the names of a command and of its parents below the root command, the flags of a
+// subcommand are in the config of its parent, e.g. the flags of snapshot save are in snapshot.save. A command
+// annotated with ConfigKeyAnnotation has its flags under that key instead.
+func getCommandPath(cmd *cobra.Command) []string {
+	if key, ok := cmd.Annotations[ConfigKeyAnnotation]; ok {
+		return []string{key}
+	}
+
+	var path []string
+	for ; cmd.HasParent(); cmd = cmd.Parent() {
+		path = append([]string{cmd.Name()}, path...)
+	}
+	if len(path) == 0 {
+		path = []string{cmd.Name()}
+	}
+	return path
+}
+
+// IsSet reports whether a config key, e.g. front.port-forward.src-port, was set by the config file, --set or
+// a flag, rather than left to its default.
+func IsSet(key string) bool {
+	return setKeys[key]
+}
+
+// recordSetKeys records the keys of the leaves of a config file.
+func recordSetKeys(prefix []string, value interface{}) {
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		setKeys[strings.Join(prefix, ".")] = true
+		return
+	}
+
+	for key, value := range values {
+		recordSetKeys(append(append([]string{}, prefix...), key), value)
+	}
+}
+
 func GetConfigWithDefaults() (*ConfigStruct, error) {
 	defaultConf := ConfigStruct{}
 	if err := defaults.Set(&defaultConf); err != nil {
@@ -111,6 +154,11 @@ func loadConfigFile(configFilePath string, config *ConfigStruct) error {
 		return err
 	}
 
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(buf, &values); err == nil {
+		recordSetKeys(nil, values)
+	}
+
 	log.Printf("Found config file, config path: %s", configFilePath)
 
 	return nil
@@ -120,10 +168,13 @@ func initFlag(f *pflag.Flag) {
 	configElemValue := reflect.ValueOf(&Config).Elem()
 
 	var flagPath []string
-	if utils.Contains([]string{ConfigFilePathCommandName}, f.Name) {
+	if utils.Contains([]string{ConfigFilePathCommandName, InstanceConfigName}, f.Name) {
 		flagPath = []string{f.Name}
 	} else {
-		flagPath = []string{cmdName, f.Name}
+		flagPath = append(append([]string{}, cmdPath...), f.Name)
+	}
+	if f.Name != SetCommandName {
+		setKeys[strings.Join(flagPath, ".")] = true
 	}
 
 	sliceValue, isSliceValue := f.Value.(pflag.SliceValue)
@@ -160,6 +211,7 @@ func mergeSetFlag(configElemValue reflect.Value, setValues []string) error {
 		argumentKey, argumentValue := split[0], split[1]
 
 		setMap[argumentKey] = append(setMap[argumentKey], argumentValue)
+		setKeys[argumentKey] = true
 	}
 
 	for argumentKey, argumentValues := range setMap {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"regexp"
 
 	"github.com/kubeshark/kubeshark/config/configStructs"
 	"github.com/kubeshark/kubeshark/kubeshark"
@@ -16,37 +17,95 @@ import (
 
 const (
 	ResourcesNamespaceConfigName = "resources-namespace"
+	InstanceConfigName           = "instance"
 	ConfigFilePathCommandName    = "config-path"
 	KubeConfigPathConfigName     = "kube-config-path"
+	ConnectionConfigName         = "connection"
+	HubSrcPortConfigName         = "hub.port-forward.src-port"
+	FrontSrcPortConfigName       = "front.port-forward.src-port"
 )
 
+// The instance name is part of the object names, which are limited to 63 characters
+const maxInstanceNameLength = 20
+
+var instanceRegex = regexp.MustCompile(fmt.Sprintf(`^[a-z0-9]([-a-z0-9]{0,%d}[a-z0-9])?$`, maxInstanceNameLength-2))
+
 type PortForward struct {
 	SrcPort uint16 `yaml:"src-port"`
 	DstPort uint16 `yaml:"dst-port"`
 }
 
+const (
+	ExposeTypeNodePort     = "NodePort"
+	ExposeTypeLoadBalancer = "LoadBalancer"
+	ExposeTypeIngress      = "Ingress"
+)
+
+type IngressConfig struct {
+	Host          string            `yaml:"host"`
+	TlsSecretName string            `yaml:"tls-secret"`
+	ClassName     string            `yaml:"class-name"`
+	Annotations   map[string]string `yaml:"annotations"`
+}
+
+// ExposeConfig makes a service reachable from outside the cluster, without a proxy running on the CLI host.
+// An empty type keeps the service internal to the cluster.
+type ExposeConfig struct {
+	Type     string        `yaml:"type"`
+	NodePort int32         `yaml:"node-port"`
+	Ingress  IngressConfig `yaml:"ingress"`
+}
+
+func (config *ExposeConfig) IsExposed() bool {
+	return config.Type != ""
+}
+
+func (config *ExposeConfig) validate(name string) error {
+	switch config.Type {
+	case "", ExposeTypeNodePort, ExposeTypeLoadBalancer:
+	case ExposeTypeIngress:
+		if config.Ingress.Host == "" {
+			return fmt.Errorf("%s.expose.ingress.host is required when exposing through an %s", name, ExposeTypeIngress)
+		}
+	default:
+		return fmt.Errorf("%s is not a valid %s.expose.type, use one of %s, %s or %s", config.Type, name, ExposeTypeNodePort, ExposeTypeLoadBalancer, ExposeTypeIngress)
+	}
+
+	return nil
+}
+
+// The ways the CLI connects to the hub and front, auto uses the API server service proxy and falls back to
+// port-forwarding to the pod when the proxy doesn't pass the health check.
+const (
+	ConnectionAuto        = "auto"
+	ConnectionProxy       = "proxy"
+	ConnectionPortForward = "port-forward"
+)
+
 type HubConfig struct {
-	PortForward PortForward `yaml:"port-forward"`
+	PortForward PortForward  `yaml:"port-forward"`
+	Expose      ExposeConfig `yaml:"expose"`
 }
 
 type FrontConfig struct {
-	PortForward PortForward `yaml:"port-forward"`
+	PortForward PortForward  `yaml:"port-forward"`
+	Expose      ExposeConfig `yaml:"expose"`
 }
 
 func CreateDefaultConfig() ConfigStruct {
 	config := ConfigStruct{}
 
 	config.Hub = HubConfig{
-		PortForward{
-			8898,
-			80,
+		PortForward: PortForward{
+			SrcPort: 8898,
+			DstPort: 80,
 		},
 	}
 
 	config.Front = FrontConfig{
-		PortForward{
-			8899,
-			80,
+		PortForward: PortForward{
+			SrcPort: 8899,
+			DstPort: 80,
 		},
 	}
 
@@ -54,24 +113,38 @@ func CreateDefaultConfig() ConfigStruct {
 }
 
 type ConfigStruct struct {
-	Hub                HubConfig                   `yaml:"hub"`
-	Front              FrontConfig                 `yaml:"front"`
-	Tap                configStructs.TapConfig     `yaml:"tap"`
-	Check              configStructs.CheckConfig   `yaml:"check"`
-	Version            configStructs.VersionConfig `yaml:"version"`
-	View               configStructs.ViewConfig    `yaml:"view"`
-	Logs               configStructs.LogsConfig    `yaml:"logs"`
-	Config             configStructs.ConfigConfig  `yaml:"config,omitempty"`
-	ImagePullPolicyStr string                      `yaml:"image-pull-policy" default:"Always"`
-	ResourcesNamespace string                      `yaml:"resources-namespace" default:"kubeshark"`
-	DumpLogs           bool                        `yaml:"dump-logs" default:"false"`
-	KubeConfigPathStr  string                      `yaml:"kube-config-path"`
-	KubeContext        string                      `yaml:"kube-context"`
-	ConfigFilePath     string                      `yaml:"config-path,omitempty" readonly:""`
-	HeadlessMode       bool                        `yaml:"headless" default:"false"`
-	LogLevelStr        string                      `yaml:"log-level,omitempty" default:"INFO" readonly:""`
-	ServiceMap         bool                        `yaml:"service-map" default:"true"`
-	OAS                models.OASConfig            `yaml:"oas"`
+	Hub                HubConfig                            `yaml:"hub"`
+	Auth               configStructs.AuthConfig             `yaml:"auth"`
+	Front              FrontConfig                          `yaml:"front"`
+	Tap                configStructs.TapConfig              `yaml:"tap"`
+	Check              configStructs.CheckConfig            `yaml:"check"`
+	Clean              configStructs.CleanConfig            `yaml:"clean"`
+	Version            configStructs.VersionConfig          `yaml:"version"`
+	View               configStructs.ViewConfig             `yaml:"view"`
+	Logs               configStructs.LogsConfig             `yaml:"logs"`
+	Proxy              configStructs.ProxyConfig            `yaml:"proxy"`
+	Query              configStructs.QueryConfig            `yaml:"query"`
+	Tail               configStructs.TailConfig             `yaml:"tail"`
+	Export             configStructs.ExportConfig           `yaml:"export"`
+	Import             configStructs.ImportConfig           `yaml:"import"`
+	Snapshot           configStructs.SnapshotConfig         `yaml:"snapshot"`
+	OASExport          configStructs.OASExportConfig        `yaml:"oas-export"`
+	OASDiff            configStructs.OASDiffConfig          `yaml:"oas-diff"`
+	ServiceMapExport   configStructs.ServiceMapExportConfig `yaml:"service-map-export"`
+	Replay             configStructs.ReplayConfig           `yaml:"replay"`
+	Config             configStructs.ConfigConfig           `yaml:"config,omitempty"`
+	ImagePullPolicyStr string                               `yaml:"image-pull-policy" default:"Always"`
+	ResourcesNamespace string                               `yaml:"resources-namespace" default:"kubeshark"`
+	Instance           string                               `yaml:"instance" default:"default"`
+	DumpLogs           bool                                 `yaml:"dump-logs" default:"false"`
+	KubeConfigPathStr  string                               `yaml:"kube-config-path"`
+	KubeContext        string                               `yaml:"kube-context"`
+	Connection         string                               `yaml:"connection" default:"auto"`
+	ConfigFilePath     string                               `yaml:"config-path,omitempty" readonly:""`
+	HeadlessMode       bool                                 `yaml:"headless" default:"false"`
+	LogLevelStr        string                               `yaml:"log-level,omitempty" default:"INFO" readonly:""`
+	ServiceMap         bool                                 `yaml:"service-map" default:"true"`
+	OAS                models.OASConfig                     `yaml:"oas"`
 }
 
 func (config *ConfigStruct) validate() error {
@@ -79,6 +152,28 @@ func (config *ConfigStruct) validate() error {
 		return fmt.Errorf("%s is not a valid log level, err: %v", config.LogLevelStr, err)
 	}
 
+	if !instanceRegex.MatchString(config.Instance) {
+		return fmt.Errorf("%s is not a valid instance name, it must consist of up to %d lower case alphanumeric characters or '-', and start and end with an alphanumeric character", config.Instance, maxInstanceNameLength)
+	}
+
+	switch config.Connection {
+	case ConnectionAuto, ConnectionProxy, ConnectionPortForward:
+	default:
+		return fmt.Errorf("%s is not a valid connection, use one of %s, %s or %s", config.Connection, ConnectionAuto, ConnectionProxy, ConnectionPortForward)
+	}
+
+	if err := config.Proxy.Validate(config.Tap.ProxyHost); err != nil {
+		return err
+	}
+
+	if err := config.Hub.Expose.validate("hub"); err != nil {
+		return err
+	}
+
+	if err := config.Front.Expose.validate("front"); err != nil {
+		return err
+	}
+
 	return nil
 }
 
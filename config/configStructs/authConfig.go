@@ -0,0 +1,10 @@
+package configStructs
+
+import "github.com/kubeshark/worker/models"
+
+type AuthConfig struct {
+	Enabled     bool             `yaml:"enabled" default:"false"`
+	KratosImage string           `yaml:"kratos-image" default:"kubeshark/kratos:latest"`
+	KetoImage   string           `yaml:"keto-image" default:"kubeshark/keto:latest"`
+	Resources   models.Resources `yaml:"resources"`
+}
@@ -0,0 +1,17 @@
+package configStructs
+
+const (
+	KeepDataCleanName      = "keep-data"
+	DryRunCleanName        = "dry-run"
+	AllNamespacesCleanName = "all-namespaces"
+	AllInstancesCleanName  = "all-instances"
+	ForceCleanName         = "force"
+)
+
+type CleanConfig struct {
+	KeepData      bool `yaml:"keep-data" default:"false"`
+	DryRun        bool `yaml:"dry-run" default:"false"`
+	AllNamespaces bool `yaml:"all-namespaces" default:"false"`
+	AllInstances  bool `yaml:"all-instances" default:"false"`
+	Force         bool `yaml:"force" default:"false"`
+}
@@ -0,0 +1,75 @@
+package configStructs
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/kubeshark/kubeshark/pkg/export"
+	"github.com/kubeshark/kubeshark/utils"
+)
+
+const (
+	FilterExportName = "filter"
+	FormatExportName = "format"
+	OutputExportName = "output"
+	TargetExportName = "target"
+	AuthExportName   = "auth"
+	TimingExportName = "timing"
+)
+
+// ExportStdout is the output that writes the export to the standard output.
+const ExportStdout = "-"
+
+type ExportConfig struct {
+	Filter string `yaml:"filter"`
+	Format string `yaml:"format" default:"har"`
+	Output string `yaml:"output"`
+	// Targets rewrite the hosts of the requests scripts replay, as host=url
+	Targets []string `yaml:"target"`
+	Auth    string   `yaml:"auth" default:"template"`
+	Timing  bool     `yaml:"timing" default:"true"`
+}
+
+func (config *ExportConfig) Validate() error {
+	if !utils.Contains(export.Formats, config.Format) {
+		return fmt.Errorf("%s is not a valid --%s, use one of %v", config.Format, FormatExportName, export.Formats)
+	}
+	if !utils.Contains(export.AuthModes, config.Auth) {
+		return fmt.Errorf("%s is not a valid --%s, use one of %v", config.Auth, AuthExportName, export.AuthModes)
+	}
+	if _, err := config.GetTargets(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetTargets returns the base url of every target host, a host of * matches any host.
+func (config *ExportConfig) GetTargets() (map[string]string, error) {
+	targets := map[string]string{}
+	for _, target := range config.Targets {
+		split := strings.SplitN(target, "=", 2)
+		if len(split) != 2 || split[0] == "" {
+			return nil, fmt.Errorf("invalid --%s %s, use host=url", TargetExportName, target)
+		}
+
+		targetUrl, err := url.Parse(split[1])
+		if err != nil || !targetUrl.IsAbs() || targetUrl.Host == "" {
+			return nil, fmt.Errorf("invalid --%s %s, the url must be absolute, e.g. http://localhost:8080", TargetExportName, target)
+		}
+		targets[split[0]] = split[1]
+	}
+	return targets, nil
+}
+
+// FilePath returns where the export is written, kubeshark_export.<format> in the working directory by default.
+func (config *ExportConfig) FilePath() string {
+	if config.Output == "" {
+		pwd, _ := os.Getwd()
+		return path.Join(pwd, fmt.Sprintf("kubeshark_export%s", export.FileSuffix(config.Format)))
+	}
+
+	return config.Output
+}
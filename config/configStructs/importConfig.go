@@ -0,0 +1,15 @@
+package configStructs
+
+const (
+	NamespaceImportName = "namespace"
+)
+
+type ImportConfig struct {
+	// Namespace deploys a hub without workers in it for the imported traffic, the traffic is imported into the
+	// running hub when it's empty
+	Namespace string `yaml:"namespace"`
+}
+
+func (config *ImportConfig) IsNewHub() bool {
+	return config.Namespace != ""
+}
@@ -0,0 +1,49 @@
+package configStructs
+
+import (
+	"os"
+	"path"
+
+	"github.com/kubeshark/kubeshark/pkg/oas"
+)
+
+const (
+	ServiceOasName = "service"
+	OutputOasName  = "output"
+)
+
+// The config keys of the oas subcommands, the oas key is the config the hub infers the specs with.
+const (
+	OasExportConfigKey = "oas-export"
+	OasDiffConfigKey   = "oas-diff"
+)
+
+// OasStdout is the output that writes the spec to the standard output.
+const OasStdout = "-"
+
+type OASExportConfig struct {
+	// Service is the service whose spec is exported, the specs of all the services are exported when it's empty
+	Service string `yaml:"service"`
+	Output  string `yaml:"output"`
+}
+
+// FilePath returns where the spec of a service is written. The spec of the service is written to the output, or
+// to <service>.openapi.yaml in the working directory by default. The specs of all the services are written to
+// <service>.openapi.yaml files in the output directory, the working directory by default.
+func (config *OASExportConfig) FilePath(service string) string {
+	if config.Service != "" && config.Output != "" {
+		return config.Output
+	}
+
+	directory := config.Output
+	if directory == "" {
+		directory, _ = os.Getwd()
+	}
+	return path.Join(directory, oas.GetFileName(service))
+}
+
+type OASDiffConfig struct {
+	// Service is the service whose spec is compared, it's picked by the servers of the documented spec when it's
+	// empty
+	Service string `yaml:"service"`
+}
@@ -0,0 +1,46 @@
+package configStructs
+
+import (
+	"fmt"
+	"net"
+)
+
+type ProxyBasicAuthConfig struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// ProxyConfig secures the local proxies of the hub and front. Only their own origins may read from them, and
+// requests need the token of the run, which is part of the printed address, or the basic auth credentials.
+type ProxyConfig struct {
+	Token          bool                 `yaml:"token" default:"true"`
+	Tls            bool                 `yaml:"tls" default:"false"`
+	AllowedOrigins []string             `yaml:"allowed-origins"`
+	BasicAuth      ProxyBasicAuthConfig `yaml:"basic-auth"`
+}
+
+func (config *ProxyConfig) IsBasicAuthEnabled() bool {
+	return config.BasicAuth.Username != ""
+}
+
+// Validate requires basic auth when the proxy is shared on the network, anyone who can reach it can read
+// the captured traffic otherwise.
+func (config *ProxyConfig) Validate(proxyHost string) error {
+	if config.IsBasicAuthEnabled() && config.BasicAuth.Password == "" {
+		return fmt.Errorf("proxy.basic-auth.password is required when proxy.basic-auth.username is set")
+	}
+
+	if !IsLoopbackHost(proxyHost) && !config.IsBasicAuthEnabled() {
+		return fmt.Errorf("binding the proxy to %s shares it on the network, set proxy.basic-auth.username and proxy.basic-auth.password", proxyHost)
+	}
+
+	return nil
+}
+
+func IsLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
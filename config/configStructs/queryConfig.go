@@ -0,0 +1,55 @@
+package configStructs
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	SinceQueryName  = "since"
+	LimitQueryName  = "limit"
+	OutputQueryName = "output"
+	ExpandQueryName = "expand"
+)
+
+// The formats entries are printed in, json prints an entry per line so the output can be piped.
+const (
+	OutputTable = "table"
+	OutputJson  = "json"
+	OutputYaml  = "yaml"
+)
+
+type QueryConfig struct {
+	Since  string `yaml:"since"`
+	Limit  int    `yaml:"limit" default:"100"`
+	Output string `yaml:"output" default:"table"`
+	Expand bool   `yaml:"expand" default:"false"`
+}
+
+func (config *QueryConfig) GetSince() time.Duration {
+	since, _ := time.ParseDuration(config.Since)
+	return since
+}
+
+func (config *QueryConfig) Validate() error {
+	if config.Since != "" {
+		if since, err := time.ParseDuration(config.Since); err != nil || since <= 0 {
+			return fmt.Errorf("%s is not a valid --%s, use a duration like 10m", config.Since, SinceQueryName)
+		}
+	}
+
+	if config.Limit <= 0 {
+		return fmt.Errorf("--%s must be positive", LimitQueryName)
+	}
+
+	return validateOutput(config.Output)
+}
+
+func validateOutput(output string) error {
+	switch output {
+	case OutputTable, OutputJson, OutputYaml:
+		return nil
+	default:
+		return fmt.Errorf("%s is not a valid output, use one of %s, %s or %s", output, OutputTable, OutputJson, OutputYaml)
+	}
+}
@@ -0,0 +1,127 @@
+package configStructs
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/kubeshark/kubeshark/pkg/replay"
+)
+
+const (
+	FilterReplayName = "filter"
+	TargetReplayName = "target"
+	RateReplayName   = "rate"
+	HeaderReplayName = "header"
+	LimitReplayName  = "limit"
+)
+
+// The kinds of targets requests are replayed to.
+const (
+	ReplayTargetService = "svc"
+	ReplayTargetPod     = "pod"
+	ReplayTargetUrl     = "url"
+)
+
+// ReplayTarget is a service or a pod of the cluster, or a url.
+type ReplayTarget struct {
+	Kind string
+	Name string
+	// Namespace and Port are empty when the target doesn't name them
+	Namespace string
+	Port      int32
+	Url       string
+}
+
+type ReplayConfig struct {
+	Filter string `yaml:"filter"`
+	// Target is where requests are replayed to, the service or pod they were captured at when it's empty
+	Target string   `yaml:"target"`
+	Rate   int      `yaml:"rate" default:"10"`
+	Header []string `yaml:"header"`
+	Limit  int      `yaml:"limit" default:"100"`
+}
+
+func (config *ReplayConfig) Validate() error {
+	if config.Filter == "" {
+		return fmt.Errorf("--%s is required, select the replayed requests with a KFL filter", FilterReplayName)
+	}
+	if config.Rate <= 0 {
+		return fmt.Errorf("%d is not a valid --%s, use a positive number of requests per second", config.Rate, RateReplayName)
+	}
+	if config.Limit < 0 {
+		return fmt.Errorf("%d is not a valid --%s, use 0 to replay all the selected requests", config.Limit, LimitReplayName)
+	}
+	if _, err := config.GetHeaders(); err != nil {
+		return err
+	}
+	if _, err := config.GetTarget(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (config *ReplayConfig) GetHeaders() ([]replay.Header, error) {
+	var headers []replay.Header
+	for _, value := range config.Header {
+		header, err := replay.ParseHeader(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s, %w", HeaderReplayName, err)
+		}
+		headers = append(headers, header)
+	}
+	return headers, nil
+}
+
+// GetTarget parses the target, svc/<name>[.<namespace>][:<port>], pod/<name>[.<namespace>][:<port>], a url, or
+// <host>:<port> for a plain HTTP url. It returns nil when there's no target.
+func (config *ReplayConfig) GetTarget() (*ReplayTarget, error) {
+	if config.Target == "" {
+		return nil, nil
+	}
+
+	if !strings.Contains(config.Target, "/") {
+		return getReplayUrlTarget(config.Target, "http://"+config.Target)
+	}
+	if strings.HasPrefix(config.Target, "http://") || strings.HasPrefix(config.Target, "https://") {
+		return getReplayUrlTarget(config.Target, config.Target)
+	}
+
+	split := strings.SplitN(config.Target, "/", 2)
+	target := &ReplayTarget{}
+	switch split[0] {
+	case "svc", "service":
+		target.Kind = ReplayTargetService
+	case "pod":
+		target.Kind = ReplayTargetPod
+	default:
+		return nil, fmt.Errorf("invalid --%s %s, use svc/<name>[.<namespace>][:<port>], pod/<name>[.<namespace>][:<port>] or a url", TargetReplayName, config.Target)
+	}
+
+	name := split[1]
+	if nameAndPort := strings.SplitN(name, ":", 2); len(nameAndPort) == 2 {
+		port, err := strconv.ParseInt(nameAndPort[1], 10, 32)
+		if err != nil || port <= 0 {
+			return nil, fmt.Errorf("invalid --%s %s, the port must be a number", TargetReplayName, config.Target)
+		}
+		name, target.Port = nameAndPort[0], int32(port)
+	}
+	if nameAndNamespace := strings.SplitN(name, ".", 2); len(nameAndNamespace) == 2 {
+		name, target.Namespace = nameAndNamespace[0], nameAndNamespace[1]
+	}
+	if name == "" {
+		return nil, fmt.Errorf("invalid --%s %s, the name is missing", TargetReplayName, config.Target)
+	}
+	target.Name = name
+
+	return target, nil
+}
+
+func getReplayUrlTarget(value string, rawUrl string) (*ReplayTarget, error) {
+	targetUrl, err := url.Parse(rawUrl)
+	if err != nil || targetUrl.Host == "" {
+		return nil, fmt.Errorf("invalid --%s %s, the url must be absolute, e.g. http://localhost:8080", TargetReplayName, value)
+	}
+	return &ReplayTarget{Kind: ReplayTargetUrl, Url: rawUrl}, nil
+}
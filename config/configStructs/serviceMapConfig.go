@@ -0,0 +1,46 @@
+package configStructs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubeshark/kubeshark/pkg/servicemap"
+	"github.com/kubeshark/kubeshark/utils"
+)
+
+const (
+	FormatServiceMapName = "format"
+	OutputServiceMapName = "output"
+	SinceServiceMapName  = "since"
+)
+
+// ServiceMapExportConfigKey is the config key of servicemap export, the service-map key enables the service map
+// of the hub.
+const ServiceMapExportConfigKey = "service-map-export"
+
+type ServiceMapExportConfig struct {
+	Format string `yaml:"format" default:"dot"`
+	// Output is the file the graph is written to, the standard output when it's empty
+	Output string `yaml:"output"`
+	// Since limits the entries the error rates and latencies of the edges are computed from
+	Since string `yaml:"since"`
+}
+
+func (config *ServiceMapExportConfig) GetSince() time.Duration {
+	since, _ := time.ParseDuration(config.Since)
+	return since
+}
+
+func (config *ServiceMapExportConfig) Validate() error {
+	if !utils.Contains(servicemap.Formats, config.Format) {
+		return fmt.Errorf("%s is not a valid --%s, use one of %v", config.Format, FormatServiceMapName, servicemap.Formats)
+	}
+
+	if config.Since != "" {
+		if since, err := time.ParseDuration(config.Since); err != nil || since <= 0 {
+			return fmt.Errorf("%s is not a valid --%s, use a duration like 10m", config.Since, SinceServiceMapName)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package configStructs
+
+import (
+	"os"
+	"path"
+)
+
+const (
+	OutputSnapshotName    = "output"
+	NamespaceSnapshotName = "namespace"
+)
+
+type SnapshotSaveConfig struct {
+	Output string `yaml:"output"`
+}
+
+// FilePath returns where the snapshot is written, kubeshark_snapshot.tar.gz in the working directory by default.
+func (config *SnapshotSaveConfig) FilePath() string {
+	if config.Output == "" {
+		pwd, _ := os.Getwd()
+		return path.Join(pwd, "kubeshark_snapshot.tar.gz")
+	}
+
+	return config.Output
+}
+
+type SnapshotLoadConfig struct {
+	Namespace string `yaml:"namespace" default:"kubeshark-snapshot"`
+}
+
+type SnapshotConfig struct {
+	Save SnapshotSaveConfig `yaml:"save"`
+	Load SnapshotLoadConfig `yaml:"load"`
+}
@@ -0,0 +1,29 @@
+package configStructs
+
+import (
+	"fmt"
+	"text/template"
+)
+
+const (
+	FormatTailName = "format"
+	RawTailName    = "raw"
+)
+
+// TailConfig configures how live entries are printed, by the tail command and by tap --tail. Format is a Go
+// template executed with every entry, an empty format prints the default line.
+type TailConfig struct {
+	Format string `yaml:"format"`
+	Raw    bool   `yaml:"raw" default:"false"`
+}
+
+func (config *TailConfig) Validate() error {
+	if config.Format == "" {
+		return nil
+	}
+
+	if _, err := template.New("tail").Parse(config.Format); err != nil {
+		return fmt.Errorf("%s is not a valid --%s template, %v", config.Format, FormatTailName, err)
+	}
+	return nil
+}
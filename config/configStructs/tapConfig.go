@@ -7,9 +7,11 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/kubeshark/kubeshark/utils"
 	"github.com/kubeshark/worker/models"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const (
@@ -24,8 +26,46 @@ const (
 	TlsName                      = "tls"
 	ProfilerName                 = "profiler"
 	MaxLiveStreamsName           = "max-live-streams"
+	DetachTapName                = "detach"
+	AttachTapName                = "attach"
+	TailTapName                  = "tail"
 )
 
+// BasenineMemoryOverheadBytes is the memory basenine needs on top of the entries it holds.
+const BasenineMemoryOverheadBytes = 256 * 1024 * 1024
+
+// BasenineResources leaves the memory limit empty by default, it is then derived from max-entries-db-size.
+type BasenineResources struct {
+	CpuLimit       string `yaml:"cpu-limit" default:"750m"`
+	MemoryLimit    string `yaml:"memory-limit"`
+	CpuRequests    string `yaml:"cpu-requests" default:"50m"`
+	MemoryRequests string `yaml:"memory-requests" default:"50Mi"`
+}
+
+type StorageConfig struct {
+	Enabled      bool   `yaml:"enabled" default:"false"`
+	Size         string `yaml:"size" default:"1Gi"`
+	StorageClass string `yaml:"storage-class"`
+}
+
+// MinReaperGracePeriod is the shortest grace period the reaper can enforce, it checks the lease once a minute.
+const MinReaperGracePeriod = time.Minute
+
+// ReaperConfig configures the in-cluster reaper, which removes the instance when the CLI stopped renewing
+// its lease for the grace period without cleaning up. The grace period is also how long the session lock
+// is held after the CLI stopped renewing it.
+type ReaperConfig struct {
+	Enabled     bool   `yaml:"enabled" default:"true"`
+	GracePeriod string `yaml:"grace-period" default:"10m"`
+	// Image is pinned, the reaper script relies on its kubectl and on the GNU date that parses the renew time of the lease
+	Image string `yaml:"image" default:"bitnami/kubectl:1.23.3"`
+}
+
+func (config *ReaperConfig) GetGracePeriod() time.Duration {
+	gracePeriod, _ := time.ParseDuration(config.GracePeriod)
+	return gracePeriod
+}
+
 type TapConfig struct {
 	PodRegexStr       string   `yaml:"regex" default:".*"`
 	GuiPort           uint16   `yaml:"gui-port" default:"8899"`
@@ -41,16 +81,23 @@ type TapConfig struct {
 		ResponseBody       []string `yaml:"response-body"`
 		RequestQueryParams []string `yaml:"request-query-params"`
 	} `yaml:"redact-patterns"`
-	HumanMaxEntriesDBSize string           `yaml:"max-entries-db-size" default:"200MB"`
-	InsertionFilter       string           `yaml:"insertion-filter" default:""`
-	DryRun                bool             `yaml:"dry-run" default:"false"`
-	HubResources          models.Resources `yaml:"hub-resources"`
-	TapperResources       models.Resources `yaml:"tapper-resources"`
-	ServiceMesh           bool             `yaml:"service-mesh" default:"false"`
-	Tls                   bool             `yaml:"tls" default:"false"`
-	PacketCapture         string           `yaml:"packet-capture" default:"libpcap"`
-	Profiler              bool             `yaml:"profiler" default:"false"`
-	MaxLiveStreams        int              `yaml:"max-live-streams" default:"500"`
+	HumanMaxEntriesDBSize string            `yaml:"max-entries-db-size" default:"200MB"`
+	InsertionFilter       string            `yaml:"insertion-filter" default:""`
+	DryRun                bool              `yaml:"dry-run" default:"false"`
+	HubResources          models.Resources  `yaml:"hub-resources"`
+	BasenineResources     BasenineResources `yaml:"basenine-resources"`
+	FrontResources        models.Resources  `yaml:"front-resources"`
+	TapperResources       models.Resources  `yaml:"tapper-resources"`
+	ServiceMesh           bool              `yaml:"service-mesh" default:"false"`
+	Tls                   bool              `yaml:"tls" default:"false"`
+	PacketCapture         string            `yaml:"packet-capture" default:"libpcap"`
+	Profiler              bool              `yaml:"profiler" default:"false"`
+	MaxLiveStreams        int               `yaml:"max-live-streams" default:"500"`
+	Storage               StorageConfig     `yaml:"storage"`
+	Detach                bool              `yaml:"detach" default:"false"`
+	Attach                bool              `yaml:"attach" default:"false"`
+	Tail                  bool              `yaml:"tail" default:"false"`
+	Reaper                ReaperConfig      `yaml:"reaper"`
 }
 
 func (config *TapConfig) PodRegex() *regexp.Regexp {
@@ -63,6 +110,20 @@ func (config *TapConfig) MaxEntriesDBSizeBytes() int64 {
 	return maxEntriesDBSizeBytes
 }
 
+func (config *TapConfig) GetBasenineResources() models.Resources {
+	memoryLimit := config.BasenineResources.MemoryLimit
+	if memoryLimit == "" {
+		memoryLimit = resource.NewQuantity(config.MaxEntriesDBSizeBytes()+BasenineMemoryOverheadBytes, resource.BinarySI).String()
+	}
+
+	return models.Resources{
+		CpuLimit:       config.BasenineResources.CpuLimit,
+		MemoryLimit:    memoryLimit,
+		CpuRequests:    config.BasenineResources.CpuRequests,
+		MemoryRequests: config.BasenineResources.MemoryRequests,
+	}
+}
+
 func (config *TapConfig) GetInsertionFilter() string {
 	insertionFilter := config.InsertionFilter
 	if fs.ValidPath(insertionFilter) {
@@ -128,5 +189,45 @@ func (config *TapConfig) Validate() error {
 		return fmt.Errorf("Could not parse --%s value %s", HumanMaxEntriesDBSizeTapName, config.HumanMaxEntriesDBSize)
 	}
 
+	if config.BasenineResources.MemoryLimit != "" {
+		memoryLimit, err := resource.ParseQuantity(config.BasenineResources.MemoryLimit)
+		if err != nil {
+			return fmt.Errorf("Could not parse basenine memory limit %s, %v", config.BasenineResources.MemoryLimit, err)
+		}
+
+		if requiredBytes := config.MaxEntriesDBSizeBytes() + BasenineMemoryOverheadBytes; memoryLimit.Value() < requiredBytes {
+			log.Printf(utils.Warning, fmt.Sprintf("The basenine memory limit %s can't fit --%s %s, basenine may be OOM killed. Set a memory limit of at least %s, or leave it empty to derive it from the DB size",
+				config.BasenineResources.MemoryLimit, HumanMaxEntriesDBSizeTapName, config.HumanMaxEntriesDBSize, resource.NewQuantity(requiredBytes, resource.BinarySI).String()))
+		}
+	}
+
+	if config.Storage.Enabled {
+		if _, err := resource.ParseQuantity(config.Storage.Size); err != nil {
+			return fmt.Errorf("Could not parse storage size %s, %v", config.Storage.Size, err)
+		}
+	}
+
+	gracePeriod, err := time.ParseDuration(config.Reaper.GracePeriod)
+	if err != nil {
+		return fmt.Errorf("Could not parse reaper grace period %s, %v", config.Reaper.GracePeriod, err)
+	}
+	if gracePeriod < MinReaperGracePeriod {
+		return fmt.Errorf("The reaper grace period %s is shorter than the minimum of %s", config.Reaper.GracePeriod, MinReaperGracePeriod)
+	}
+
+	if config.Attach && config.Detach {
+		return fmt.Errorf("--%s and --%s can't be used together", AttachTapName, DetachTapName)
+	}
+
+	if config.Tail && config.Detach {
+		return fmt.Errorf("--%s and --%s can't be used together", TailTapName, DetachTapName)
+	}
+
 	return nil
 }
+
+// IsReaperNeeded reports whether the run renews a lease and is watched by the reaper, a detached run is left
+// running on purpose.
+func (config *TapConfig) IsReaperNeeded() bool {
+	return config.Reaper.Enabled && !config.Detach
+}
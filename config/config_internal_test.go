@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+
+	"github.com/spf13/cobra"
 )
 
 type ConfigMock struct {
@@ -383,3 +385,44 @@ func TestGetParsedValueInvalidValue(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCommandPath(t *testing.T) {
+	root := &cobra.Command{Use: "kubeshark"}
+	parent := &cobra.Command{Use: "oas"}
+	list := &cobra.Command{Use: "list"}
+	export := &cobra.Command{Use: "export", Annotations: map[string]string{ConfigKeyAnnotation: "oas-export"}}
+	root.AddCommand(parent)
+	parent.AddCommand(list, export)
+
+	if path := getCommandPath(list); !reflect.DeepEqual(path, []string{"oas", "list"}) {
+		t.Errorf("unexpected path of oas list %v", path)
+	}
+	if path := getCommandPath(export); !reflect.DeepEqual(path, []string{"oas-export"}) {
+		t.Errorf("unexpected path of oas export %v", path)
+	}
+}
+
+func TestIsSet(t *testing.T) {
+	setKeys = map[string]bool{}
+	defer func() { setKeys = map[string]bool{} }()
+
+	recordSetKeys(nil, map[string]interface{}{
+		"front": map[string]interface{}{"port-forward": map[string]interface{}{"src-port": 8080}},
+		"tap":   map[string]interface{}{"regex": ".*"},
+	})
+	configMock := ConfigMock{}
+	if err := mergeSetFlag(reflect.ValueOf(&configMock).Elem(), []string{"section.test=value"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"front.port-forward.src-port", "tap.regex", "section.test"} {
+		if !IsSet(key) {
+			t.Errorf("unexpected unset key %v", key)
+		}
+	}
+	for _, key := range []string{"hub.port-forward.src-port", "front.port-forward", "test"} {
+		if IsSet(key) {
+			t.Errorf("unexpected set key %v", key)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/kubeshark/kubeshark/kubernetes"
 	"github.com/kubeshark/kubeshark/utils"
 	"github.com/kubeshark/worker/models"
 
@@ -34,6 +35,13 @@ func NewConnector(url string, retries int, timeout time.Duration) *Connector {
 	}
 }
 
+// NewProxyConnector connects to the local proxy on port, authenticated with the token of the run.
+func NewProxyConnector(port uint16, retries int, timeout time.Duration) *Connector {
+	connector := NewConnector(GetProxyUrl(port), retries, timeout)
+	connector.client.Transport = newProxyTransport()
+	return connector
+}
+
 func (connector *Connector) TestConnection(path string) error {
 	retriesLeft := connector.retries
 	for retriesLeft > 0 {
@@ -53,6 +61,15 @@ func (connector *Connector) TestConnection(path string) error {
 	return nil
 }
 
+// CheckConnection reaches path once, unlike TestConnection it doesn't wait for the Hub to get ready.
+func (connector *Connector) CheckConnection(path string) error {
+	response, err := utils.Get(fmt.Sprintf("%s%s", connector.url, path), connector.client)
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
 func (connector *Connector) isReachable(path string) (bool, error) {
 	targetUrl := fmt.Sprintf("%s%s", connector.url, path)
 	if _, err := utils.Get(targetUrl, connector.client); err != nil {
@@ -91,3 +108,18 @@ func (connector *Connector) ReportTappedPods(pods []core.Pod) error {
 		}
 	}
 }
+
+func (connector *Connector) ReportUntappablePods(untappablePods []kubernetes.UntappablePod) error {
+	untappablePodsUrl := fmt.Sprintf("%s/status/untappablePods", connector.url)
+
+	if jsonValue, err := json.Marshal(untappablePods); err != nil {
+		return fmt.Errorf("Failed Marshal the untappable pods %w", err)
+	} else {
+		if _, err := utils.Post(untappablePodsUrl, "application/json", bytes.NewBuffer(jsonValue), connector.client); err != nil {
+			return fmt.Errorf("Failed sending to Hub the untappable pods %w", err)
+		} else {
+			log.Printf("Reported to Hub about %d untappable pods successfully", len(untappablePods))
+			return nil
+		}
+	}
+}
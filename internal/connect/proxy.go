@@ -0,0 +1,154 @@
+package connect
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
+	"github.com/kubeshark/kubeshark/kubernetes"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+// The token and certificate of the local proxies are generated once per run and shared by the hub and front.
+var (
+	proxyOptionsOnce sync.Once
+	proxyOptions     *kubernetes.ProxyOptions
+	proxyOptionsErr  error
+	proxyRootCAs     *x509.CertPool
+)
+
+// GetProxyOptions returns the options of the local proxies of this run, the ports of the hub and front must
+// be resolved before it's first called since they make up the allowed origins.
+func GetProxyOptions() (*kubernetes.ProxyOptions, error) {
+	proxyOptionsOnce.Do(func() {
+		proxyOptions, proxyOptionsErr = createProxyOptions()
+	})
+	return proxyOptions, proxyOptionsErr
+}
+
+func createProxyOptions() (*kubernetes.ProxyOptions, error) {
+	proxyConfig := config.Config.Proxy
+	opts := &kubernetes.ProxyOptions{
+		Host: config.Config.Tap.ProxyHost,
+		Guard: &kubernetes.ProxyGuardOptions{
+			BasicAuthUsername: proxyConfig.BasicAuth.Username,
+			BasicAuthPassword: proxyConfig.BasicAuth.Password,
+		},
+	}
+
+	if proxyConfig.Token {
+		token, err := kubernetes.GenerateProxyToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed generating the proxy token, %w", err)
+		}
+		opts.Guard.Token = token
+	}
+
+	hosts := getProxyHosts()
+	if proxyConfig.Tls {
+		certificate, rootCAs, err := kubernetes.GenerateProxyCertificate(hosts)
+		if err != nil {
+			return nil, fmt.Errorf("failed generating the proxy certificate, %w", err)
+		}
+		opts.Certificate = certificate
+		proxyRootCAs = rootCAs
+	}
+
+	for _, host := range hosts {
+		for _, port := range []uint16{config.Config.Hub.PortForward.SrcPort, config.Config.Front.PortForward.SrcPort} {
+			opts.Guard.AllowedOrigins = append(opts.Guard.AllowedOrigins, fmt.Sprintf("%s://%s:%d", opts.GetScheme(), host, port))
+		}
+	}
+	opts.Guard.AllowedOrigins = append(opts.Guard.AllowedOrigins, proxyConfig.AllowedOrigins...)
+
+	return opts, nil
+}
+
+func getProxyHosts() []string {
+	hosts := []string{"localhost", "127.0.0.1"}
+	if proxyHost := config.Config.Tap.ProxyHost; proxyHost != "localhost" && proxyHost != "127.0.0.1" {
+		hosts = append(hosts, proxyHost)
+	}
+	return hosts
+}
+
+// getPublicProxyHost is the host the printed addresses use, a proxy bound to all interfaces is opened locally.
+func getPublicProxyHost() string {
+	proxyHost := config.Config.Tap.ProxyHost
+	if configStructs.IsLoopbackHost(proxyHost) || proxyHost == "0.0.0.0" || proxyHost == "::" {
+		return "localhost"
+	}
+	return proxyHost
+}
+
+// GetProxyUrl returns the address of the local proxy on port, which the CLI connects to.
+func GetProxyUrl(port uint16) string {
+	scheme := "http"
+	if config.Config.Proxy.Tls {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, getPublicProxyHost(), port)
+}
+
+// GetProxyBrowserUrl returns the address of the local proxy on port with the token, opening it in the browser
+// trades the token for a cookie.
+func GetProxyBrowserUrl(port uint16) string {
+	proxyUrl := GetProxyUrl(port)
+	opts, err := GetProxyOptions()
+	if err != nil || opts.Guard.Token == "" {
+		return proxyUrl
+	}
+	return fmt.Sprintf("%s/?%s=%s", proxyUrl, kubernetes.ProxyTokenQueryParam, url.QueryEscape(opts.Guard.Token))
+}
+
+// proxyTransport authenticates the requests of the CLI to the local proxies and trusts their certificate.
+type proxyTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (transport *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if transport.token != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(kubernetes.ProxyTokenHeader, transport.token)
+	}
+	return transport.base.RoundTrip(req)
+}
+
+func newProxyTransport() http.RoundTripper {
+	opts, err := GetProxyOptions()
+	if err != nil {
+		return http.DefaultTransport
+	}
+
+	base := http.DefaultTransport
+	if proxyRootCAs != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = &tls.Config{RootCAs: proxyRootCAs, MinVersion: tls.VersionTLS12}
+		base = transport
+	}
+
+	return &proxyTransport{token: opts.Guard.Token, base: base}
+}
+
+// NewProxyHubClient returns a client of the hub served by the local proxy on port.
+func NewProxyHubClient(port uint16) (*hub.Client, error) {
+	opts, err := GetProxyOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	var clientOptions []hub.Option
+	if proxyRootCAs != nil {
+		clientOptions = append(clientOptions, hub.WithTLSConfig(&tls.Config{RootCAs: proxyRootCAs, MinVersion: tls.VersionTLS12}))
+	}
+	if opts.Guard.Token != "" {
+		clientOptions = append(clientOptions, hub.WithHeader(kubernetes.ProxyTokenHeader, opts.Guard.Token))
+	}
+	return hub.NewClient(GetProxyUrl(port), clientOptions...)
+}
@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const podDeletionPollInterval = 500 * time.Millisecond
+
+// newApplyPatch serializes a typed object into a server-side apply patch, which requires the type meta
+// the typed clients leave empty.
+func newApplyPatch(object runtime.Object, gvk schema.GroupVersionKind) ([]byte, error) {
+	object.GetObjectKind().SetGroupVersionKind(gvk)
+	return json.Marshal(object)
+}
+
+func applyPatchOptions() metav1.PatchOptions {
+	force := true
+	return metav1.PatchOptions{
+		Force:        &force,
+		FieldManager: fieldManagerName,
+	}
+}
+
+// ApplyPod applies the pod, most of a pod spec is immutable so a pod whose spec changed in an immutable field
+// is replaced. A pod that is invalid for any other reason is reported as is.
+func (provider *Provider) ApplyPod(ctx context.Context, namespace string, pod *core.Pod) (*core.Pod, error) {
+	data, err := newApplyPatch(pod, core.SchemeGroupVersion.WithKind("Pod"))
+	if err != nil {
+		return nil, err
+	}
+
+	appliedPod, err := provider.clientSet.CoreV1().Pods(namespace).Patch(ctx, pod.Name, types.ApplyPatchType, data, applyPatchOptions())
+	if !isImmutablePodSpecError(err) {
+		return appliedPod, err
+	}
+
+	if err := provider.RemovePod(ctx, namespace, pod.Name); err != nil {
+		return nil, err
+	}
+	if err := provider.waitUntilPodDeleted(ctx, namespace, pod.Name); err != nil {
+		return nil, err
+	}
+
+	return provider.clientSet.CoreV1().Pods(namespace).Patch(ctx, pod.Name, types.ApplyPatchType, data, applyPatchOptions())
+}
+
+// isImmutablePodSpecError reports whether an update of a pod was rejected only because it changed fields of
+// the pod spec that can't be updated, which the API server reports as forbidden spec fields.
+func isImmutablePodSpecError(err error) bool {
+	if !k8serrors.IsInvalid(err) {
+		return false
+	}
+
+	status, ok := err.(k8serrors.APIStatus)
+	if !ok || status.Status().Details == nil || len(status.Status().Details.Causes) == 0 {
+		return false
+	}
+
+	for _, cause := range status.Status().Details.Causes {
+		isSpecField := cause.Field == "spec" || strings.HasPrefix(cause.Field, "spec.")
+		if !isSpecField || (cause.Type != metav1.CauseType(field.ErrorTypeForbidden) && !strings.Contains(cause.Message, "field is immutable")) {
+			return false
+		}
+	}
+	return true
+}
+
+func (provider *Provider) waitUntilPodDeleted(ctx context.Context, namespace string, podName string) error {
+	return wait.PollImmediateUntil(podDeletionPollInterval, func() (bool, error) {
+		_, err := provider.clientSet.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}, ctx.Done())
+}
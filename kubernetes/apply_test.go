@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"errors"
+	"testing"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestIsImmutablePodSpecError(t *testing.T) {
+	podKind := schema.GroupKind{Kind: "Pod"}
+	tests := []struct {
+		Name     string
+		Err      error
+		Expected bool
+	}{
+		{
+			Name:     "spec update",
+			Err:      k8serrors.NewInvalid(podKind, "ks-hub", field.ErrorList{field.Forbidden(field.NewPath("spec"), "pod updates may not change fields other than `spec.containers[*].image`")}),
+			Expected: true,
+		},
+		{
+			Name:     "immutable spec field",
+			Err:      k8serrors.NewInvalid(podKind, "ks-hub", field.ErrorList{field.Invalid(field.NewPath("spec", "volumes"), nil, "field is immutable")}),
+			Expected: true,
+		},
+		{
+			Name:     "invalid spec value",
+			Err:      k8serrors.NewInvalid(podKind, "ks-hub", field.ErrorList{field.Invalid(field.NewPath("spec", "containers").Index(0).Child("image"), "", "must not be empty")}),
+			Expected: false,
+		},
+		{
+			Name: "spec update and invalid metadata",
+			Err: k8serrors.NewInvalid(podKind, "ks-hub", field.ErrorList{
+				field.Forbidden(field.NewPath("spec"), "pod updates may not change fields other than `spec.containers[*].image`"),
+				field.Invalid(field.NewPath("metadata", "labels"), "-", "must be a valid label value"),
+			}),
+			Expected: false,
+		},
+		{
+			Name:     "not invalid",
+			Err:      errors.New("connection refused"),
+			Expected: false,
+		},
+		{
+			Name:     "no error",
+			Expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if actual := isImmutablePodSpecError(test.Err); actual != test.Expected {
+				t.Errorf("unexpected result - expected: %v, actual: %v", test.Expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package kubernetes
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	reconnectInitialDelay = time.Second
+	reconnectMaxDelay     = 30 * time.Second
+	// reconnectStableAfter is how long a connection has to last before the next failure starts over
+	// from the initial delay
+	reconnectStableAfter = time.Minute
+)
+
+// reconnectBackoff doubles the delay between reconnection attempts, so an API server that is down or a
+// machine that just woke up isn't flooded with attempts.
+type reconnectBackoff struct {
+	delay       time.Duration
+	connectedAt time.Time
+}
+
+func (backoff *reconnectBackoff) connected() {
+	backoff.connectedAt = time.Now()
+}
+
+// wait sleeps before the next attempt, it returns false when ctx is done first.
+func (backoff *reconnectBackoff) wait(ctx context.Context) bool {
+	if backoff.delay == 0 || (!backoff.connectedAt.IsZero() && time.Since(backoff.connectedAt) > reconnectStableAfter) {
+		backoff.delay = reconnectInitialDelay
+	} else if backoff.delay *= 2; backoff.delay > reconnectMaxDelay {
+		backoff.delay = reconnectMaxDelay
+	}
+	backoff.connectedAt = time.Time{}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(backoff.delay):
+		return true
+	}
+}
@@ -1,26 +1,115 @@
 package kubernetes
 
+import (
+	"fmt"
+	"regexp"
+)
+
 const (
 	KubesharkResourcesPrefix   = "ks-"
-	FrontPodName               = KubesharkResourcesPrefix + "front"
-	FrontServiceName           = FrontPodName
-	HubPodName                 = KubesharkResourcesPrefix + "hub"
-	HubServiceName             = HubPodName
-	ClusterRoleBindingName     = KubesharkResourcesPrefix + "cluster-role-binding"
-	ClusterRoleName            = KubesharkResourcesPrefix + "cluster-role"
+	DefaultInstance            = "default"
 	K8sAllNamespaces           = ""
-	RoleBindingName            = KubesharkResourcesPrefix + "role-binding"
-	RoleName                   = KubesharkResourcesPrefix + "role"
-	ServiceAccountName         = KubesharkResourcesPrefix + "service-account"
-	TapperDaemonSetName        = KubesharkResourcesPrefix + "worker-daemon-set"
-	TapperPodName              = KubesharkResourcesPrefix + "worker"
-	ConfigMapName              = KubesharkResourcesPrefix + "config"
 	MinKubernetesServerVersion = "1.16.0"
 )
 
+// The names of the objects of the current instance, SetInstance derives them from the instance name.
+var (
+	Instance                       string
+	FrontPodName                   string
+	FrontServiceName               string
+	FrontIngressName               string
+	HubPodName                     string
+	HubServiceName                 string
+	HubIngressName                 string
+	ClusterRoleBindingName         string
+	ClusterRoleName                string
+	RoleBindingName                string
+	RoleName                       string
+	ServiceAccountName             string
+	TapperDaemonSetName            string
+	TapperPodName                  string
+	ConfigMapName                  string
+	SecurityContextConstraintsName string
+	PersistentVolumeClaimName      string
+	AuthSecretName                 string
+	LeaseName                      string
+	ReaperName                     string
+)
+
 const (
 	LabelPrefixApp      = "app.kubernetes.io/"
 	LabelManagedBy      = LabelPrefixApp + "managed-by"
 	LabelCreatedBy      = LabelPrefixApp + "created-by"
+	LabelInstance       = LabelPrefixApp + "instance"
+	LabelComponent      = LabelPrefixApp + "component"
 	LabelValueKubeshark = "kubeshark"
+	LabelValueReaper    = "reaper"
 )
+
+func init() {
+	SetInstance(DefaultInstance)
+}
+
+// SetInstance switches the object names to those of another instance. The default instance keeps the names
+// Kubeshark always used, so the objects of earlier versions are still found.
+func SetInstance(instance string) {
+	Instance = instance
+
+	prefix := KubesharkResourcesPrefix
+	if instance != DefaultInstance {
+		prefix = KubesharkResourcesPrefix + instance + "-"
+	}
+
+	FrontPodName = prefix + "front"
+	FrontServiceName = FrontPodName
+	FrontIngressName = FrontPodName
+	HubPodName = prefix + "hub"
+	HubServiceName = HubPodName
+	HubIngressName = HubPodName
+	ClusterRoleBindingName = prefix + "cluster-role-binding"
+	ClusterRoleName = prefix + "cluster-role"
+	RoleBindingName = prefix + "role-binding"
+	RoleName = prefix + "role"
+	ServiceAccountName = prefix + "service-account"
+	TapperDaemonSetName = prefix + "worker-daemon-set"
+	TapperPodName = prefix + "worker"
+	ConfigMapName = prefix + "config"
+	SecurityContextConstraintsName = prefix + "scc"
+	PersistentVolumeClaimName = prefix + "persistent-volume-claim"
+	AuthSecretName = prefix + "auth-secret"
+	LeaseName = prefix + "lease"
+	ReaperName = prefix + "reaper"
+}
+
+// GetInstance returns the instance an object belongs to, objects created before instances existed
+// have no instance label and belong to the default instance.
+func GetInstance(labels map[string]string) string {
+	if instance, ok := labels[LabelInstance]; ok && instance != "" {
+		return instance
+	}
+	return DefaultInstance
+}
+
+// The pods of the tapper daemon set are named after TapperPodName followed by a generated suffix, and the events
+// of an object are named after the object followed by a generated suffix. The patterns match these exact formats
+// so that instances whose names start alike, ks-worker and ks-worker-2-worker, don't see each other's pods.
+const generatedSuffixPattern = "[a-z0-9]+"
+
+// TapperPodRegex matches the pods of the tapper daemon set of the current instance.
+func TapperPodRegex() *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf("^%s$", tapperPodPattern()))
+}
+
+// TapperEventRegex matches the events of the tapper pods of the current instance.
+func TapperEventRegex() *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`^%s\.%s$`, tapperPodPattern(), generatedSuffixPattern))
+}
+
+// HubEventRegex matches the events of the hub pod of the current instance.
+func HubEventRegex() *regexp.Regexp {
+	return regexp.MustCompile(fmt.Sprintf(`^%s\.%s$`, regexp.QuoteMeta(HubPodName), generatedSuffixPattern))
+}
+
+func tapperPodPattern() string {
+	return fmt.Sprintf("%s-%s", regexp.QuoteMeta(TapperPodName), generatedSuffixPattern)
+}
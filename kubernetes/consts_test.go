@@ -0,0 +1,35 @@
+package kubernetes
+
+import (
+	"testing"
+)
+
+func TestTapperRegexMatchesOwnInstanceOnly(t *testing.T) {
+	defer SetInstance(DefaultInstance)
+
+	SetInstance("worker-2")
+	otherPod, otherEvent := TapperPodName+"-x7k2p", TapperPodName+"-x7k2p.16f8a3c2d1e0b9a4"
+
+	SetInstance(DefaultInstance)
+	tests := []struct {
+		Name     string
+		Actual   bool
+		Expected bool
+	}{
+		{Name: "own pod", Actual: TapperPodRegex().MatchString(TapperPodName + "-x7k2p"), Expected: true},
+		{Name: "other instance pod", Actual: TapperPodRegex().MatchString(otherPod), Expected: false},
+		{Name: "own event", Actual: TapperEventRegex().MatchString(TapperPodName + "-x7k2p.16f8a3c2d1e0b9a4"), Expected: true},
+		{Name: "other instance event", Actual: TapperEventRegex().MatchString(otherEvent), Expected: false},
+		{Name: "daemon set event", Actual: TapperEventRegex().MatchString(TapperDaemonSetName + ".16f8a3c2d1e0b9a4"), Expected: false},
+		{Name: "own hub event", Actual: HubEventRegex().MatchString(HubPodName + ".16f8a3c2d1e0b9a4"), Expected: true},
+		{Name: "other instance hub event", Actual: HubEventRegex().MatchString(HubPodName + "-hub.16f8a3c2d1e0b9a4"), Expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.Actual != test.Expected {
+				t.Errorf("unexpected result - expected: %v, actual: %v", test.Expected, test.Actual)
+			}
+		})
+	}
+}
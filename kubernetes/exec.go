@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// BasenineContainerName is the container of the hub pod that stores the traffic.
+const BasenineContainerName = "basenine"
+
+// RestoreContainerName is the init container of the hub pod that holds basenine back while a snapshot is
+// restored into its data directory, it exits once RestoredFileName is created there.
+const (
+	RestoreContainerName = "restore"
+	RestoredFileName     = ".kubeshark-restored"
+)
+
+// ExecInPod runs command in a container and streams its standard input and output, nil streams aren't attached.
+// The output of the command is in stderr when it fails.
+func (provider *Provider) ExecInPod(ctx context.Context, namespace string, podName string, containerName string, command []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	request := provider.clientSet.CoreV1().RESTClient().Post().
+		Namespace(namespace).
+		Resource("pods").
+		Name(podName).
+		SubResource("exec").
+		VersionedParams(&core.PodExecOptions{
+			Container: containerName,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    stdout != nil,
+			Stderr:    stderr != nil,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(&provider.clientConfig, http.MethodPost, request.URL())
+	if err != nil {
+		return err
+	}
+
+	// The executor of this client version can't be canceled, the stream is left behind when ctx is done
+	done := make(chan error, 1)
+	go func() {
+		done <- executor.Stream(remotecommand.StreamOptions{Stdin: stdin, Stdout: stdout, Stderr: stderr})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed running %v in %s/%s, %w", command, podName, containerName, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
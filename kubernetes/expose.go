@@ -0,0 +1,166 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const loadBalancerAddressPollInterval = 2 * time.Second
+
+type IngressOptions struct {
+	Host          string
+	TlsSecretName string
+	ClassName     string
+	Annotations   map[string]string
+}
+
+func (provider *Provider) ApplyIngress(ctx context.Context, namespace string, ingressName string, serviceName string, servicePort int32, opts IngressOptions) (*networking.Ingress, error) {
+	pathType := networking.PathTypePrefix
+	ingress := &networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ingressName,
+			Annotations: opts.Annotations,
+			Labels: map[string]string{
+				LabelManagedBy: provider.managedBy,
+				LabelCreatedBy: provider.createdBy,
+				LabelInstance:  Instance,
+			},
+		},
+		Spec: networking.IngressSpec{
+			Rules: []networking.IngressRule{
+				{
+					Host: opts.Host,
+					IngressRuleValue: networking.IngressRuleValue{
+						HTTP: &networking.HTTPIngressRuleValue{
+							Paths: []networking.HTTPIngressPath{
+								{
+									Path:     "/",
+									PathType: &pathType,
+									Backend: networking.IngressBackend{
+										Service: &networking.IngressServiceBackend{
+											Name: serviceName,
+											Port: networking.ServiceBackendPort{
+												Number: servicePort,
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if opts.ClassName != "" {
+		ingress.Spec.IngressClassName = &opts.ClassName
+	}
+
+	if opts.TlsSecretName != "" {
+		ingress.Spec.TLS = []networking.IngressTLS{
+			{
+				Hosts:      []string{opts.Host},
+				SecretName: opts.TlsSecretName,
+			},
+		}
+	}
+
+	data, err := newApplyPatch(ingress, networking.SchemeGroupVersion.WithKind("Ingress"))
+	if err != nil {
+		return nil, err
+	}
+	return provider.clientSet.NetworkingV1().Ingresses(namespace).Patch(ctx, ingressName, types.ApplyPatchType, data, applyPatchOptions())
+}
+
+// DoesIngressExist reports whether an ingress exists, so a failed run only rolls back an ingress it created.
+func (provider *Provider) DoesIngressExist(ctx context.Context, namespace string, name string) (bool, error) {
+	ingressResource, err := provider.clientSet.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	return provider.doesResourceExist(ingressResource, err)
+}
+
+// GetExposedUrl returns the address a service is reachable at from outside the cluster, through its ingress,
+// load balancer or node port. It returns an empty url when the service is only reachable through a proxy.
+// For a load balancer it waits until the cloud provider assigns an address or ctx is done.
+func (provider *Provider) GetExposedUrl(ctx context.Context, namespace string, serviceName string, ingressName string) (string, error) {
+	ingress, err := provider.clientSet.NetworkingV1().Ingresses(namespace).Get(ctx, ingressName, metav1.GetOptions{})
+	if err == nil {
+		return getIngressUrl(ingress), nil
+	} else if !k8serrors.IsNotFound(err) {
+		return "", err
+	}
+
+	for {
+		service, err := provider.clientSet.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+
+		switch service.Spec.Type {
+		case core.ServiceTypeLoadBalancer:
+			for _, loadBalancerIngress := range service.Status.LoadBalancer.Ingress {
+				if loadBalancerIngress.Hostname != "" {
+					return fmt.Sprintf("http://%s:%d", loadBalancerIngress.Hostname, service.Spec.Ports[0].Port), nil
+				}
+				if loadBalancerIngress.IP != "" {
+					return fmt.Sprintf("http://%s:%d", loadBalancerIngress.IP, service.Spec.Ports[0].Port), nil
+				}
+			}
+		case core.ServiceTypeNodePort:
+			nodeAddress, err := provider.getNodeAddress(ctx)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("http://%s:%d", nodeAddress, service.Spec.Ports[0].NodePort), nil
+		default:
+			return "", nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("load balancer of service %s has no address yet", serviceName)
+		case <-time.After(loadBalancerAddressPollInterval):
+		}
+	}
+}
+
+func getIngressUrl(ingress *networking.Ingress) string {
+	scheme := "http"
+	if len(ingress.Spec.TLS) > 0 {
+		scheme = "https"
+	}
+
+	host := ""
+	if len(ingress.Spec.Rules) > 0 {
+		host = ingress.Spec.Rules[0].Host
+	}
+
+	return fmt.Sprintf("%s://%s", scheme, host)
+}
+
+// getNodeAddress prefers an external address, clusters without one are usually reachable through the internal one.
+func (provider *Provider) getNodeAddress(ctx context.Context) (string, error) {
+	nodes, err := provider.clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	for _, addressType := range []core.NodeAddressType{core.NodeExternalIP, core.NodeInternalIP} {
+		for _, node := range nodes.Items {
+			for _, address := range node.Status.Addresses {
+				if address.Type == addressType {
+					return address.Address, nil
+				}
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no node has an external or internal ip address")
+}
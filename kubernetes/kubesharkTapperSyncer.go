@@ -27,12 +27,15 @@ type KubesharkTapperSyncer struct {
 	startTime              time.Time
 	context                context.Context
 	CurrentlyTappedPods    []core.Pod
+	CurrentUntappablePods  []UntappablePod
 	config                 TapperSyncerConfig
 	kubernetesProvider     *Provider
 	TapPodChangesOut       chan TappedPodChangeEvent
+	UntappablePodsOut      chan []UntappablePod
 	TapperStatusChangedOut chan models.TapperStatus
 	ErrorOut               chan K8sTapManagerError
 	nodeToTappedPodMap     models.NodeToPodsMap
+	nodeUntappableReasons  map[string]string
 	tappedNodes            []string
 }
 
@@ -55,11 +58,14 @@ func CreateAndStartKubesharkTapperSyncer(ctx context.Context, kubernetesProvider
 		startTime:              startTime.Truncate(time.Second), // Round down because k8s CreationTimestamp is given in 1 sec resolution.
 		context:                ctx,
 		CurrentlyTappedPods:    make([]core.Pod, 0),
+		CurrentUntappablePods:  make([]UntappablePod, 0),
 		config:                 config,
 		kubernetesProvider:     kubernetesProvider,
 		TapPodChangesOut:       make(chan TappedPodChangeEvent, 100),
+		UntappablePodsOut:      make(chan []UntappablePod, 100),
 		TapperStatusChangedOut: make(chan models.TapperStatus, 100),
 		ErrorOut:               make(chan K8sTapManagerError, 100),
+		nodeUntappableReasons:  make(map[string]string),
 	}
 
 	if err, _ := syncer.updateCurrentlyTappedPods(); err != nil {
@@ -77,8 +83,7 @@ func CreateAndStartKubesharkTapperSyncer(ctx context.Context, kubernetesProvider
 }
 
 func (tapperSyncer *KubesharkTapperSyncer) watchTapperPods() {
-	kubesharkResourceRegex := regexp.MustCompile(fmt.Sprintf("^%s.*", TapperPodName))
-	podWatchHelper := NewPodWatchHelper(tapperSyncer.kubernetesProvider, kubesharkResourceRegex)
+	podWatchHelper := NewPodWatchHelper(tapperSyncer.kubernetesProvider, TapperPodRegex())
 	eventChan, errorChan := FilteredWatch(tapperSyncer.context, podWatchHelper, []string{tapperSyncer.config.KubesharkResourcesNamespace}, podWatchHelper)
 
 	for {
@@ -95,6 +100,10 @@ func (tapperSyncer *KubesharkTapperSyncer) watchTapperPods() {
 				continue
 			}
 
+			if GetInstance(pod.Labels) != Instance {
+				continue
+			}
+
 			log.Printf("Watching tapper pods loop, tapper: %v, node: %v, status: %v", pod.Name, pod.Spec.NodeName, pod.Status.Phase)
 			if pod.Spec.NodeName != "" {
 				tapperStatus := models.TapperStatus{TapperName: pod.Name, NodeName: pod.Spec.NodeName, Status: string(pod.Status.Phase)}
@@ -116,8 +125,7 @@ func (tapperSyncer *KubesharkTapperSyncer) watchTapperPods() {
 }
 
 func (tapperSyncer *KubesharkTapperSyncer) watchTapperEvents() {
-	kubesharkResourceRegex := regexp.MustCompile(fmt.Sprintf("^%s.*", TapperPodName))
-	eventWatchHelper := NewEventWatchHelper(tapperSyncer.kubernetesProvider, kubesharkResourceRegex, "pod")
+	eventWatchHelper := NewEventWatchHelper(tapperSyncer.kubernetesProvider, TapperEventRegex(), "pod")
 	eventChan, errorChan := FilteredWatch(tapperSyncer.context, eventWatchHelper, []string{tapperSyncer.config.KubesharkResourcesNamespace}, eventWatchHelper)
 
 	for {
@@ -150,6 +158,10 @@ func (tapperSyncer *KubesharkTapperSyncer) watchTapperEvents() {
 				continue
 			}
 
+			if GetInstance(pod.Labels) != Instance {
+				continue
+			}
+
 			nodeName := ""
 			if event.Reason != "FailedScheduling" {
 				nodeName = pod.Spec.NodeName
@@ -275,7 +287,12 @@ func (tapperSyncer *KubesharkTapperSyncer) updateCurrentlyTappedPods() (err erro
 	if matchingPods, err := tapperSyncer.kubernetesProvider.ListAllRunningPodsMatchingRegex(tapperSyncer.context, &tapperSyncer.config.PodFilterRegex, tapperSyncer.config.TargetNamespaces); err != nil {
 		return err, false
 	} else {
-		podsToTap := excludeKubesharkPods(matchingPods)
+		podsToTap, untappablePods := splitPodsByNodeSupport(excludeKubesharkPods(matchingPods), tapperSyncer.getNodeUntappableReasons(matchingPods))
+		if !equalUntappablePods(tapperSyncer.CurrentUntappablePods, untappablePods) {
+			tapperSyncer.CurrentUntappablePods = untappablePods
+			tapperSyncer.UntappablePodsOut <- untappablePods
+		}
+
 		addedPods, removedPods := getPodArrayDiff(tapperSyncer.CurrentlyTappedPods, podsToTap)
 		for _, addedPod := range addedPods {
 			log.Printf("tapping new pod %s", addedPod.Name)
@@ -296,6 +313,28 @@ func (tapperSyncer *KubesharkTapperSyncer) updateCurrentlyTappedPods() (err erro
 	}
 }
 
+// getNodeUntappableReasons reads the info of nodes it hasn't seen yet, nodes that can't be read are assumed to be tappable
+func (tapperSyncer *KubesharkTapperSyncer) getNodeUntappableReasons(pods []core.Pod) map[string]string {
+	for _, pod := range pods {
+		nodeName := pod.Spec.NodeName
+		if _, ok := tapperSyncer.nodeUntappableReasons[nodeName]; ok || nodeName == "" {
+			continue
+		}
+
+		node, err := tapperSyncer.kubernetesProvider.GetNode(tapperSyncer.context, nodeName)
+		if err != nil {
+			log.Printf("Couldn't get node %s, assuming it is supported by the worker, err: %v", nodeName, err)
+			tapperSyncer.nodeUntappableReasons[nodeName] = ""
+			continue
+		}
+
+		log.Printf("Node %s info: %s", nodeName, DescribeNodeInfo(node))
+		tapperSyncer.nodeUntappableReasons[nodeName] = GetNodeUntappableReason(node)
+	}
+
+	return tapperSyncer.nodeUntappableReasons
+}
+
 func (tapperSyncer *KubesharkTapperSyncer) updateKubesharkTappers() error {
 	nodesToTap := make([]string, len(tapperSyncer.nodeToTappedPodMap))
 	i := 0
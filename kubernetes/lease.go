@@ -0,0 +1,154 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	coordination "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	AnnotationHolderUser     = "kubeshark.co/holder-user"
+	AnnotationHolderHost     = "kubeshark.co/holder-host"
+	AnnotationHolderKubeUser = "kubeshark.co/holder-kube-user"
+	AnnotationHolderSession  = "kubeshark.co/holder-session"
+	AnnotationDetached       = "kubeshark.co/detached"
+)
+
+// SessionHolder identifies who runs a tap session, it is recorded on the lease that locks the session.
+// Session tells apart the runs of a user on a host, e.g. in two terminals.
+type SessionHolder struct {
+	User      string
+	Host      string
+	Session   string
+	KubeUser  string
+	StartTime time.Time
+	Detached  bool
+}
+
+func (holder *SessionHolder) Identity() string {
+	return fmt.Sprintf("%s@%s/%s", holder.User, holder.Host, holder.Session)
+}
+
+func (holder *SessionHolder) String() string {
+	description := holder.Identity()
+	if holder.KubeUser != "" {
+		description = fmt.Sprintf("%s (kube user %s)", description, holder.KubeUser)
+	}
+	if !holder.StartTime.IsZero() {
+		description = fmt.Sprintf("%s since %s", description, holder.StartTime.Local().Format(time.RFC1123))
+	}
+	if holder.Detached {
+		description = fmt.Sprintf("%s, detached", description)
+	}
+	return description
+}
+
+// LeaseLostError is returned when renewing a lease another holder took over.
+type LeaseLostError struct {
+	Holder *SessionHolder
+}
+
+func (err *LeaseLostError) Error() string {
+	return fmt.Sprintf("the session was taken over by %s", err.Holder)
+}
+
+func (provider *Provider) buildLease(leaseName string, holder *SessionHolder, duration time.Duration) *coordination.Lease {
+	identity := holder.Identity()
+	durationSeconds := int32(duration.Seconds())
+	acquireTime := metav1.NewMicroTime(holder.StartTime)
+	renewTime := metav1.NewMicroTime(time.Now())
+	return &coordination.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: leaseName,
+			Labels: map[string]string{
+				LabelManagedBy: provider.managedBy,
+				LabelCreatedBy: provider.createdBy,
+				LabelInstance:  Instance,
+			},
+			Annotations: map[string]string{
+				AnnotationHolderUser:     holder.User,
+				AnnotationHolderHost:     holder.Host,
+				AnnotationHolderKubeUser: holder.KubeUser,
+				AnnotationHolderSession:  holder.Session,
+				AnnotationDetached:       strconv.FormatBool(holder.Detached),
+			},
+		},
+		Spec: coordination.LeaseSpec{
+			HolderIdentity:       &identity,
+			LeaseDurationSeconds: &durationSeconds,
+			AcquireTime:          &acquireTime,
+			RenewTime:            &renewTime,
+		},
+	}
+}
+
+// CreateLease creates the lease that locks the session, it fails with AlreadyExists when another session holds it.
+func (provider *Provider) CreateLease(ctx context.Context, namespace string, leaseName string, holder *SessionHolder, duration time.Duration) (*coordination.Lease, error) {
+	return provider.clientSet.CoordinationV1().Leases(namespace).Create(ctx, provider.buildLease(leaseName, holder, duration), metav1.CreateOptions{})
+}
+
+// TakeOverLease replaces the holder of an existing lease, it fails with Conflict when the lease changed since
+// it was read, e.g. when another session took it over first.
+func (provider *Provider) TakeOverLease(ctx context.Context, lease *coordination.Lease, holder *SessionHolder, duration time.Duration) (*coordination.Lease, error) {
+	takenOverLease := provider.buildLease(lease.Name, holder, duration)
+	takenOverLease.ResourceVersion = lease.ResourceVersion
+	return provider.clientSet.CoordinationV1().Leases(lease.Namespace).Update(ctx, takenOverLease, metav1.UpdateOptions{})
+}
+
+func (provider *Provider) GetLease(ctx context.Context, namespace string, leaseName string) (*coordination.Lease, error) {
+	return provider.clientSet.CoordinationV1().Leases(namespace).Get(ctx, leaseName, metav1.GetOptions{})
+}
+
+// RenewLease moves the renew time of a lease as long as it is still held by the given identity.
+func (provider *Provider) RenewLease(ctx context.Context, namespace string, leaseName string, identity string) error {
+	lease, err := provider.GetLease(ctx, namespace, leaseName)
+	if err != nil {
+		return err
+	}
+
+	if holder := GetLeaseHolder(lease); holder.Identity() != identity {
+		return &LeaseLostError{Holder: holder}
+	}
+
+	renewTime := metav1.NewMicroTime(time.Now())
+	lease.Spec.RenewTime = &renewTime
+	_, err = provider.clientSet.CoordinationV1().Leases(namespace).Update(ctx, lease, metav1.UpdateOptions{})
+	return err
+}
+
+func (provider *Provider) RemoveLease(ctx context.Context, namespace string, leaseName string) error {
+	err := provider.clientSet.CoordinationV1().Leases(namespace).Delete(ctx, leaseName, metav1.DeleteOptions{})
+	return provider.handleRemovalError(err)
+}
+
+func GetLeaseHolder(lease *coordination.Lease) *SessionHolder {
+	holder := &SessionHolder{
+		User:     lease.Annotations[AnnotationHolderUser],
+		Host:     lease.Annotations[AnnotationHolderHost],
+		Session:  lease.Annotations[AnnotationHolderSession],
+		KubeUser: lease.Annotations[AnnotationHolderKubeUser],
+		Detached: lease.Annotations[AnnotationDetached] == strconv.FormatBool(true),
+	}
+	if lease.Spec.AcquireTime != nil {
+		holder.StartTime = lease.Spec.AcquireTime.Time
+	}
+	return holder
+}
+
+// IsLeaseExpired reports whether the holder of an attached session stopped renewing the lease,
+// detached sessions are never renewed so their lease never expires.
+func IsLeaseExpired(lease *coordination.Lease) bool {
+	if GetLeaseHolder(lease).Detached {
+		return false
+	}
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+
+	expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+	return time.Now().After(expiry)
+}
@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	coordination "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newLease(renewedAgo time.Duration, duration time.Duration, detached bool) *coordination.Lease {
+	renewTime := metav1.NewMicroTime(time.Now().Add(-renewedAgo))
+	durationSeconds := int32(duration.Seconds())
+	annotations := map[string]string{}
+	if detached {
+		annotations[AnnotationDetached] = "true"
+	}
+
+	return &coordination.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "ks-lease", Annotations: annotations},
+		Spec: coordination.LeaseSpec{
+			RenewTime:            &renewTime,
+			LeaseDurationSeconds: &durationSeconds,
+		},
+	}
+}
+
+func TestIsLeaseExpired(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Lease   *coordination.Lease
+		Expired bool
+	}{
+		{Name: "renewed", Lease: newLease(time.Second, time.Minute, false), Expired: false},
+		{Name: "not renewed", Lease: newLease(2*time.Minute, time.Minute, false), Expired: true},
+		{Name: "detached", Lease: newLease(time.Hour, time.Minute, true), Expired: false},
+		{Name: "never renewed", Lease: &coordination.Lease{}, Expired: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if expired := IsLeaseExpired(test.Lease); expired != test.Expired {
+				t.Errorf("unexpected lease expiry - lease: %v, expected: %v, actual: %v", test.Name, test.Expired, expired)
+			}
+		})
+	}
+}
+
+func TestGetLeaseHolder(t *testing.T) {
+	holder := &SessionHolder{User: "admin", Host: "laptop", Session: "4242-0a1b2c3d", KubeUser: "admin@cluster", StartTime: time.Unix(1660000000, 0), Detached: true}
+	otherHolder := &SessionHolder{User: "admin", Host: "laptop", Session: "4343-4e5f6a7b"}
+
+	leaseHolder := GetLeaseHolder((&Provider{}).buildLease("ks-lease", holder, time.Minute))
+	if leaseHolder.Identity() != holder.Identity() || leaseHolder.KubeUser != holder.KubeUser || !leaseHolder.StartTime.Equal(holder.StartTime) || !leaseHolder.Detached {
+		t.Errorf("unexpected lease holder - expected: %v, actual: %v", holder, leaseHolder)
+	}
+	if leaseHolder.Identity() == otherHolder.Identity() {
+		t.Errorf("unexpected identity shared by two sessions of a user on a host - %v", leaseHolder.Identity())
+	}
+}
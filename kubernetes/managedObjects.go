@@ -0,0 +1,256 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	KindCronJob                    = "CronJob"
+	KindDaemonSet                  = "DaemonSet"
+	KindPod                        = "Pod"
+	KindIngress                    = "Ingress"
+	KindService                    = "Service"
+	KindNetworkPolicy              = "NetworkPolicy"
+	KindConfigMap                  = "ConfigMap"
+	KindLease                      = "Lease"
+	KindSecret                     = "Secret"
+	KindPersistentVolumeClaim      = "PersistentVolumeClaim"
+	KindRoleBinding                = "RoleBinding"
+	KindRole                       = "Role"
+	KindServiceAccount             = "ServiceAccount"
+	KindClusterRoleBinding         = "ClusterRoleBinding"
+	KindClusterRole                = "ClusterRole"
+	KindSecurityContextConstraints = "SecurityContextConstraints"
+	KindNamespace                  = "Namespace"
+)
+
+// NamespacedManagedKinds are the namespaced kinds Kubeshark creates, ordered so that owners are removed
+// before the objects they use.
+var NamespacedManagedKinds = []string{
+	KindCronJob,
+	KindDaemonSet,
+	KindPod,
+	KindIngress,
+	KindService,
+	KindNetworkPolicy,
+	KindConfigMap,
+	KindLease,
+	KindSecret,
+	KindPersistentVolumeClaim,
+	KindRoleBinding,
+	KindRole,
+	KindServiceAccount,
+}
+
+// ClusterManagedKinds are the cluster scoped kinds Kubeshark creates, the namespace goes last since removing it
+// removes whatever is left in it. SecurityContextConstraints exist on OpenShift only, so they are not listed.
+var ClusterManagedKinds = []string{
+	KindClusterRoleBinding,
+	KindClusterRole,
+	KindNamespace,
+}
+
+type ManagedObject struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Instance  string
+}
+
+func (object ManagedObject) String() string {
+	if object.Namespace == "" {
+		return fmt.Sprintf("%s %s", object.Kind, object.Name)
+	}
+	return fmt.Sprintf("%s %s in namespace %s", object.Kind, object.Name, object.Namespace)
+}
+
+type managedKind struct {
+	list   func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error)
+	remove func(ctx context.Context, namespace string, name string) error
+}
+
+func (provider *Provider) managedKinds() map[string]managedKind {
+	return map[string]managedKind{
+		KindCronJob: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				isCronJobV1Served, err := provider.IsCronJobV1Served()
+				if err != nil {
+					return nil, err
+				}
+				if !isCronJobV1Served {
+					return provider.clientSet.BatchV1beta1().CronJobs(namespace).List(ctx, listOptions)
+				}
+				return provider.clientSet.BatchV1().CronJobs(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemoveCronJob,
+		},
+		KindDaemonSet: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.AppsV1().DaemonSets(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemoveDaemonSet,
+		},
+		KindPod: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.CoreV1().Pods(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemovePod,
+		},
+		KindIngress: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.NetworkingV1().Ingresses(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemoveIngress,
+		},
+		KindService: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.CoreV1().Services(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemoveService,
+		},
+		KindNetworkPolicy: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.NetworkingV1().NetworkPolicies(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemoveNetworkPolicy,
+		},
+		KindConfigMap: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.CoreV1().ConfigMaps(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemoveConfigMap,
+		},
+		KindLease: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.CoordinationV1().Leases(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemoveLease,
+		},
+		KindSecret: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.CoreV1().Secrets(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemoveSecret,
+		},
+		KindPersistentVolumeClaim: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.CoreV1().PersistentVolumeClaims(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemovePersistentVolumeClaim,
+		},
+		KindRoleBinding: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.RbacV1().RoleBindings(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemoveRoleBinding,
+		},
+		KindRole: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.RbacV1().Roles(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemoveRole,
+		},
+		KindServiceAccount: {
+			list: func(ctx context.Context, namespace string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.CoreV1().ServiceAccounts(namespace).List(ctx, listOptions)
+			},
+			remove: provider.RemoveServiceAccount,
+		},
+		KindClusterRoleBinding: {
+			list: func(ctx context.Context, _ string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.RbacV1().ClusterRoleBindings().List(ctx, listOptions)
+			},
+			remove: func(ctx context.Context, _ string, name string) error {
+				return provider.RemoveClusterRoleBinding(ctx, name)
+			},
+		},
+		KindClusterRole: {
+			list: func(ctx context.Context, _ string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.RbacV1().ClusterRoles().List(ctx, listOptions)
+			},
+			remove: func(ctx context.Context, _ string, name string) error {
+				return provider.RemoveClusterRole(ctx, name)
+			},
+		},
+		KindSecurityContextConstraints: {
+			list: func(ctx context.Context, _ string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.dynamicClient.Resource(securityContextConstraintsResource).List(ctx, listOptions)
+			},
+			remove: func(ctx context.Context, _ string, name string) error {
+				return provider.RemoveSecurityContextConstraints(ctx, name)
+			},
+		},
+		KindNamespace: {
+			list: func(ctx context.Context, _ string, listOptions metav1.ListOptions) (runtime.Object, error) {
+				return provider.clientSet.CoreV1().Namespaces().List(ctx, listOptions)
+			},
+			remove: func(ctx context.Context, _ string, name string) error {
+				return provider.RemoveNamespace(ctx, name)
+			},
+		},
+	}
+}
+
+// ListManagedObjects lists the objects of a kind that carry the managed-by label, of all instances, in namespace
+// or in all namespaces when namespace is K8sAllNamespaces. The namespace is ignored for cluster scoped kinds.
+func (provider *Provider) ListManagedObjects(ctx context.Context, kind string, namespace string) ([]ManagedObject, error) {
+	managedKind, ok := provider.managedKinds()[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown kind %s", kind)
+	}
+
+	listOptions := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", LabelManagedBy, provider.managedBy),
+	}
+	list, err := managedKind.list(ctx, namespace, listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]ManagedObject, 0, len(items))
+	for _, item := range items {
+		accessor, err := meta.Accessor(item)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, ManagedObject{
+			Kind:      kind,
+			Namespace: accessor.GetNamespace(),
+			Name:      accessor.GetName(),
+			Instance:  GetInstance(accessor.GetLabels()),
+		})
+	}
+
+	return objects, nil
+}
+
+func (provider *Provider) RemoveManagedObject(ctx context.Context, object ManagedObject) error {
+	managedKind, ok := provider.managedKinds()[object.Kind]
+	if !ok {
+		return fmt.Errorf("unknown kind %s", object.Kind)
+	}
+
+	return managedKind.remove(ctx, object.Namespace, object.Name)
+}
+
+func (provider *Provider) ListManagedPods(ctx context.Context, namespace string) ([]core.Pod, error) {
+	listOptions := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", LabelManagedBy, provider.managedBy),
+	}
+	pods, err := provider.clientSet.CoreV1().Pods(namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
@@ -0,0 +1,76 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubeshark/kubeshark/utils"
+	core "k8s.io/api/core/v1"
+)
+
+const (
+	NodeOperatingSystemLabel = "kubernetes.io/os"
+	NodeArchitectureLabel    = "kubernetes.io/arch"
+)
+
+// The worker image is published for these platforms only, a worker scheduled anywhere else crash-loops.
+var (
+	SupportedNodeOperatingSystems = []string{"linux"}
+	SupportedNodeArchitectures    = []string{"amd64", "arm64"}
+)
+
+type UntappablePod struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	NodeName  string `json:"nodeName"`
+	Reason    string `json:"reason"`
+}
+
+func DescribeNodeInfo(node *core.Node) string {
+	nodeInfo := node.Status.NodeInfo
+	return fmt.Sprintf("%s/%s, kernel: %s, container runtime: %s", nodeInfo.OperatingSystem, nodeInfo.Architecture, nodeInfo.KernelVersion, nodeInfo.ContainerRuntimeVersion)
+}
+
+// GetNodeUntappableReason returns an empty string when a worker can run on the node.
+func GetNodeUntappableReason(node *core.Node) string {
+	nodeInfo := node.Status.NodeInfo
+
+	if !utils.Contains(SupportedNodeOperatingSystems, nodeInfo.OperatingSystem) {
+		return fmt.Sprintf("node %s runs %s, the worker supports only %s", node.Name, nodeInfo.OperatingSystem, strings.Join(SupportedNodeOperatingSystems, ", "))
+	}
+
+	if !utils.Contains(SupportedNodeArchitectures, nodeInfo.Architecture) {
+		return fmt.Sprintf("node %s is %s, the worker supports only %s", node.Name, nodeInfo.Architecture, strings.Join(SupportedNodeArchitectures, ", "))
+	}
+
+	return ""
+}
+
+func splitPodsByNodeSupport(pods []core.Pod, nodeUntappableReasons map[string]string) (tappablePods []core.Pod, untappablePods []UntappablePod) {
+	tappablePods = make([]core.Pod, 0)
+	untappablePods = make([]UntappablePod, 0)
+
+	for _, pod := range pods {
+		if reason := nodeUntappableReasons[pod.Spec.NodeName]; reason != "" {
+			untappablePods = append(untappablePods, UntappablePod{Name: pod.Name, Namespace: pod.Namespace, NodeName: pod.Spec.NodeName, Reason: reason})
+		} else {
+			tappablePods = append(tappablePods, pod)
+		}
+	}
+
+	return tappablePods, untappablePods
+}
+
+func equalUntappablePods(untappablePods1 []UntappablePod, untappablePods2 []UntappablePod) bool {
+	if len(untappablePods1) != len(untappablePods2) {
+		return false
+	}
+
+	for i := range untappablePods1 {
+		if untappablePods1[i] != untappablePods2[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newNode(name string, operatingSystem string, architecture string) *core.Node {
+	return &core.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: core.NodeStatus{
+			NodeInfo: core.NodeSystemInfo{
+				OperatingSystem: operatingSystem,
+				Architecture:    architecture,
+			},
+		},
+	}
+}
+
+func TestGetNodeUntappableReason(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Node     *core.Node
+		Tappable bool
+	}{
+		{Name: "linux amd64", Node: newNode("node-1", "linux", "amd64"), Tappable: true},
+		{Name: "linux arm64", Node: newNode("node-2", "linux", "arm64"), Tappable: true},
+		{Name: "windows amd64", Node: newNode("node-3", "windows", "amd64"), Tappable: false},
+		{Name: "linux s390x", Node: newNode("node-4", "linux", "s390x"), Tappable: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			reason := GetNodeUntappableReason(test.Node)
+			if test.Tappable && reason != "" {
+				t.Errorf("unexpected untappable reason - node: %v, reason: %v", test.Node.Name, reason)
+			} else if !test.Tappable && reason == "" {
+				t.Errorf("expected untappable reason - node: %v", test.Node.Name)
+			}
+		})
+	}
+}
+
+func TestSplitPodsByNodeSupport(t *testing.T) {
+	pods := []core.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "default"}, Spec: core.PodSpec{NodeName: "linux-node"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "default"}, Spec: core.PodSpec{NodeName: "windows-node"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod-3", Namespace: "default"}, Spec: core.PodSpec{NodeName: "unknown-node"}},
+	}
+	nodeUntappableReasons := map[string]string{
+		"linux-node":   "",
+		"windows-node": "node windows-node runs windows, the worker supports only linux",
+	}
+
+	tappablePods, untappablePods := splitPodsByNodeSupport(pods, nodeUntappableReasons)
+
+	if len(tappablePods) != 2 || tappablePods[0].Name != "pod-1" || tappablePods[1].Name != "pod-3" {
+		t.Errorf("unexpected tappable pods - %v", tappablePods)
+	}
+
+	if len(untappablePods) != 1 || untappablePods[0].Name != "pod-2" || untappablePods[0].Reason != nodeUntappableReasons["windows-node"] {
+		t.Errorf("unexpected untappable pods - %v", untappablePods)
+	}
+}
@@ -0,0 +1,111 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const openShiftSecurityApiGroup = "security.openshift.io"
+
+var securityContextConstraintsResource = schema.GroupVersionResource{
+	Group:    openShiftSecurityApiGroup,
+	Version:  "v1",
+	Resource: "securitycontextconstraints",
+}
+
+// IsOpenShift reports whether the cluster serves the OpenShift security API group,
+// in which case the worker needs a dedicated SecurityContextConstraints to run.
+func (provider *Provider) IsOpenShift() (bool, error) {
+	groups, err := provider.clientSet.Discovery().ServerGroups()
+	if err != nil {
+		return false, err
+	}
+
+	for _, group := range groups.Groups {
+		if group.Name == openShiftSecurityApiGroup {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (provider *Provider) ApplyKubesharkSecurityContextConstraints(ctx context.Context, namespace string, serviceAccountName string, sccName string, version string) error {
+	scc := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": securityContextConstraintsResource.GroupVersion().String(),
+			"kind":       "SecurityContextConstraints",
+			"metadata": map[string]interface{}{
+				"name": sccName,
+				"labels": map[string]interface{}{
+					"kubeshark-cli-version": version,
+					LabelManagedBy:          provider.managedBy,
+					LabelCreatedBy:          provider.createdBy,
+					LabelInstance:           Instance,
+				},
+			},
+			// The worker runs with hostNetwork, reads the host procfs/sysfs through hostPath volumes
+			// and adds the capabilities listed in ApplyKubesharkTapperDaemonSet.
+			"allowHostNetwork":         true,
+			"allowHostPorts":           true,
+			"allowHostPID":             false,
+			"allowHostIPC":             false,
+			"allowHostDirVolumePlugin": true,
+			"allowPrivilegedContainer": false,
+			"allowedCapabilities": []interface{}{
+				"NET_RAW",
+				"NET_ADMIN",
+				"SYS_ADMIN",
+				"SYS_PTRACE",
+				"DAC_OVERRIDE",
+				"SYS_RESOURCE",
+			},
+			"readOnlyRootFilesystem": false,
+			"runAsUser":              map[string]interface{}{"type": "RunAsAny"},
+			"seLinuxContext":         map[string]interface{}{"type": "RunAsAny"},
+			"fsGroup":                map[string]interface{}{"type": "RunAsAny"},
+			"supplementalGroups":     map[string]interface{}{"type": "RunAsAny"},
+			"volumes": []interface{}{
+				"configMap",
+				"downwardAPI",
+				"emptyDir",
+				"hostPath",
+				"persistentVolumeClaim",
+				"projected",
+				"secret",
+			},
+			"users": []interface{}{
+				fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccountName),
+			},
+		},
+	}
+
+	data, err := scc.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	_, err = provider.dynamicClient.Resource(securityContextConstraintsResource).Patch(ctx, sccName, types.ApplyPatchType, data, applyPatchOptions())
+	return err
+}
+
+func (provider *Provider) DoesSecurityContextConstraintsExist(ctx context.Context, name string) (bool, error) {
+	scc, err := provider.dynamicClient.Resource(securityContextConstraintsResource).Get(ctx, name, metav1.GetOptions{})
+	return provider.doesResourceExist(scc, err)
+}
+
+func (provider *Provider) RemoveSecurityContextConstraints(ctx context.Context, name string) error {
+	err := provider.dynamicClient.Resource(securityContextConstraintsResource).Delete(ctx, name, metav1.DeleteOptions{})
+	return provider.handleRemovalError(err)
+}
+
+func (provider *Provider) ListManagedSecurityContextConstraints(ctx context.Context) (*unstructured.UnstructuredList, error) {
+	listOptions := metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", LabelManagedBy, provider.managedBy),
+	}
+	return provider.dynamicClient.Resource(securityContextConstraintsResource).List(ctx, listOptions)
+}
@@ -0,0 +1,178 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/portforward"
+)
+
+const (
+	portForwardHealthCheckInterval = 30 * time.Second
+	// portForwardProbeTimeout is how long a probe connection has to stay open to count as healthy, a broken
+	// port-forward closes the connections it accepts right away
+	portForwardProbeTimeout = 2 * time.Second
+)
+
+// StartPodPortForward forwards srcPort on host to dstPort of the running pod that matches podRegex. When the
+// forwarded pod goes away, e.g. because it was replaced, it keeps forwarding to the pod that replaced it. The
+// forwarding is checked periodically, since a broken connection to the API server doesn't always end it.
+func StartPodPortForward(ctx context.Context, kubernetesProvider *Provider, host string, namespace string, podRegex *regexp.Regexp, srcPort uint16, dstPort uint16) error {
+	forward, err := startPodPortForward(ctx, kubernetesProvider, host, namespace, podRegex, srcPort, dstPort)
+	if err != nil {
+		return err
+	}
+
+	go followPodPortForward(ctx, kubernetesProvider, host, namespace, podRegex, srcPort, dstPort, forward)
+	return nil
+}
+
+func followPodPortForward(ctx context.Context, kubernetesProvider *Provider, host string, namespace string, podRegex *regexp.Regexp, srcPort uint16, dstPort uint16, forward *podPortForward) {
+	healthCheckTicker := time.NewTicker(portForwardHealthCheckInterval)
+	defer healthCheckTicker.Stop()
+
+	backoff := &reconnectBackoff{}
+	backoff.connected()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-healthCheckTicker.C:
+			err := forward.checkHealth(ctx, kubernetesProvider, host, namespace, srcPort)
+			if err == nil {
+				continue
+			} else if ctx.Err() != nil {
+				return
+			}
+			log.Printf("The port-forward to pod %s is unhealthy, reconnecting, err: %v", forward.podName, err)
+			forward.stop()
+			// The port is free for the next forwarding only once this one ended
+			<-forward.done
+		case err := <-forward.done:
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Lost the port-forward to pod %s, reconnecting, err: %v", forward.podName, err)
+		}
+
+		for {
+			if !backoff.wait(ctx) {
+				return
+			}
+
+			var err error
+			if forward, err = startPodPortForward(ctx, kubernetesProvider, host, namespace, podRegex, srcPort, dstPort); err == nil {
+				backoff.connected()
+				break
+			}
+			log.Printf("Failed to reconnect the port-forward to pod %s, retrying, err: %v", podRegex, err)
+		}
+	}
+}
+
+type podPortForward struct {
+	podName  string
+	done     <-chan error
+	stopChan chan struct{}
+	stopOnce sync.Once
+}
+
+func (forward *podPortForward) stop() {
+	forward.stopOnce.Do(func() {
+		close(forward.stopChan)
+	})
+}
+
+// checkHealth returns an error when the forwarded pod stopped running or the forwarding doesn't hold a
+// connection anymore.
+func (forward *podPortForward) checkHealth(ctx context.Context, kubernetesProvider *Provider, host string, namespace string, srcPort uint16) error {
+	pod, err := kubernetesProvider.GetPod(ctx, namespace, forward.podName)
+	if err != nil {
+		return err
+	} else if pod.DeletionTimestamp != nil || pod.Status.Phase != core.PodRunning {
+		return fmt.Errorf("pod %s isn't running", forward.podName)
+	}
+
+	return probePortForward(host, srcPort)
+}
+
+// probePortForward connects to the forwarded port without sending anything, the forwarded servers wait for a
+// request, so a connection that ends before the timeout means the forwarding is broken.
+func probePortForward(host string, port uint16) error {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(int(port))), portForwardProbeTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetReadDeadline(time.Now().Add(portForwardProbeTimeout)); err != nil {
+		return err
+	}
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			return nil
+		}
+		return err
+	}
+
+	return nil
+}
+
+// startPodPortForward starts forwarding to the first running pod that matches podRegex, the done channel of the
+// returned forward is closed once the forwarding ends.
+func startPodPortForward(ctx context.Context, kubernetesProvider *Provider, host string, namespace string, podRegex *regexp.Regexp, srcPort uint16, dstPort uint16) (*podPortForward, error) {
+	pods, err := kubernetesProvider.ListAllRunningPodsMatchingRegex(ctx, podRegex, []string{namespace})
+	if err != nil {
+		return nil, err
+	} else if len(pods) == 0 {
+		return nil, fmt.Errorf("didn't find a running pod matching %s to port-forward", podRegex)
+	}
+
+	podName := pods[0].Name
+	log.Printf("Starting port-forward - namespace: [%v], pod name: [%s], port: [%d:%d]", namespace, podName, srcPort, dstPort)
+
+	dialer, err := getHttpDialer(kubernetesProvider, namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+
+	stopChan, readyChan := make(chan struct{}), make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{host}, []string{fmt.Sprintf("%d:%d", srcPort, dstPort)}, stopChan, readyChan, io.Discard, io.Discard)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- forwarder.ForwardPorts()
+		close(done)
+	}()
+
+	forward := &podPortForward{podName: podName, done: done, stopChan: stopChan}
+	select {
+	case <-readyChan:
+	case err := <-done:
+		if err == nil {
+			err = fmt.Errorf("port-forward to pod %s ended before it was ready", podName)
+		}
+		return nil, err
+	case <-ctx.Done():
+		forward.stop()
+		return nil, ctx.Err()
+	}
+
+	go func() {
+		<-ctx.Done()
+		forward.stop()
+	}()
+
+	return forward, nil
+}
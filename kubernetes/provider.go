@@ -9,6 +9,7 @@ import (
 	"io"
 	"log"
 	"net/url"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -25,12 +26,14 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/apimachinery/pkg/watch"
 	applyconfapp "k8s.io/client-go/applyconfigurations/apps/v1"
 	applyconfcore "k8s.io/client-go/applyconfigurations/core/v1"
 	applyconfmeta "k8s.io/client-go/applyconfigurations/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/rest"
@@ -41,8 +44,10 @@ import (
 
 type Provider struct {
 	clientSet        *kubernetes.Clientset
+	dynamicClient    dynamic.Interface
 	kubernetesConfig clientcmd.ClientConfig
 	clientConfig     rest.Config
+	contextName      string
 	managedBy        string
 	createdBy        string
 }
@@ -78,12 +83,20 @@ func NewProvider(kubeConfigPath string, contextName string) (*Provider, error) {
 			"you can set alternative kube config file path by adding the kube-config-path field to the kubeshark config file, err:  %w", kubeConfigPath, err)
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(restClientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error while using kube config (%s)\n"+
+			"you can set alternative kube config file path by adding the kube-config-path field to the kubeshark config file, err:  %w", kubeConfigPath, err)
+	}
+
 	log.Printf("K8s client config, host: %s, api path: %s, user agent: %s", restClientConfig.Host, restClientConfig.APIPath, restClientConfig.UserAgent)
 
 	return &Provider{
 		clientSet:        clientSet,
+		dynamicClient:    dynamicClient,
 		kubernetesConfig: kubernetesConfig,
 		clientConfig:     *restClientConfig,
+		contextName:      contextName,
 		managedBy:        LabelValueKubeshark,
 		createdBy:        LabelValueKubeshark,
 	}, nil
@@ -99,9 +112,14 @@ func NewProviderInCluster() (*Provider, error) {
 	if err != nil {
 		return nil, err
 	}
+	dynamicClient, err := dynamic.NewForConfig(restClientConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Provider{
 		clientSet:        clientSet,
+		dynamicClient:    dynamicClient,
 		kubernetesConfig: nil, // not relevant in cluster
 		clientConfig:     *restClientConfig,
 		managedBy:        LabelValueKubeshark,
@@ -117,6 +135,28 @@ func (provider *Provider) CurrentNamespace() (string, error) {
 	return ns, err
 }
 
+// GetKubeUser returns the name of the kube config user the provider authenticates as, or an empty string
+// when it isn't known, e.g. in cluster.
+func (provider *Provider) GetKubeUser() string {
+	if provider.kubernetesConfig == nil {
+		return ""
+	}
+
+	rawConfig, err := provider.kubernetesConfig.RawConfig()
+	if err != nil {
+		return ""
+	}
+
+	contextName := provider.contextName
+	if contextName == "" {
+		contextName = rawConfig.CurrentContext
+	}
+	if kubeContext, ok := rawConfig.Contexts[contextName]; ok {
+		return kubeContext.AuthInfo
+	}
+	return ""
+}
+
 func (provider *Provider) WaitUtilNamespaceDeleted(ctx context.Context, name string) error {
 	fieldSelector := fmt.Sprintf("metadata.name=%s", name)
 	var limit int64 = 1
@@ -157,17 +197,22 @@ func (provider *Provider) WaitUtilNamespaceDeleted(ctx context.Context, name str
 	return err
 }
 
-func (provider *Provider) CreateNamespace(ctx context.Context, name string) (*core.Namespace, error) {
+func (provider *Provider) ApplyNamespace(ctx context.Context, name string) (*core.Namespace, error) {
 	namespaceSpec := &core.Namespace{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: name,
 			Labels: map[string]string{
 				LabelManagedBy: provider.managedBy,
 				LabelCreatedBy: provider.createdBy,
+				LabelInstance:  Instance,
 			},
 		},
 	}
-	return provider.clientSet.CoreV1().Namespaces().Create(ctx, namespaceSpec, metav1.CreateOptions{})
+	data, err := newApplyPatch(namespaceSpec, core.SchemeGroupVersion.WithKind("Namespace"))
+	if err != nil {
+		return nil, err
+	}
+	return provider.clientSet.CoreV1().Namespaces().Patch(ctx, name, types.ApplyPatchType, data, applyPatchOptions())
 }
 
 type HubOptions struct {
@@ -176,34 +221,31 @@ type HubOptions struct {
 	PodImage              string
 	KratosImage           string
 	KetoImage             string
+	AuthResources         models.Resources
+	AuthSecretName        string
 	ServiceAccountName    string
 	IsNamespaceRestricted bool
 	MaxEntriesDBSizeBytes int64
 	Resources             models.Resources
+	BasenineResources     models.Resources
 	ImagePullPolicy       core.PullPolicy
 	LogLevel              logging.Level
 	Profiler              bool
+	// WaitForRestore starts basenine once a snapshot is restored into its persistent volume claim
+	WaitForRestore bool
 }
 
 func (provider *Provider) BuildHubPod(opts *HubOptions, mountVolumeClaim bool, volumeClaimName string, createAuthContainer bool) (*core.Pod, error) {
 	configMapVolume := &core.ConfigMapVolumeSource{}
 	configMapVolume.Name = ConfigMapName
 
-	cpuLimit, err := resource.ParseQuantity(opts.Resources.CpuLimit)
-	if err != nil {
-		return nil, fmt.Errorf("invalid cpu limit for %s container", opts.PodName)
-	}
-	memLimit, err := resource.ParseQuantity(opts.Resources.MemoryLimit)
-	if err != nil {
-		return nil, fmt.Errorf("invalid memory limit for %s container", opts.PodName)
-	}
-	cpuRequests, err := resource.ParseQuantity(opts.Resources.CpuRequests)
+	resources, err := parseResourceRequirements(opts.Resources)
 	if err != nil {
-		return nil, fmt.Errorf("invalid cpu request for %s container", opts.PodName)
+		return nil, fmt.Errorf("invalid resources for %s container, %v", opts.PodName, err)
 	}
-	memRequests, err := resource.ParseQuantity(opts.Resources.MemoryRequests)
+	basenineResources, err := parseResourceRequirements(opts.BasenineResources)
 	if err != nil {
-		return nil, fmt.Errorf("invalid memory request for %s container", opts.PodName)
+		return nil, fmt.Errorf("invalid resources for basenine container, %v", err)
 	}
 
 	command := []string{
@@ -261,19 +303,10 @@ func (provider *Provider) BuildHubPod(opts *HubOptions, mountVolumeClaim bool, v
 					Value: opts.LogLevel.String(),
 				},
 			},
-			Resources: core.ResourceRequirements{
-				Limits: core.ResourceList{
-					"cpu":    cpuLimit,
-					"memory": memLimit,
-				},
-				Requests: core.ResourceList{
-					"cpu":    cpuRequests,
-					"memory": memRequests,
-				},
-			},
+			Resources: resources,
 		},
 		{
-			Name:            "basenine",
+			Name:            BasenineContainerName,
 			Image:           opts.PodImage,
 			ImagePullPolicy: opts.ImagePullPolicy,
 			VolumeMounts:    volumeMounts,
@@ -288,16 +321,7 @@ func (provider *Provider) BuildHubPod(opts *HubOptions, mountVolumeClaim bool, v
 				SuccessThreshold: 1,
 				TimeoutSeconds:   1,
 			},
-			Resources: core.ResourceRequirements{
-				Limits: core.ResourceList{
-					"cpu":    cpuLimit,
-					"memory": memLimit,
-				},
-				Requests: core.ResourceList{
-					"cpu":    cpuRequests,
-					"memory": memRequests,
-				},
-			},
+			Resources:  basenineResources,
 			Command:    []string{"basenine"},
 			Args:       []string{"-addr", "0.0.0.0", "-port", utils.BaseninePort, "-persistent"},
 			WorkingDir: models.DataDirPath,
@@ -305,6 +329,20 @@ func (provider *Provider) BuildHubPod(opts *HubOptions, mountVolumeClaim bool, v
 	}
 
 	if createAuthContainer {
+		authResources, err := parseResourceRequirements(opts.AuthResources)
+		if err != nil {
+			return nil, fmt.Errorf("invalid resources for %s auth containers, %v", opts.PodName, err)
+		}
+		authEnvFrom := []core.EnvFromSource{
+			{
+				SecretRef: &core.SecretEnvSource{
+					LocalObjectReference: core.LocalObjectReference{
+						Name: opts.AuthSecretName,
+					},
+				},
+			},
+		}
+
 		containers = append(containers, core.Container{
 			Name:            "kratos",
 			Image:           opts.KratosImage,
@@ -323,16 +361,8 @@ func (provider *Provider) BuildHubPod(opts *HubOptions, mountVolumeClaim bool, v
 				SuccessThreshold: 1,
 				TimeoutSeconds:   1,
 			},
-			Resources: core.ResourceRequirements{
-				Limits: core.ResourceList{
-					"cpu":    cpuLimit,
-					"memory": memLimit,
-				},
-				Requests: core.ResourceList{
-					"cpu":    cpuRequests,
-					"memory": memRequests,
-				},
-			},
+			Resources: authResources,
+			EnvFrom:   authEnvFrom,
 		})
 
 		containers = append(containers, core.Container{
@@ -353,16 +383,8 @@ func (provider *Provider) BuildHubPod(opts *HubOptions, mountVolumeClaim bool, v
 				SuccessThreshold: 1,
 				TimeoutSeconds:   1,
 			},
-			Resources: core.ResourceRequirements{
-				Limits: core.ResourceList{
-					"cpu":    cpuLimit,
-					"memory": memLimit,
-				},
-				Requests: core.ResourceList{
-					"cpu":    cpuRequests,
-					"memory": memRequests,
-				},
-			},
+			Resources: authResources,
+			EnvFrom:   authEnvFrom,
 		})
 	}
 
@@ -373,6 +395,7 @@ func (provider *Provider) BuildHubPod(opts *HubOptions, mountVolumeClaim bool, v
 				"app":          opts.PodName,
 				LabelManagedBy: provider.managedBy,
 				LabelCreatedBy: provider.createdBy,
+				LabelInstance:  Instance,
 			},
 		},
 		Spec: core.PodSpec{
@@ -393,6 +416,19 @@ func (provider *Provider) BuildHubPod(opts *HubOptions, mountVolumeClaim bool, v
 		},
 	}
 
+	if mountVolumeClaim && opts.WaitForRestore {
+		restoredFile := path.Join(models.DataDirPath, RestoredFileName)
+		pod.Spec.InitContainers = []core.Container{
+			{
+				Name:            RestoreContainerName,
+				Image:           opts.PodImage,
+				ImagePullPolicy: opts.ImagePullPolicy,
+				VolumeMounts:    volumeMounts,
+				Command:         []string{"sh", "-c", fmt.Sprintf("until [ -f %[1]s ]; do sleep 1; done; rm %[1]s", restoredFile)},
+			},
+		}
+	}
+
 	//define the service account only when it exists to prevent pod crash
 	if opts.ServiceAccountName != "" {
 		pod.Spec.ServiceAccountName = opts.ServiceAccountName
@@ -400,25 +436,43 @@ func (provider *Provider) BuildHubPod(opts *HubOptions, mountVolumeClaim bool, v
 	return pod, nil
 }
 
-func (provider *Provider) BuildFrontPod(opts *HubOptions, mountVolumeClaim bool, volumeClaimName string, createAuthContainer bool) (*core.Pod, error) {
-	configMapVolume := &core.ConfigMapVolumeSource{}
-	configMapVolume.Name = ConfigMapName
-
-	cpuLimit, err := resource.ParseQuantity(opts.Resources.CpuLimit)
+func parseResourceRequirements(resources models.Resources) (core.ResourceRequirements, error) {
+	cpuLimit, err := resource.ParseQuantity(resources.CpuLimit)
+	if err != nil {
+		return core.ResourceRequirements{}, fmt.Errorf("invalid cpu limit %q", resources.CpuLimit)
+	}
+	memLimit, err := resource.ParseQuantity(resources.MemoryLimit)
 	if err != nil {
-		return nil, fmt.Errorf("invalid cpu limit for %s container", opts.PodName)
+		return core.ResourceRequirements{}, fmt.Errorf("invalid memory limit %q", resources.MemoryLimit)
 	}
-	memLimit, err := resource.ParseQuantity(opts.Resources.MemoryLimit)
+	cpuRequests, err := resource.ParseQuantity(resources.CpuRequests)
 	if err != nil {
-		return nil, fmt.Errorf("invalid memory limit for %s container", opts.PodName)
+		return core.ResourceRequirements{}, fmt.Errorf("invalid cpu request %q", resources.CpuRequests)
 	}
-	cpuRequests, err := resource.ParseQuantity(opts.Resources.CpuRequests)
+	memRequests, err := resource.ParseQuantity(resources.MemoryRequests)
 	if err != nil {
-		return nil, fmt.Errorf("invalid cpu request for %s container", opts.PodName)
+		return core.ResourceRequirements{}, fmt.Errorf("invalid memory request %q", resources.MemoryRequests)
 	}
-	memRequests, err := resource.ParseQuantity(opts.Resources.MemoryRequests)
+
+	return core.ResourceRequirements{
+		Limits: core.ResourceList{
+			"cpu":    cpuLimit,
+			"memory": memLimit,
+		},
+		Requests: core.ResourceList{
+			"cpu":    cpuRequests,
+			"memory": memRequests,
+		},
+	}, nil
+}
+
+func (provider *Provider) BuildFrontPod(opts *HubOptions, mountVolumeClaim bool, volumeClaimName string, createAuthContainer bool) (*core.Pod, error) {
+	configMapVolume := &core.ConfigMapVolumeSource{}
+	configMapVolume.Name = ConfigMapName
+
+	resources, err := parseResourceRequirements(opts.Resources)
 	if err != nil {
-		return nil, fmt.Errorf("invalid memory request for %s container", opts.PodName)
+		return nil, fmt.Errorf("invalid resources for %s container, %v", opts.PodName, err)
 	}
 
 	volumeMounts := []core.VolumeMount{}
@@ -441,16 +495,7 @@ func (provider *Provider) BuildFrontPod(opts *HubOptions, mountVolumeClaim bool,
 				SuccessThreshold: 1,
 				TimeoutSeconds:   1,
 			},
-			Resources: core.ResourceRequirements{
-				Limits: core.ResourceList{
-					"cpu":    cpuLimit,
-					"memory": memLimit,
-				},
-				Requests: core.ResourceList{
-					"cpu":    cpuRequests,
-					"memory": memRequests,
-				},
-			},
+			Resources:  resources,
 			Command:    []string{"nginx"},
 			Args:       []string{"-g", "daemon off;"},
 			WorkingDir: models.DataDirPath,
@@ -464,6 +509,7 @@ func (provider *Provider) BuildFrontPod(opts *HubOptions, mountVolumeClaim bool,
 				"app":          opts.PodName,
 				LabelManagedBy: provider.managedBy,
 				LabelCreatedBy: provider.createdBy,
+				LabelInstance:  Instance,
 			},
 		},
 		Spec: core.PodSpec{
@@ -495,13 +541,14 @@ func (provider *Provider) CreatePod(ctx context.Context, namespace string, podSp
 	return provider.clientSet.CoreV1().Pods(namespace).Create(ctx, podSpec, metav1.CreateOptions{})
 }
 
-func (provider *Provider) CreateService(ctx context.Context, namespace string, serviceName string, appLabelValue string, targetPort int, port int32, nodePort int32) (*core.Service, error) {
+func (provider *Provider) ApplyService(ctx context.Context, namespace string, serviceName string, appLabelValue string, targetPort int, port int32, serviceType core.ServiceType, nodePort int32) (*core.Service, error) {
 	service := core.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: serviceName,
 			Labels: map[string]string{
 				LabelManagedBy: provider.managedBy,
 				LabelCreatedBy: provider.createdBy,
+				LabelInstance:  Instance,
 			},
 		},
 		Spec: core.ServiceSpec{
@@ -512,11 +559,71 @@ func (provider *Provider) CreateService(ctx context.Context, namespace string, s
 					Port:       port,
 				},
 			},
-			Type:     core.ServiceTypeClusterIP,
+			Type:     serviceType,
 			Selector: map[string]string{"app": appLabelValue},
 		},
 	}
-	return provider.clientSet.CoreV1().Services(namespace).Create(ctx, &service, metav1.CreateOptions{})
+
+	// A zero node port lets kubernetes allocate one, it can't be set on a ClusterIP service at all
+	if serviceType != core.ServiceTypeClusterIP {
+		service.Spec.Ports[0].NodePort = nodePort
+	}
+
+	data, err := newApplyPatch(&service, core.SchemeGroupVersion.WithKind("Service"))
+	if err != nil {
+		return nil, err
+	}
+	return provider.clientSet.CoreV1().Services(namespace).Patch(ctx, serviceName, types.ApplyPatchType, data, applyPatchOptions())
+}
+
+func (provider *Provider) CreatePersistentVolumeClaim(ctx context.Context, namespace string, volumeClaimName string, size string, storageClassName string) (*core.PersistentVolumeClaim, error) {
+	storageRequest, err := resource.ParseQuantity(size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size for %s persistent volume claim", volumeClaimName)
+	}
+
+	volumeClaim := &core.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: volumeClaimName,
+			Labels: map[string]string{
+				LabelManagedBy: provider.managedBy,
+				LabelCreatedBy: provider.createdBy,
+				LabelInstance:  Instance,
+			},
+		},
+		Spec: core.PersistentVolumeClaimSpec{
+			AccessModes: []core.PersistentVolumeAccessMode{core.ReadWriteOnce},
+			Resources: core.ResourceRequirements{
+				Requests: core.ResourceList{
+					core.ResourceStorage: storageRequest,
+				},
+			},
+		},
+	}
+
+	// leave the storage class unset to use the cluster default
+	if storageClassName != "" {
+		volumeClaim.Spec.StorageClassName = &storageClassName
+	}
+
+	return provider.clientSet.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, volumeClaim, metav1.CreateOptions{})
+}
+
+func (provider *Provider) CreateSecret(ctx context.Context, namespace string, secretName string, data map[string][]byte) (*core.Secret, error) {
+	secret := &core.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: secretName,
+			Labels: map[string]string{
+				LabelManagedBy: provider.managedBy,
+				LabelCreatedBy: provider.createdBy,
+				LabelInstance:  Instance,
+			},
+		},
+		Type: core.SecretTypeOpaque,
+		Data: data,
+	}
+
+	return provider.clientSet.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
 }
 
 func (provider *Provider) CanI(ctx context.Context, namespace string, resource string, verb string, group string) (bool, error) {
@@ -554,11 +661,26 @@ func (provider *Provider) DoesServiceAccountExist(ctx context.Context, namespace
 	return provider.doesResourceExist(serviceAccountResource, err)
 }
 
+func (provider *Provider) DoesPodExist(ctx context.Context, namespace string, name string) (bool, error) {
+	podResource, err := provider.clientSet.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	return provider.doesResourceExist(podResource, err)
+}
+
 func (provider *Provider) DoesServiceExist(ctx context.Context, namespace string, name string) (bool, error) {
 	serviceResource, err := provider.clientSet.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
 	return provider.doesResourceExist(serviceResource, err)
 }
 
+func (provider *Provider) DoesPersistentVolumeClaimExist(ctx context.Context, namespace string, name string) (bool, error) {
+	volumeClaimResource, err := provider.clientSet.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	return provider.doesResourceExist(volumeClaimResource, err)
+}
+
+func (provider *Provider) DoesSecretExist(ctx context.Context, namespace string, name string) (bool, error) {
+	secretResource, err := provider.clientSet.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	return provider.doesResourceExist(secretResource, err)
+}
+
 func (provider *Provider) DoesClusterRoleExist(ctx context.Context, name string) (bool, error) {
 	clusterRoleResource, err := provider.clientSet.RbacV1().ClusterRoles().Get(ctx, name, metav1.GetOptions{})
 	return provider.doesResourceExist(clusterRoleResource, err)
@@ -592,7 +714,7 @@ func (provider *Provider) doesResourceExist(resource interface{}, err error) (bo
 	return resource != nil, nil
 }
 
-func (provider *Provider) CreateKubesharkRBAC(ctx context.Context, namespace string, serviceAccountName string, clusterRoleName string, clusterRoleBindingName string, version string, resources []string) error {
+func (provider *Provider) ApplyKubesharkRBAC(ctx context.Context, namespace string, serviceAccountName string, clusterRoleName string, clusterRoleBindingName string, version string, resources []string) error {
 	serviceAccount := &core.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: serviceAccountName,
@@ -600,6 +722,7 @@ func (provider *Provider) CreateKubesharkRBAC(ctx context.Context, namespace str
 				"kubeshark-cli-version": version,
 				LabelManagedBy:          provider.managedBy,
 				LabelCreatedBy:          provider.createdBy,
+				LabelInstance:           Instance,
 			},
 		},
 	}
@@ -610,6 +733,7 @@ func (provider *Provider) CreateKubesharkRBAC(ctx context.Context, namespace str
 				"kubeshark-cli-version": version,
 				LabelManagedBy:          provider.managedBy,
 				LabelCreatedBy:          provider.createdBy,
+				LabelInstance:           Instance,
 			},
 		},
 		Rules: []rbac.PolicyRule{
@@ -627,6 +751,7 @@ func (provider *Provider) CreateKubesharkRBAC(ctx context.Context, namespace str
 				"kubeshark-cli-version": version,
 				LabelManagedBy:          provider.managedBy,
 				LabelCreatedBy:          provider.createdBy,
+				LabelInstance:           Instance,
 			},
 		},
 		RoleRef: rbac.RoleRef{
@@ -642,22 +767,27 @@ func (provider *Provider) CreateKubesharkRBAC(ctx context.Context, namespace str
 			},
 		},
 	}
-	_, err := provider.clientSet.CoreV1().ServiceAccounts(namespace).Create(ctx, serviceAccount, metav1.CreateOptions{})
-	if err != nil && !k8serrors.IsAlreadyExists(err) {
+	if err := provider.applyServiceAccount(ctx, namespace, serviceAccount); err != nil {
 		return err
 	}
-	_, err = provider.clientSet.RbacV1().ClusterRoles().Create(ctx, clusterRole, metav1.CreateOptions{})
-	if err != nil && !k8serrors.IsAlreadyExists(err) {
+
+	data, err := newApplyPatch(clusterRole, rbac.SchemeGroupVersion.WithKind("ClusterRole"))
+	if err != nil {
 		return err
 	}
-	_, err = provider.clientSet.RbacV1().ClusterRoleBindings().Create(ctx, clusterRoleBinding, metav1.CreateOptions{})
-	if err != nil && !k8serrors.IsAlreadyExists(err) {
+	if _, err = provider.clientSet.RbacV1().ClusterRoles().Patch(ctx, clusterRoleName, types.ApplyPatchType, data, applyPatchOptions()); err != nil {
 		return err
 	}
-	return nil
+
+	data, err = newApplyPatch(clusterRoleBinding, rbac.SchemeGroupVersion.WithKind("ClusterRoleBinding"))
+	if err != nil {
+		return err
+	}
+	_, err = provider.clientSet.RbacV1().ClusterRoleBindings().Patch(ctx, clusterRoleBindingName, types.ApplyPatchType, data, applyPatchOptions())
+	return err
 }
 
-func (provider *Provider) CreateKubesharkRBACNamespaceRestricted(ctx context.Context, namespace string, serviceAccountName string, roleName string, roleBindingName string, version string) error {
+func (provider *Provider) ApplyKubesharkRBACNamespaceRestricted(ctx context.Context, namespace string, serviceAccountName string, roleName string, roleBindingName string, version string) error {
 	serviceAccount := &core.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: serviceAccountName,
@@ -665,6 +795,7 @@ func (provider *Provider) CreateKubesharkRBACNamespaceRestricted(ctx context.Con
 				"kubeshark-cli-version": version,
 				LabelManagedBy:          provider.managedBy,
 				LabelCreatedBy:          provider.createdBy,
+				LabelInstance:           Instance,
 			},
 		},
 	}
@@ -675,6 +806,7 @@ func (provider *Provider) CreateKubesharkRBACNamespaceRestricted(ctx context.Con
 				"kubeshark-cli-version": version,
 				LabelManagedBy:          provider.managedBy,
 				LabelCreatedBy:          provider.createdBy,
+				LabelInstance:           Instance,
 			},
 		},
 		Rules: []rbac.PolicyRule{
@@ -692,6 +824,7 @@ func (provider *Provider) CreateKubesharkRBACNamespaceRestricted(ctx context.Con
 				"kubeshark-cli-version": version,
 				LabelManagedBy:          provider.managedBy,
 				LabelCreatedBy:          provider.createdBy,
+				LabelInstance:           Instance,
 			},
 		},
 		RoleRef: rbac.RoleRef{
@@ -707,19 +840,33 @@ func (provider *Provider) CreateKubesharkRBACNamespaceRestricted(ctx context.Con
 			},
 		},
 	}
-	_, err := provider.clientSet.CoreV1().ServiceAccounts(namespace).Create(ctx, serviceAccount, metav1.CreateOptions{})
-	if err != nil && !k8serrors.IsAlreadyExists(err) {
+	if err := provider.applyServiceAccount(ctx, namespace, serviceAccount); err != nil {
 		return err
 	}
-	_, err = provider.clientSet.RbacV1().Roles(namespace).Create(ctx, role, metav1.CreateOptions{})
-	if err != nil && !k8serrors.IsAlreadyExists(err) {
+
+	data, err := newApplyPatch(role, rbac.SchemeGroupVersion.WithKind("Role"))
+	if err != nil {
 		return err
 	}
-	_, err = provider.clientSet.RbacV1().RoleBindings(namespace).Create(ctx, roleBinding, metav1.CreateOptions{})
-	if err != nil && !k8serrors.IsAlreadyExists(err) {
+	if _, err = provider.clientSet.RbacV1().Roles(namespace).Patch(ctx, roleName, types.ApplyPatchType, data, applyPatchOptions()); err != nil {
 		return err
 	}
-	return nil
+
+	data, err = newApplyPatch(roleBinding, rbac.SchemeGroupVersion.WithKind("RoleBinding"))
+	if err != nil {
+		return err
+	}
+	_, err = provider.clientSet.RbacV1().RoleBindings(namespace).Patch(ctx, roleBindingName, types.ApplyPatchType, data, applyPatchOptions())
+	return err
+}
+
+func (provider *Provider) applyServiceAccount(ctx context.Context, namespace string, serviceAccount *core.ServiceAccount) error {
+	data, err := newApplyPatch(serviceAccount, core.SchemeGroupVersion.WithKind("ServiceAccount"))
+	if err != nil {
+		return err
+	}
+	_, err = provider.clientSet.CoreV1().ServiceAccounts(namespace).Patch(ctx, serviceAccount.Name, types.ApplyPatchType, data, applyPatchOptions())
+	return err
 }
 
 func (provider *Provider) RemoveNamespace(ctx context.Context, name string) error {
@@ -767,6 +914,26 @@ func (provider *Provider) RemoveService(ctx context.Context, namespace string, s
 	return provider.handleRemovalError(err)
 }
 
+func (provider *Provider) RemovePersistentVolumeClaim(ctx context.Context, namespace string, volumeClaimName string) error {
+	err := provider.clientSet.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, volumeClaimName, metav1.DeleteOptions{})
+	return provider.handleRemovalError(err)
+}
+
+func (provider *Provider) RemoveSecret(ctx context.Context, namespace string, secretName string) error {
+	err := provider.clientSet.CoreV1().Secrets(namespace).Delete(ctx, secretName, metav1.DeleteOptions{})
+	return provider.handleRemovalError(err)
+}
+
+func (provider *Provider) RemoveIngress(ctx context.Context, namespace string, ingressName string) error {
+	err := provider.clientSet.NetworkingV1().Ingresses(namespace).Delete(ctx, ingressName, metav1.DeleteOptions{})
+	return provider.handleRemovalError(err)
+}
+
+func (provider *Provider) RemoveNetworkPolicy(ctx context.Context, namespace string, networkPolicyName string) error {
+	err := provider.clientSet.NetworkingV1().NetworkPolicies(namespace).Delete(ctx, networkPolicyName, metav1.DeleteOptions{})
+	return provider.handleRemovalError(err)
+}
+
 func (provider *Provider) RemoveDaemonSet(ctx context.Context, namespace string, daemonSetName string) error {
 	err := provider.clientSet.AppsV1().DaemonSets(namespace).Delete(ctx, daemonSetName, metav1.DeleteOptions{})
 	return provider.handleRemovalError(err)
@@ -782,7 +949,11 @@ func (provider *Provider) handleRemovalError(err error) error {
 	return err
 }
 
-func (provider *Provider) CreateConfigMap(ctx context.Context, namespace string, configMapName string, serializedKubesharkConfig string) error {
+func (provider *Provider) GetConfigMap(ctx context.Context, namespace string, configMapName string) (*core.ConfigMap, error) {
+	return provider.clientSet.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, metav1.GetOptions{})
+}
+
+func (provider *Provider) ApplyConfigMap(ctx context.Context, namespace string, configMapName string, serializedKubesharkConfig string) error {
 	configMapData := make(map[string]string)
 	configMapData[models.ConfigFileName] = serializedKubesharkConfig
 
@@ -796,14 +967,17 @@ func (provider *Provider) CreateConfigMap(ctx context.Context, namespace string,
 			Labels: map[string]string{
 				LabelManagedBy: provider.managedBy,
 				LabelCreatedBy: provider.createdBy,
+				LabelInstance:  Instance,
 			},
 		},
 		Data: configMapData,
 	}
-	if _, err := provider.clientSet.CoreV1().ConfigMaps(namespace).Create(ctx, configMap, metav1.CreateOptions{}); err != nil {
+	data, err := newApplyPatch(configMap, core.SchemeGroupVersion.WithKind("ConfigMap"))
+	if err != nil {
 		return err
 	}
-	return nil
+	_, err = provider.clientSet.CoreV1().ConfigMaps(namespace).Patch(ctx, configMapName, types.ApplyPatchType, data, applyPatchOptions())
+	return err
 }
 
 func (provider *Provider) ApplyKubesharkTapperDaemonSet(ctx context.Context, namespace string, daemonSetName string, podImage string, tapperPodName string, hubPodIp string, nodeNames []string, serviceAccountName string, resources models.Resources, imagePullPolicy core.PullPolicy, kubesharkApiFilteringOptions api.TrafficFilteringOptions, logLevel logging.Level, serviceMesh bool, tls bool, maxLiveStreams int) error {
@@ -909,8 +1083,19 @@ func (provider *Provider) ApplyKubesharkTapperDaemonSet(ctx context.Context, nam
 		nodeSelectorRequirement.WithOperator(core.NodeSelectorOpIn)
 		nodeSelectorRequirement.WithValues(nodeName)
 
+		operatingSystemRequirement := applyconfcore.NodeSelectorRequirement()
+		operatingSystemRequirement.WithKey(NodeOperatingSystemLabel)
+		operatingSystemRequirement.WithOperator(core.NodeSelectorOpIn)
+		operatingSystemRequirement.WithValues(SupportedNodeOperatingSystems...)
+
+		architectureRequirement := applyconfcore.NodeSelectorRequirement()
+		architectureRequirement.WithKey(NodeArchitectureLabel)
+		architectureRequirement.WithOperator(core.NodeSelectorOpIn)
+		architectureRequirement.WithValues(SupportedNodeArchitectures...)
+
 		nodeSelectorTerm := applyconfcore.NodeSelectorTerm()
 		nodeSelectorTerm.WithMatchFields(nodeSelectorRequirement)
+		nodeSelectorTerm.WithMatchExpressions(operatingSystemRequirement, architectureRequirement)
 		matchFields = append(matchFields, nodeSelectorTerm)
 	}
 
@@ -960,6 +1145,7 @@ func (provider *Provider) ApplyKubesharkTapperDaemonSet(ctx context.Context, nam
 		"app":          tapperPodName,
 		LabelManagedBy: provider.managedBy,
 		LabelCreatedBy: provider.createdBy,
+		LabelInstance:  Instance,
 	})
 	podTemplate.WithSpec(podSpec)
 
@@ -976,6 +1162,7 @@ func (provider *Provider) ApplyKubesharkTapperDaemonSet(ctx context.Context, nam
 		WithLabels(map[string]string{
 			LabelManagedBy: provider.managedBy,
 			LabelCreatedBy: provider.createdBy,
+			LabelInstance:  Instance,
 		}).
 		WithSpec(applyconfapp.DaemonSetSpec().WithSelector(labelSelector).WithTemplate(podTemplate))
 
@@ -1009,6 +1196,7 @@ func (provider *Provider) ResetKubesharkTapperDaemonSet(ctx context.Context, nam
 		"app":          tapperPodName,
 		LabelManagedBy: provider.managedBy,
 		LabelCreatedBy: provider.createdBy,
+		LabelInstance:  Instance,
 	})
 	podTemplate.WithSpec(podSpec)
 
@@ -1025,6 +1213,7 @@ func (provider *Provider) ResetKubesharkTapperDaemonSet(ctx context.Context, nam
 		WithLabels(map[string]string{
 			LabelManagedBy: provider.managedBy,
 			LabelCreatedBy: provider.createdBy,
+			LabelInstance:  Instance,
 		}).
 		WithSpec(applyconfapp.DaemonSetSpec().WithSelector(labelSelector).WithTemplate(podTemplate))
 
@@ -1060,6 +1249,10 @@ func (provider *Provider) GetPod(ctx context.Context, namespaces string, podName
 	return provider.clientSet.CoreV1().Pods(namespaces).Get(ctx, podName, metav1.GetOptions{})
 }
 
+func (provider *Provider) GetNode(ctx context.Context, nodeName string) (*core.Node, error) {
+	return provider.clientSet.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+}
+
 func (provider *Provider) ListAllRunningPodsMatchingRegex(ctx context.Context, regex *regexp.Regexp, namespaces []string) ([]core.Pod, error) {
 	pods, err := provider.ListAllPodsMatchingRegex(ctx, regex, namespaces)
 	if err != nil {
@@ -1084,6 +1277,15 @@ func (provider *Provider) ListPodsByAppLabel(ctx context.Context, namespaces str
 	return pods.Items, err
 }
 
+func (provider *Provider) ListServices(ctx context.Context, namespace string) ([]core.Service, error) {
+	services, err := provider.clientSet.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return services.Items, err
+}
+
 func (provider *Provider) ListAllNamespaces(ctx context.Context) ([]core.Namespace, error) {
 	namespaces, err := provider.clientSet.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
 	if err != nil {
@@ -3,10 +3,12 @@ package kubernetes
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
+	"net/http/httputil"
 	"net/url"
 	"regexp"
 	"strings"
@@ -22,7 +24,24 @@ import (
 const k8sProxyApiPrefix = "/"
 const kubesharkServicePort = 80
 
-func StartProxy(kubernetesProvider *Provider, proxyHost string, srcPort uint16, dstPort uint16, kubesharkNamespace string, kubesharkServiceName string, cancel context.CancelFunc) (*http.Server, error) {
+// LocalhostIp is where internal listeners, which only the CLI talks to, are bound.
+const LocalhostIp = "127.0.0.1"
+
+// ProxyOptions configure the local listener of a proxy, it serves TLS when Certificate is set.
+type ProxyOptions struct {
+	Host        string
+	Guard       *ProxyGuardOptions
+	Certificate *tls.Certificate
+}
+
+func (opts *ProxyOptions) GetScheme() string {
+	if opts.Certificate != nil {
+		return "https"
+	}
+	return "http"
+}
+
+func StartProxy(kubernetesProvider *Provider, proxyOptions *ProxyOptions, srcPort uint16, dstPort uint16, kubesharkNamespace string, kubesharkServiceName string, cancel context.CancelFunc) (*http.Server, error) {
 	log.Printf("Starting proxy - namespace: [%v], service name: [%s], port: [%d:%d]\n", kubesharkNamespace, kubesharkServiceName, srcPort, dstPort)
 	filter := &proxy.FilterServer{
 		AcceptPaths:   proxy.MakeRegexpArrayOrDie(proxy.DefaultPathAcceptRE),
@@ -39,17 +58,45 @@ func StartProxy(kubernetesProvider *Provider, proxyHost string, srcPort uint16,
 	mux.Handle(k8sProxyApiPrefix, getRerouteHttpHandlerKubesharkAPI(proxyHandler, kubesharkNamespace, kubesharkServiceName))
 	mux.Handle("/static/", getRerouteHttpHandlerKubesharkStatic(proxyHandler, kubesharkNamespace, kubesharkServiceName))
 
-	l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", proxyHost, int(srcPort)))
+	return serveProxy(NewProxyGuard(mux, proxyOptions.Guard), proxyOptions, srcPort, cancel)
+}
+
+// StartReverseProxy serves targetUrl, e.g. a port-forward on an internal port, on srcPort behind the same
+// guard as StartProxy.
+func StartReverseProxy(proxyOptions *ProxyOptions, srcPort uint16, targetUrl string, cancel context.CancelFunc) (*http.Server, error) {
+	target, err := url.Parse(targetUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Starting reverse proxy - target: [%s], port: [%d]", targetUrl, srcPort)
+	return serveProxy(NewProxyGuard(httputil.NewSingleHostReverseProxy(target), proxyOptions.Guard), proxyOptions, srcPort, cancel)
+}
+
+func serveProxy(handler http.Handler, proxyOptions *ProxyOptions, srcPort uint16, cancel context.CancelFunc) (*http.Server, error) {
+	l, err := net.Listen("tcp", fmt.Sprintf("%s:%d", proxyOptions.Host, int(srcPort)))
 	if err != nil {
 		return nil, err
 	}
 
 	server := &http.Server{
-		Handler: mux,
+		Handler: handler,
+	}
+	if proxyOptions.Certificate != nil {
+		server.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{*proxyOptions.Certificate},
+			MinVersion:   tls.VersionTLS12,
+		}
 	}
 
 	go func() {
-		if err := server.Serve(l); err != nil && err != http.ErrServerClosed {
+		var err error
+		if proxyOptions.Certificate != nil {
+			err = server.ServeTLS(l, "", "")
+		} else {
+			err = server.Serve(l)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Printf("Error creating proxy, %v", err)
 			cancel()
 		}
@@ -66,18 +113,28 @@ func GetLocalhostOnPort(port uint16) string {
 	return fmt.Sprintf("http://localhost:%d", port)
 }
 
+// IsPortFree reports whether port can be listened on host.
+func IsPortFree(host string, port uint16) bool {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", host, port))
+	if err != nil {
+		return false
+	}
+	_ = listener.Close()
+	return true
+}
+
+// FindFreePort returns a port on host the OS considers free.
+func FindFreePort(host string) (uint16, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:0", host))
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return uint16(listener.Addr().(*net.TCPAddr).Port), nil
+}
+
 func getRerouteHttpHandlerKubesharkAPI(proxyHandler http.Handler, kubesharkNamespace string, kubesharkServiceName string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, x-session-token")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
-
 		proxiedPath := getKubesharkHubProxiedHostAndPath(kubesharkNamespace, kubesharkServiceName)
 
 		//avoid redirecting several times
@@ -0,0 +1,140 @@
+package kubernetes
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const (
+	ProxyTokenHeader     = "X-Kubeshark-Token"
+	ProxyTokenQueryParam = "token"
+	proxyTokenCookie     = "kubeshark-token"
+	proxyTokenLength     = 32
+)
+
+// ProxyGuardOptions restrict who may use a local proxy. Browsers send the token as a cookie, which the proxy sets
+// when it's opened with the token in the query, the CLI sends it in a header. Basic auth is an alternative to
+// the token, for proxies shared on the network.
+type ProxyGuardOptions struct {
+	Token             string
+	AllowedOrigins    []string
+	BasicAuthUsername string
+	BasicAuthPassword string
+}
+
+func GenerateProxyToken() (string, error) {
+	bytes := make([]byte, proxyTokenLength)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// NewProxyGuard rejects requests from other origins, so websites open in the browser can't read the captured
+// traffic, and requests without the token or basic auth credentials.
+func NewProxyGuard(next http.Handler, opts *ProxyGuardOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if !opts.isAllowedOrigin(origin) {
+				http.Error(w, "origin not allowed", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, x-session-token, "+ProxyTokenHeader)
+			w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+			w.Header().Set("Vary", "Origin")
+		}
+
+		// Preflight requests never carry credentials
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if token := r.URL.Query().Get(ProxyTokenQueryParam); token != "" && opts.isValidToken(token) {
+			http.SetCookie(w, &http.Cookie{
+				Name:     proxyTokenCookie,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteStrictMode,
+			})
+			// Keep the token out of the browser history and of the referrer of the requests the page makes
+			query := r.URL.Query()
+			query.Del(ProxyTokenQueryParam)
+			redirectUrl := *r.URL
+			redirectUrl.RawQuery = query.Encode()
+			http.Redirect(w, r, redirectUrl.RequestURI(), http.StatusFound)
+			return
+		}
+
+		if !opts.isAuthorized(r) {
+			if opts.BasicAuthUsername != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="kubeshark"`)
+			}
+			http.Error(w, "unauthorized, open the address the kubeshark CLI printed", http.StatusUnauthorized)
+			return
+		}
+
+		removeProxyCredentials(r, opts)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (opts *ProxyGuardOptions) isAllowedOrigin(origin string) bool {
+	for _, allowedOrigin := range opts.AllowedOrigins {
+		if strings.EqualFold(origin, allowedOrigin) {
+			return true
+		}
+	}
+	return false
+}
+
+func (opts *ProxyGuardOptions) isValidToken(token string) bool {
+	return opts.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(opts.Token)) == 1
+}
+
+func (opts *ProxyGuardOptions) isValidBasicAuth(r *http.Request) bool {
+	if opts.BasicAuthUsername == "" {
+		return false
+	}
+	username, password, ok := r.BasicAuth()
+	return ok &&
+		subtle.ConstantTimeCompare([]byte(username), []byte(opts.BasicAuthUsername)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(opts.BasicAuthPassword)) == 1
+}
+
+func (opts *ProxyGuardOptions) isAuthorized(r *http.Request) bool {
+	if opts.Token == "" && opts.BasicAuthUsername == "" {
+		return true
+	}
+
+	if opts.isValidToken(r.Header.Get(ProxyTokenHeader)) {
+		return true
+	}
+	if cookie, err := r.Cookie(proxyTokenCookie); err == nil && opts.isValidToken(cookie.Value) {
+		return true
+	}
+	return opts.isValidBasicAuth(r)
+}
+
+// removeProxyCredentials keeps the credentials of the proxy from reaching the hub and front.
+func removeProxyCredentials(r *http.Request, opts *ProxyGuardOptions) {
+	r.Header.Del(ProxyTokenHeader)
+	if opts.isValidBasicAuth(r) {
+		r.Header.Del("Authorization")
+	}
+
+	cookies := r.Cookies()
+	r.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		if cookie.Name != proxyTokenCookie {
+			r.AddCookie(cookie)
+		}
+	}
+}
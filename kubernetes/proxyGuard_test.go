@@ -0,0 +1,76 @@
+package kubernetes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestProxyGuard(t *testing.T) {
+	opts := &ProxyGuardOptions{
+		Token:             "secret",
+		AllowedOrigins:    []string{"http://localhost:8899"},
+		BasicAuthUsername: "user",
+		BasicAuthPassword: "pass",
+	}
+
+	var forwarded *http.Request
+	guard := NewProxyGuard(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r
+	}), opts)
+
+	tests := []struct {
+		Name    string
+		Prepare func(r *http.Request)
+		Status  int
+	}{
+		{Name: "no credentials", Prepare: func(r *http.Request) {}, Status: http.StatusUnauthorized},
+		{Name: "header", Prepare: func(r *http.Request) { r.Header.Set(ProxyTokenHeader, "secret") }, Status: http.StatusOK},
+		{Name: "wrong header", Prepare: func(r *http.Request) { r.Header.Set(ProxyTokenHeader, "wrong") }, Status: http.StatusUnauthorized},
+		{Name: "cookie", Prepare: func(r *http.Request) { r.AddCookie(&http.Cookie{Name: proxyTokenCookie, Value: "secret"}) }, Status: http.StatusOK},
+		{Name: "basic auth", Prepare: func(r *http.Request) { r.SetBasicAuth("user", "pass") }, Status: http.StatusOK},
+		{Name: "wrong basic auth", Prepare: func(r *http.Request) { r.SetBasicAuth("user", "wrong") }, Status: http.StatusUnauthorized},
+		{Name: "foreign origin", Prepare: func(r *http.Request) {
+			r.Header.Set(ProxyTokenHeader, "secret")
+			r.Header.Set("Origin", "https://evil.example")
+		}, Status: http.StatusForbidden},
+		{Name: "own origin", Prepare: func(r *http.Request) {
+			r.AddCookie(&http.Cookie{Name: proxyTokenCookie, Value: "secret"})
+			r.Header.Set("Origin", "http://localhost:8899")
+		}, Status: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			forwarded = nil
+			request := httptest.NewRequest(http.MethodGet, "/api", nil)
+			test.Prepare(request)
+			recorder := httptest.NewRecorder()
+			guard.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.Status {
+				t.Fatalf("expected status %d, got %d", test.Status, recorder.Code)
+			}
+			if forwarded != nil && (forwarded.Header.Get(ProxyTokenHeader) != "" || forwarded.Header.Get("Cookie") != "") {
+				t.Errorf("proxy credentials were forwarded, headers: %v", forwarded.Header)
+			}
+		})
+	}
+}
+
+func TestProxyGuardTokenQuery(t *testing.T) {
+	guard := NewProxyGuard(http.NotFoundHandler(), &ProxyGuardOptions{Token: "secret"})
+
+	recorder := httptest.NewRecorder()
+	guard.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/entries?token=secret&q=1", nil))
+
+	if recorder.Code != http.StatusFound {
+		t.Fatalf("expected status %d, got %d", http.StatusFound, recorder.Code)
+	}
+	if location := recorder.Header().Get("Location"); location != "/entries?q=1" {
+		t.Errorf("expected the token to be removed from the redirect, got %s", location)
+	}
+	if cookies := recorder.Result().Cookies(); len(cookies) != 1 || cookies[0].Value != "secret" || !cookies[0].HttpOnly {
+		t.Errorf("expected an http only token cookie, got %v", cookies)
+	}
+}
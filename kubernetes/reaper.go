@@ -0,0 +1,416 @@
+package kubernetes
+
+import (
+	"context"
+	"strconv"
+
+	batch "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	core "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const reaperSchedule = "* * * * *"
+
+// reaperScript removes the instance once its lease expired. It never removes the reaper objects directly,
+// they are all owned by the reaper role, so removing that role last lets the garbage collector remove the rest.
+const reaperScript = `set -u
+lease=$(kubectl get lease "$LEASE" -n "$NAMESPACE" --ignore-not-found -o jsonpath='{.spec.renewTime} {.spec.leaseDurationSeconds}') || exit 1
+set -- $lease
+if [ $# -eq 2 ]; then
+  age=$(( $(date +%s) - $(date -d "$1" +%s) ))
+  if [ "$age" -le "$2" ]; then
+    exit 0
+  fi
+  echo "Lease $LEASE was last renewed ${age}s ago, removing Kubeshark instance $INSTANCE"
+else
+  echo "Lease $LEASE is missing, removing Kubeshark instance $INSTANCE"
+fi
+
+selector="$LABEL_MANAGED_BY=$MANAGED_BY,$LABEL_INSTANCE=$INSTANCE,$LABEL_COMPONENT!=$COMPONENT"
+kinds="daemonsets,pods,ingresses,services,networkpolicies,configmaps,secrets,rolebindings,roles,serviceaccounts"
+if [ "$KEEP_DATA" != "true" ]; then
+  kinds="$kinds,persistentvolumeclaims"
+fi
+kubectl delete "$kinds" -n "$NAMESPACE" -l "$selector" --wait=false
+kubectl delete lease "$LEASE" -n "$NAMESPACE" --ignore-not-found --wait=false
+
+if [ "$NAMESPACE_RESTRICTED" = "true" ]; then
+  kubectl delete role "$REAPER" -n "$NAMESPACE" --wait=false
+  exit 0
+fi
+
+kubectl delete clusterrolebinding "$CLUSTER_ROLE_BINDING" --ignore-not-found --wait=false
+kubectl delete clusterrole "$CLUSTER_ROLE" --ignore-not-found --wait=false
+kubectl delete securitycontextconstraints "$SCC" --ignore-not-found --wait=false 2>/dev/null
+others=$(kubectl get pods -n "$NAMESPACE" -l "$LABEL_MANAGED_BY=$MANAGED_BY,$LABEL_INSTANCE!=$INSTANCE" -o name)
+managedBy=$(kubectl get namespace "$NAMESPACE" -o jsonpath='{.metadata.labels.app\.kubernetes\.io/managed-by}')
+if [ "$KEEP_DATA" != "true" ] && [ -z "$others" ] && [ "$managedBy" = "$MANAGED_BY" ]; then
+  kubectl delete namespace "$NAMESPACE" --wait=false
+fi
+kubectl delete clusterrole "$REAPER" --wait=false
+`
+
+type ReaperOptions struct {
+	Namespace             string
+	Image                 string
+	ImagePullPolicy       core.PullPolicy
+	IsNamespaceRestricted bool
+	KeepData              bool
+}
+
+// ApplyReaper applies a CronJob that removes the instance when the CLI stopped renewing the lease without
+// cleaning up, e.g. when it was killed or the machine it ran on went to sleep.
+func (provider *Provider) ApplyReaper(ctx context.Context, opts *ReaperOptions) error {
+	owner, err := provider.applyReaperOwner(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	serviceAccount := &core.ServiceAccount{
+		ObjectMeta: provider.reaperObjectMeta(&owner),
+	}
+	if err := provider.applyServiceAccount(ctx, opts.Namespace, serviceAccount); err != nil {
+		return err
+	}
+
+	if !opts.IsNamespaceRestricted {
+		role := &rbac.Role{
+			ObjectMeta: provider.reaperObjectMeta(&owner),
+			Rules:      reaperNamespacedRules(),
+		}
+		data, err := newApplyPatch(role, rbac.SchemeGroupVersion.WithKind("Role"))
+		if err != nil {
+			return err
+		}
+		if _, err := provider.clientSet.RbacV1().Roles(opts.Namespace).Patch(ctx, ReaperName, types.ApplyPatchType, data, applyPatchOptions()); err != nil {
+			return err
+		}
+
+		clusterRoleBinding := &rbac.ClusterRoleBinding{
+			ObjectMeta: provider.reaperObjectMeta(&owner),
+			RoleRef: rbac.RoleRef{
+				Name:     ReaperName,
+				Kind:     "ClusterRole",
+				APIGroup: "rbac.authorization.k8s.io",
+			},
+			Subjects: reaperSubjects(opts.Namespace),
+		}
+		data, err = newApplyPatch(clusterRoleBinding, rbac.SchemeGroupVersion.WithKind("ClusterRoleBinding"))
+		if err != nil {
+			return err
+		}
+		if _, err := provider.clientSet.RbacV1().ClusterRoleBindings().Patch(ctx, ReaperName, types.ApplyPatchType, data, applyPatchOptions()); err != nil {
+			return err
+		}
+	}
+
+	roleBinding := &rbac.RoleBinding{
+		ObjectMeta: provider.reaperObjectMeta(&owner),
+		RoleRef: rbac.RoleRef{
+			Name:     ReaperName,
+			Kind:     "Role",
+			APIGroup: "rbac.authorization.k8s.io",
+		},
+		Subjects: reaperSubjects(opts.Namespace),
+	}
+	data, err := newApplyPatch(roleBinding, rbac.SchemeGroupVersion.WithKind("RoleBinding"))
+	if err != nil {
+		return err
+	}
+	if _, err := provider.clientSet.RbacV1().RoleBindings(opts.Namespace).Patch(ctx, ReaperName, types.ApplyPatchType, data, applyPatchOptions()); err != nil {
+		return err
+	}
+
+	return provider.applyReaperCronJob(ctx, provider.buildReaperCronJob(opts, owner))
+}
+
+// applyReaperCronJob applies the reaper CronJob as a batch/v1beta1 CronJob on clusters older than Kubernetes 1.21,
+// which don't serve batch/v1 CronJobs.
+func (provider *Provider) applyReaperCronJob(ctx context.Context, cronJob *batch.CronJob) error {
+	isCronJobV1Served, err := provider.IsCronJobV1Served()
+	if err != nil {
+		return err
+	}
+
+	if isCronJobV1Served {
+		data, err := newApplyPatch(cronJob, batch.SchemeGroupVersion.WithKind("CronJob"))
+		if err != nil {
+			return err
+		}
+		_, err = provider.clientSet.BatchV1().CronJobs(cronJob.Namespace).Patch(ctx, cronJob.Name, types.ApplyPatchType, data, applyPatchOptions())
+		return err
+	}
+
+	v1beta1CronJob := &batchv1beta1.CronJob{
+		ObjectMeta: cronJob.ObjectMeta,
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:                   cronJob.Spec.Schedule,
+			ConcurrencyPolicy:          batchv1beta1.ConcurrencyPolicy(cronJob.Spec.ConcurrencyPolicy),
+			SuccessfulJobsHistoryLimit: cronJob.Spec.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     cronJob.Spec.FailedJobsHistoryLimit,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: cronJob.Spec.JobTemplate.ObjectMeta,
+				Spec:       cronJob.Spec.JobTemplate.Spec,
+			},
+		},
+	}
+	data, err := newApplyPatch(v1beta1CronJob, batchv1beta1.SchemeGroupVersion.WithKind("CronJob"))
+	if err != nil {
+		return err
+	}
+	_, err = provider.clientSet.BatchV1beta1().CronJobs(cronJob.Namespace).Patch(ctx, cronJob.Name, types.ApplyPatchType, data, applyPatchOptions())
+	return err
+}
+
+// IsCronJobV1Served reports whether the cluster serves batch/v1 CronJobs, which Kubernetes added in 1.21.
+func (provider *Provider) IsCronJobV1Served() (bool, error) {
+	resources, err := provider.clientSet.Discovery().ServerResourcesForGroupVersion(batch.SchemeGroupVersion.String())
+	if err != nil {
+		return false, err
+	}
+
+	for _, resource := range resources.APIResources {
+		if resource.Name == "cronjobs" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// applyReaperOwner applies the role all the other reaper objects are owned by, a ClusterRole that may also
+// remove the cluster scoped objects of the instance, or a Role in namespace restricted mode.
+func (provider *Provider) applyReaperOwner(ctx context.Context, opts *ReaperOptions) (metav1.OwnerReference, error) {
+	if opts.IsNamespaceRestricted {
+		role := &rbac.Role{
+			ObjectMeta: provider.reaperObjectMeta(nil),
+			Rules:      reaperNamespacedRules(),
+		}
+		data, err := newApplyPatch(role, rbac.SchemeGroupVersion.WithKind("Role"))
+		if err != nil {
+			return metav1.OwnerReference{}, err
+		}
+		appliedRole, err := provider.clientSet.RbacV1().Roles(opts.Namespace).Patch(ctx, ReaperName, types.ApplyPatchType, data, applyPatchOptions())
+		if err != nil {
+			return metav1.OwnerReference{}, err
+		}
+		return newReaperOwnerReference(appliedRole, "Role"), nil
+	}
+
+	clusterRole := &rbac.ClusterRole{
+		ObjectMeta: provider.reaperObjectMeta(nil),
+		Rules: []rbac.PolicyRule{
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"namespaces"},
+				ResourceNames: []string{opts.Namespace},
+				Verbs:         []string{"get", "delete"},
+			},
+			{
+				APIGroups:     []string{"rbac.authorization.k8s.io"},
+				Resources:     []string{"clusterroles"},
+				ResourceNames: []string{ClusterRoleName, ReaperName},
+				Verbs:         []string{"delete"},
+			},
+			{
+				APIGroups:     []string{"rbac.authorization.k8s.io"},
+				Resources:     []string{"clusterrolebindings"},
+				ResourceNames: []string{ClusterRoleBindingName},
+				Verbs:         []string{"delete"},
+			},
+			{
+				APIGroups:     []string{openShiftSecurityApiGroup},
+				Resources:     []string{securityContextConstraintsResource.Resource},
+				ResourceNames: []string{SecurityContextConstraintsName},
+				Verbs:         []string{"delete"},
+			},
+		},
+	}
+	data, err := newApplyPatch(clusterRole, rbac.SchemeGroupVersion.WithKind("ClusterRole"))
+	if err != nil {
+		return metav1.OwnerReference{}, err
+	}
+	appliedClusterRole, err := provider.clientSet.RbacV1().ClusterRoles().Patch(ctx, ReaperName, types.ApplyPatchType, data, applyPatchOptions())
+	if err != nil {
+		return metav1.OwnerReference{}, err
+	}
+	return newReaperOwnerReference(appliedClusterRole, "ClusterRole"), nil
+}
+
+func newReaperOwnerReference(owner metav1.Object, kind string) metav1.OwnerReference {
+	return metav1.OwnerReference{
+		APIVersion: rbac.SchemeGroupVersion.String(),
+		Kind:       kind,
+		Name:       owner.GetName(),
+		UID:        owner.GetUID(),
+	}
+}
+
+func (provider *Provider) reaperObjectMeta(owner *metav1.OwnerReference) metav1.ObjectMeta {
+	objectMeta := metav1.ObjectMeta{
+		Name: ReaperName,
+		Labels: map[string]string{
+			LabelManagedBy: provider.managedBy,
+			LabelCreatedBy: provider.createdBy,
+			LabelInstance:  Instance,
+			LabelComponent: LabelValueReaper,
+		},
+	}
+	if owner != nil {
+		objectMeta.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	return objectMeta
+}
+
+func reaperNamespacedRules() []rbac.PolicyRule {
+	return []rbac.PolicyRule{
+		{
+			APIGroups:     []string{"coordination.k8s.io"},
+			Resources:     []string{"leases"},
+			ResourceNames: []string{LeaseName},
+			Verbs:         []string{"get", "delete"},
+		},
+		{
+			APIGroups: []string{""},
+			Resources: []string{"pods", "services", "configmaps", "secrets", "serviceaccounts", "persistentvolumeclaims"},
+			Verbs:     []string{"list", "delete"},
+		},
+		{
+			APIGroups: []string{"apps"},
+			Resources: []string{"daemonsets"},
+			Verbs:     []string{"list", "delete"},
+		},
+		{
+			APIGroups: []string{"networking.k8s.io"},
+			Resources: []string{"ingresses", "networkpolicies"},
+			Verbs:     []string{"list", "delete"},
+		},
+		{
+			APIGroups: []string{"rbac.authorization.k8s.io"},
+			Resources: []string{"roles", "rolebindings"},
+			Verbs:     []string{"list", "delete"},
+		},
+	}
+}
+
+func reaperSubjects(namespace string) []rbac.Subject {
+	return []rbac.Subject{
+		{
+			Kind:      "ServiceAccount",
+			Name:      ReaperName,
+			Namespace: namespace,
+		},
+	}
+}
+
+func (provider *Provider) buildReaperCronJob(opts *ReaperOptions, owner metav1.OwnerReference) *batch.CronJob {
+	successfulJobsHistoryLimit := int32(0)
+	failedJobsHistoryLimit := int32(1)
+	backoffLimit := int32(0)
+
+	env := []core.EnvVar{
+		{Name: "NAMESPACE", Value: opts.Namespace},
+		{Name: "INSTANCE", Value: Instance},
+		{Name: "LEASE", Value: LeaseName},
+		{Name: "REAPER", Value: ReaperName},
+		{Name: "CLUSTER_ROLE", Value: ClusterRoleName},
+		{Name: "CLUSTER_ROLE_BINDING", Value: ClusterRoleBindingName},
+		{Name: "SCC", Value: SecurityContextConstraintsName},
+		{Name: "MANAGED_BY", Value: provider.managedBy},
+		{Name: "COMPONENT", Value: LabelValueReaper},
+		{Name: "LABEL_MANAGED_BY", Value: LabelManagedBy},
+		{Name: "LABEL_INSTANCE", Value: LabelInstance},
+		{Name: "LABEL_COMPONENT", Value: LabelComponent},
+		{Name: "KEEP_DATA", Value: strconv.FormatBool(opts.KeepData)},
+		{Name: "NAMESPACE_RESTRICTED", Value: strconv.FormatBool(opts.IsNamespaceRestricted)},
+	}
+
+	objectMeta := provider.reaperObjectMeta(&owner)
+	objectMeta.Namespace = opts.Namespace
+	return &batch.CronJob{
+		ObjectMeta: objectMeta,
+		Spec: batch.CronJobSpec{
+			Schedule:                   reaperSchedule,
+			ConcurrencyPolicy:          batch.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &successfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     &failedJobsHistoryLimit,
+			JobTemplate: batch.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: objectMeta.Labels,
+				},
+				Spec: batch.JobSpec{
+					BackoffLimit: &backoffLimit,
+					Template: core.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: objectMeta.Labels,
+						},
+						Spec: core.PodSpec{
+							ServiceAccountName: ReaperName,
+							RestartPolicy:      core.RestartPolicyNever,
+							Containers: []core.Container{
+								{
+									Name:            ReaperName,
+									Image:           opts.Image,
+									ImagePullPolicy: opts.ImagePullPolicy,
+									Command:         []string{"/bin/sh", "-c", reaperScript},
+									Env:             env,
+									Resources: core.ResourceRequirements{
+										Limits: core.ResourceList{
+											core.ResourceCPU:    resource.MustParse("100m"),
+											core.ResourceMemory: resource.MustParse("128Mi"),
+										},
+										Requests: core.ResourceList{
+											core.ResourceCPU:    resource.MustParse("10m"),
+											core.ResourceMemory: resource.MustParse("32Mi"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (provider *Provider) DoesReaperExist(ctx context.Context, namespace string, isNamespaceRestricted bool) (bool, error) {
+	if isNamespaceRestricted {
+		return provider.DoesRoleExist(ctx, namespace, ReaperName)
+	}
+	return provider.DoesClusterRoleExist(ctx, ReaperName)
+}
+
+// RemoveReaper removes the role that owns the reaper objects, the garbage collector removes the rest.
+func (provider *Provider) RemoveReaper(ctx context.Context, namespace string, isNamespaceRestricted bool) error {
+	propagationPolicy := metav1.DeletePropagationBackground
+	deleteOptions := metav1.DeleteOptions{PropagationPolicy: &propagationPolicy}
+
+	var err error
+	if isNamespaceRestricted {
+		err = provider.clientSet.RbacV1().Roles(namespace).Delete(ctx, ReaperName, deleteOptions)
+	} else {
+		err = provider.clientSet.RbacV1().ClusterRoles().Delete(ctx, ReaperName, deleteOptions)
+	}
+	return provider.handleRemovalError(err)
+}
+
+func (provider *Provider) RemoveCronJob(ctx context.Context, namespace string, cronJobName string) error {
+	isCronJobV1Served, err := provider.IsCronJobV1Served()
+	if err != nil {
+		return err
+	}
+
+	propagationPolicy := metav1.DeletePropagationBackground
+	deleteOptions := metav1.DeleteOptions{PropagationPolicy: &propagationPolicy}
+	if isCronJobV1Served {
+		err = provider.clientSet.BatchV1().CronJobs(namespace).Delete(ctx, cronJobName, deleteOptions)
+	} else {
+		err = provider.clientSet.BatchV1beta1().CronJobs(namespace).Delete(ctx, cronJobName, deleteOptions)
+	}
+	return provider.handleRemovalError(err)
+}
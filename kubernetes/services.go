@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/rest"
+)
+
+func (provider *Provider) GetService(ctx context.Context, namespace string, serviceName string) (*core.Service, error) {
+	return provider.clientSet.CoreV1().Services(namespace).Get(ctx, serviceName, metav1.GetOptions{})
+}
+
+// GetServicePort returns the port of a service that is port, or targets port on the pods of the service. The
+// only port of a service is returned for any port.
+func GetServicePort(service *core.Service, port int32) (*core.ServicePort, error) {
+	for i, servicePort := range service.Spec.Ports {
+		if servicePort.Port == port || (servicePort.TargetPort.Type == intstr.Int && servicePort.TargetPort.IntVal == port) {
+			return &service.Spec.Ports[i], nil
+		}
+	}
+
+	if len(service.Spec.Ports) == 1 {
+		return &service.Spec.Ports[0], nil
+	}
+	return nil, fmt.Errorf("service %s.%s has no port %d", service.Name, service.Namespace, port)
+}
+
+// GetServiceBackend returns a running pod of a service, and the port of the pod a port of the service targets.
+func (provider *Provider) GetServiceBackend(ctx context.Context, service *core.Service, servicePort *core.ServicePort) (string, uint16, error) {
+	if len(service.Spec.Selector) == 0 {
+		return "", 0, fmt.Errorf("service %s.%s selects no pods", service.Name, service.Namespace)
+	}
+
+	pods, err := provider.clientSet.CoreV1().Pods(service.Namespace).List(ctx, metav1.ListOptions{LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String()})
+	if err != nil {
+		return "", 0, err
+	}
+
+	for _, pod := range pods.Items {
+		if !IsPodRunning(&pod) {
+			continue
+		}
+
+		switch {
+		case servicePort.TargetPort.Type == intstr.String:
+			for _, container := range pod.Spec.Containers {
+				for _, containerPort := range container.Ports {
+					if containerPort.Name == servicePort.TargetPort.StrVal {
+						return pod.Name, uint16(containerPort.ContainerPort), nil
+					}
+				}
+			}
+		case servicePort.TargetPort.IntVal != 0:
+			return pod.Name, uint16(servicePort.TargetPort.IntVal), nil
+		default:
+			return pod.Name, uint16(servicePort.Port), nil
+		}
+	}
+
+	return "", 0, fmt.Errorf("service %s.%s has no running pod serving port %d", service.Name, service.Namespace, servicePort.Port)
+}
+
+// GetServiceProxy returns a transport authenticated to the API server, and the url of the API server proxy of a
+// service port, the paths of the service are served under it.
+func (provider *Provider) GetServiceProxy(namespace string, serviceName string, port int32) (http.RoundTripper, string, error) {
+	transport, err := rest.TransportFor(&provider.clientConfig)
+	if err != nil {
+		return nil, "", err
+	}
+
+	proxyUrl := fmt.Sprintf("%s/api/v1/namespaces/%s/services/%s:%d/proxy", strings.TrimSuffix(provider.clientConfig.Host, "/"), namespace, serviceName, port)
+	return transport, proxyUrl, nil
+}
@@ -2,14 +2,11 @@ package kubernetes
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log"
 	"sync"
-	"time"
-
-	"github.com/kubeshark/kubeshark/debounce"
 
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/watch"
 )
 
@@ -32,18 +29,16 @@ func FilteredWatch(ctx context.Context, watcherCreator WatchCreator, targetNames
 
 		go func(targetNamespace string) {
 			defer wg.Done()
-			watchRestartDebouncer := debounce.NewDebouncer(1*time.Minute, func() {})
+			backoff := &reconnectBackoff{}
 
 			for {
 				watcher, err := watcherCreator.NewWatcher(ctx, targetNamespace)
-				if err != nil {
-					errorChan <- fmt.Errorf("error in k8s watch: %v", err)
-					break
+				if err == nil {
+					backoff.connected()
+					err = startWatchLoop(ctx, watcher, filterer, eventChan) // blocking
+					watcher.Stop()
 				}
 
-				err = startWatchLoop(ctx, watcher, filterer, eventChan) // blocking
-				watcher.Stop()
-
 				select {
 				case <-ctx.Done():
 					return
@@ -51,21 +46,20 @@ func FilteredWatch(ctx context.Context, watcherCreator WatchCreator, targetNames
 					break
 				}
 
-				if err != nil {
+				// Missing permissions won't fix themselves, anything else may be a hiccup of the API server
+				// or of the connection to it, e.g. after the machine woke up from sleep
+				if k8serrors.IsForbidden(err) {
 					errorChan <- fmt.Errorf("error in k8s watch: %v", err)
-					break
+					return
+				}
+
+				if err != nil {
+					log.Printf("k8s watch failed, restarting watcher, err: %v", err)
 				} else {
-					if !watchRestartDebouncer.IsOn() {
-						if err := watchRestartDebouncer.SetOn(); err != nil {
-							log.Print(err)
-						}
-						log.Print("k8s watch channel closed, restarting watcher")
-						time.Sleep(time.Second * 5)
-						continue
-					} else {
-						errorChan <- errors.New("k8s watch unstable, closes frequently")
-						break
-					}
+					log.Print("k8s watch channel closed, restarting watcher")
+				}
+				if !backoff.wait(ctx) {
+					return
 				}
 			}
 		}(targetNamespace)
@@ -0,0 +1,74 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Workload is the controller that owns pods, or a pod without one, by the kind kubectl names it with.
+type Workload struct {
+	Kind string
+	Name string
+}
+
+func (workload *Workload) String() string {
+	return fmt.Sprintf("%s/%s", workload.Kind, workload.Name)
+}
+
+// GetWorkload returns the workload of a service or a pod by its name, the workload of a service is that of its pods.
+// It returns nil when there's no such service or pod.
+func (provider *Provider) GetWorkload(ctx context.Context, namespace string, name string) (*Workload, error) {
+	service, err := provider.GetService(ctx, namespace, name)
+	if err == nil {
+		if len(service.Spec.Selector) == 0 {
+			return nil, nil
+		}
+
+		pods, err := provider.clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String()})
+		if err != nil {
+			return nil, err
+		}
+		if len(pods.Items) == 0 {
+			return nil, nil
+		}
+		return provider.getPodWorkload(ctx, &pods.Items[0])
+	}
+	if !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	pod, err := provider.GetPod(ctx, namespace, name)
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return provider.getPodWorkload(ctx, pod)
+}
+
+// getPodWorkload returns the controller of a pod, the deployment of the replica set that owns it when there's one.
+func (provider *Provider) getPodWorkload(ctx context.Context, pod *core.Pod) (*Workload, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return &Workload{Kind: "pod", Name: pod.Name}, nil
+	}
+	if owner.Kind != "ReplicaSet" {
+		return &Workload{Kind: strings.ToLower(owner.Kind), Name: owner.Name}, nil
+	}
+
+	replicaSet, err := provider.clientSet.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if deployment := metav1.GetControllerOf(replicaSet); deployment != nil {
+		return &Workload{Kind: strings.ToLower(deployment.Kind), Name: deployment.Name}, nil
+	}
+	return &Workload{Kind: "replicaset", Name: replicaSet.Name}, nil
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/kubeshark/kubeshark/config"
 	"github.com/kubeshark/kubeshark/kubernetes"
+	core "k8s.io/api/core/v1"
 )
 
 func DumpLogs(ctx context.Context, provider *kubernetes.Provider, filePath string) error {
@@ -19,8 +20,9 @@ func DumpLogs(ctx context.Context, provider *kubernetes.Provider, filePath strin
 		return err
 	}
 
+	pods = filterInstancePods(pods)
 	if len(pods) == 0 {
-		return fmt.Errorf("no kubeshark pods found in namespace %s", config.Config.ResourcesNamespace)
+		return fmt.Errorf("no pods of kubeshark instance %s found in namespace %s", kubernetes.Instance, config.Config.ResourcesNamespace)
 	}
 
 	newZipFile, err := os.Create(filePath)
@@ -71,3 +73,14 @@ func DumpLogs(ctx context.Context, provider *kubernetes.Provider, filePath strin
 	log.Printf("You can find the zip file with all logs in %s", filePath)
 	return nil
 }
+
+func filterInstancePods(pods []core.Pod) []core.Pod {
+	instancePods := make([]core.Pod, 0)
+	for _, pod := range pods {
+		if kubernetes.GetInstance(pod.Labels) == kubernetes.Instance {
+			instancePods = append(instancePods, pod)
+		}
+	}
+
+	return instancePods
+}
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+// curlWriter writes a shell script that replays every request with curl.
+type curlWriter struct {
+	out     *bufio.Writer
+	builder *scriptBuilder
+}
+
+func newCurlWriter(out io.Writer, opts Options) (*curlWriter, error) {
+	writer := &curlWriter{out: bufio.NewWriter(out), builder: newScriptBuilder(FormatCurl, opts)}
+	if _, err := writer.out.WriteString("#!/bin/sh\n"); err != nil {
+		return nil, err
+	}
+	for _, line := range getScriptHeaderComment(opts) {
+		if _, err := fmt.Fprintf(writer.out, "# %s\n", line); err != nil {
+			return nil, err
+		}
+	}
+	return writer, nil
+}
+
+func (writer *curlWriter) Write(entry *hub.Entry) error {
+	request, err := writer.builder.build(entry)
+	if err != nil {
+		return err
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "\n# %s, responded %d\n", request.Comment, request.ExpectedStatus)
+	if request.Delay > 0 {
+		fmt.Fprintf(&builder, "sleep %.3f\n", request.Delay.Seconds())
+	}
+	fmt.Fprintf(&builder, "curl -sS -i -X %s %s", shellQuote(request.Method), shellQuote(request.Url))
+	for _, header := range request.Headers {
+		if header.Env != "" {
+			fmt.Fprintf(&builder, " \\\n  -H \"%s: ${%s}\"", header.Name, header.Env)
+		} else {
+			fmt.Fprintf(&builder, " \\\n  -H %s", shellQuote(fmt.Sprintf("%s: %s", header.Name, header.Value)))
+		}
+	}
+	if request.Body != "" {
+		fmt.Fprintf(&builder, " \\\n  --data-binary %s", shellQuote(request.Body))
+	}
+	builder.WriteString("\necho\n")
+
+	_, err = writer.out.WriteString(builder.String())
+	return err
+}
+
+func (writer *curlWriter) Close() error {
+	return writer.out.Flush()
+}
+
+// shellQuote quotes a value in single quotes, which the shell expands nothing in.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
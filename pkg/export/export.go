@@ -0,0 +1,103 @@
+// Package export writes the entries the hub captured to files other tools load, HAR, pcapng and JSON lines, or
+// to scripts that replay their requests with curl, k6 or a Go test.
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+const (
+	FormatHar    = "har"
+	FormatPcapng = "pcapng"
+	FormatJsonl  = "jsonl"
+	FormatCurl   = "curl"
+	FormatK6     = "k6"
+	FormatGoTest = "go-test"
+)
+
+var Formats = []string{FormatHar, FormatPcapng, FormatJsonl, FormatCurl, FormatK6, FormatGoTest}
+
+// FileSuffix returns the end of the names of the files of a format, Go test files must end with _test.go.
+func FileSuffix(format string) string {
+	switch format {
+	case FormatCurl:
+		return ".sh"
+	case FormatK6:
+		return ".js"
+	case FormatGoTest:
+		return "_test.go"
+	default:
+		return "." + format
+	}
+}
+
+// Writer writes entries as they are read, Close completes the file.
+type Writer interface {
+	Write(entry *hub.Entry) error
+	Close() error
+}
+
+type Options struct {
+	// Creator is the name and version of the tool recorded in the file
+	CreatorName    string
+	CreatorVersion string
+	Redactor       *Redactor
+	Script         ScriptOptions
+}
+
+// UnsupportedEntryError is returned by writers for entries their format can't hold, e.g. non HTTP entries in HAR.
+type UnsupportedEntryError struct {
+	Id       string
+	Protocol string
+	Format   string
+}
+
+func (err *UnsupportedEntryError) Error() string {
+	return fmt.Sprintf("entry %s is %s, which %s can't hold", err.Id, err.Protocol, err.Format)
+}
+
+func NewWriter(format string, out io.Writer, opts Options) (Writer, error) {
+	switch format {
+	case FormatHar:
+		return newHarWriter(out, opts)
+	case FormatPcapng:
+		return newPcapngWriter(out, opts)
+	case FormatJsonl:
+		return newJsonlWriter(out, opts), nil
+	case FormatCurl:
+		return newCurlWriter(out, opts)
+	case FormatK6:
+		return newK6Writer(out, opts)
+	case FormatGoTest:
+		return newGoTestWriter(out, opts)
+	default:
+		return nil, fmt.Errorf("%s is not a supported export format, use one of %v", format, Formats)
+	}
+}
+
+// Result counts the entries an export wrote and those its format can't hold.
+type Result struct {
+	Written int
+	Skipped int
+}
+
+// Export pages through the entries that match query from the oldest one, and writes every one of them.
+func Export(ctx context.Context, client *hub.Client, query string, writer Writer) (*Result, error) {
+	result := &Result{}
+	err := client.WalkEntries(ctx, query, func(entry *hub.Entry) error {
+		if err := writer.Write(entry); err != nil {
+			if _, ok := err.(*UnsupportedEntryError); ok {
+				result.Skipped++
+				return nil
+			}
+			return err
+		}
+		result.Written++
+		return nil
+	})
+	return result, err
+}
@@ -0,0 +1,259 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+func newHttpEntry(id string) *hub.Entry {
+	return &hub.Entry{
+		Id:          id,
+		Protocol:    hub.Protocol{Name: hub.ProtocolHttp},
+		Source:      &hub.TCP{IP: "10.0.0.1", Port: "43210", Name: "front.default"},
+		Destination: &hub.TCP{IP: "10.0.0.2", Port: "80", Name: "checkout.default"},
+		Namespace:   "default",
+		Timestamp:   1660000000000,
+		ElapsedTime: 12,
+		Request: map[string]interface{}{
+			"method":      "POST",
+			"url":         "/orders?token=secret&id=1",
+			"headers":     map[string]interface{}{"Authorization": "Bearer secret", "Host": "checkout"},
+			"queryString": map[string]interface{}{"token": "secret", "id": "1"},
+			"postData":    map[string]interface{}{"mimeType": "application/json", "text": `{"card":{"number":"4111"},"item":"book"}`},
+		},
+		Response: map[string]interface{}{
+			"status":     200,
+			"statusText": "OK",
+			"headers":    []interface{}{map[string]interface{}{"name": "Content-Type", "value": "application/json"}},
+			"content":    map[string]interface{}{"mimeType": "application/json", "text": `{"ok":true}`},
+		},
+	}
+}
+
+var testRedactor = &Redactor{
+	RequestHeaders:     []string{"authorization"},
+	RequestBody:        []string{"number"},
+	RequestQueryParams: []string{"token"},
+}
+
+func TestHar(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter(FormatHar, &out, Options{CreatorName: "kubeshark", CreatorVersion: "test", Redactor: testRedactor})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"1", "2"} {
+		if err := writer.Write(newHttpEntry(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Write(&hub.Entry{Id: "3", Protocol: hub.Protocol{Name: "redis"}}); err == nil {
+		t.Error("expected non HTTP entries to be unsupported")
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var har struct {
+		Log struct {
+			Version string     `json:"version"`
+			Entries []harEntry `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &har); err != nil {
+		t.Fatalf("invalid HAR %s: %v", out.String(), err)
+	}
+	if har.Log.Version != harVersion || len(har.Log.Entries) != 2 {
+		t.Fatalf("unexpected HAR %s", out.String())
+	}
+
+	entry := har.Log.Entries[0]
+	if entry.Request.Url != "http://checkout/orders?id=1&token=%5BREDACTED%5D" {
+		t.Errorf("unexpected url %s", entry.Request.Url)
+	}
+	if entry.Request.Headers.Get("Authorization") != RedactedValue || entry.Request.QueryString.Get("token") != RedactedValue {
+		t.Errorf("the request wasn't redacted %+v", entry.Request)
+	}
+	if strings.Contains(entry.Request.PostData.Text, "4111") || !strings.Contains(entry.Request.PostData.Text, "book") {
+		t.Errorf("unexpected redacted body %s", entry.Request.PostData.Text)
+	}
+	if !strings.Contains(entry.Comment, "namespace default") {
+		t.Errorf("expected the kubernetes metadata in the comment, got %s", entry.Comment)
+	}
+}
+
+func TestPcapng(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter(FormatPcapng, &out, Options{CreatorName: "kubeshark", Redactor: testRedactor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Write(newHttpEntry("1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := out.Bytes()
+	var blockTypes []uint32
+	var packets [][]byte
+	for len(data) > 0 {
+		if len(data) < 12 {
+			t.Fatalf("truncated block %v", data)
+		}
+		blockType := binary.LittleEndian.Uint32(data[0:])
+		length := binary.LittleEndian.Uint32(data[4:])
+		if length%4 != 0 || int(length) > len(data) || binary.LittleEndian.Uint32(data[length-4:]) != length {
+			t.Fatalf("invalid block length %d", length)
+		}
+		if blockType == pcapngEnhancedPacketBlock {
+			capturedLength := binary.LittleEndian.Uint32(data[20:])
+			packets = append(packets, data[28:28+capturedLength])
+		}
+		blockTypes = append(blockTypes, blockType)
+		data = data[length:]
+	}
+
+	if blockTypes[0] != pcapngSectionHeaderBlock || blockTypes[1] != pcapngInterfaceBlock || len(packets) != 2 {
+		t.Fatalf("unexpected blocks %v", blockTypes)
+	}
+	for _, packet := range packets {
+		if checksum(nil, packet[:pcapngIpv4HeaderLength]) != 0 {
+			t.Errorf("invalid IPv4 header checksum")
+		}
+	}
+
+	request := string(packets[0][pcapngIpv4HeaderLength+pcapngTcpHeaderLength:])
+	if !strings.HasPrefix(request, "POST /orders?id=1&token=%5BREDACTED%5D HTTP/1.1\r\n") || strings.Contains(request, "Bearer secret") {
+		t.Errorf("unexpected request %q", request)
+	}
+}
+
+func TestJsonlRedaction(t *testing.T) {
+	entry := newHttpEntry("1")
+	entry.Request["headers"] = map[string]interface{}{"Cookie": "session=c00kie", "Host": "checkout"}
+	entry.Request["cookies"] = map[string]interface{}{"session": "c00kie"}
+	entry.Response["headers"] = map[string]interface{}{"Set-Cookie": "session=c00kie; HttpOnly"}
+	entry.Response["cookies"] = []interface{}{map[string]interface{}{"name": "session", "value": "c00kie"}}
+	redactor := &Redactor{RequestHeaders: []string{"cookie"}, ResponseHeaders: []string{"set-cookie"}}
+
+	var out bytes.Buffer
+	writer, _ := NewWriter(FormatJsonl, &out, Options{Redactor: redactor})
+	if err := writer.Write(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Write(&hub.Entry{Id: "2", Protocol: hub.Protocol{Name: "redis"}}); err == nil {
+		t.Error("expected non HTTP entries to be left out of a redacted export")
+	}
+
+	if strings.Contains(out.String(), "c00kie") || strings.Count(out.String(), RedactedValue) != 4 {
+		t.Errorf("unexpected redacted cookies %s", out.String())
+	}
+}
+
+func TestExportPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/entries" && r.URL.Query().Get("leftOff") == "0":
+			_ = json.NewEncoder(w).Encode(hub.EntriesResponse{Data: []*hub.BaseEntry{{Id: "1"}, {Id: "2"}}, Meta: hub.QueryMetadata{LeftOff: "2"}})
+		case r.URL.Path == "/entries":
+			_ = json.NewEncoder(w).Encode(hub.EntriesResponse{Meta: hub.QueryMetadata{LeftOff: "2"}})
+		default:
+			id := strings.TrimPrefix(r.URL.Path, "/entries/")
+			_ = json.NewEncoder(w).Encode(hub.EntryWrapper{Data: newHttpEntry(id)})
+		}
+	}))
+	defer server.Close()
+
+	client, err := hub.NewClient(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	writer, _ := NewWriter(FormatJsonl, &out, Options{})
+	result, err := Export(context.Background(), client, "http", writer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Written != 2 || strings.Count(out.String(), "\n") != 2 {
+		t.Errorf("unexpected export %+v: %s", result, out.String())
+	}
+}
+
+func writeScript(t *testing.T, format string, script ScriptOptions) string {
+	var out bytes.Buffer
+	writer, err := NewWriter(format, &out, Options{CreatorName: "kubeshark", CreatorVersion: "test", Script: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	later := newHttpEntry("2")
+	later.Timestamp += 1500
+	for _, entry := range []*hub.Entry{newHttpEntry("1"), later} {
+		if err := writer.Write(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestScripts(t *testing.T) {
+	script := ScriptOptions{Targets: map[string]string{"checkout": "https://staging:8443/api"}, Auth: AuthTemplate, Timing: true}
+
+	curl := writeScript(t, FormatCurl, script)
+	for _, expected := range []string{"sleep 1.500", "curl -sS -i -X 'POST' 'https://staging:8443/api/orders?token=secret&id=1'", `-H "Authorization: ${AUTHORIZATION}"`, `--data-binary '{"card":{"number":"4111"},"item":"book"}'`} {
+		if !strings.Contains(curl, expected) {
+			t.Errorf("expected %s in curl script %s", expected, curl)
+		}
+	}
+
+	k6 := writeScript(t, FormatK6, ScriptOptions{Auth: AuthStrip})
+	if strings.Contains(k6, "Authorization") || strings.Contains(k6, "sleep(") || !strings.Contains(k6, `"http://checkout/orders?token=secret&id=1"`) {
+		t.Errorf("unexpected k6 script %s", k6)
+	}
+
+	goTest := writeScript(t, FormatGoTest, script)
+	if _, err := parser.ParseFile(token.NewFileSet(), "replay_test.go", goTest, 0); err != nil {
+		t.Errorf("invalid Go test %s: %v", goTest, err)
+	}
+	if !strings.Contains(goTest, `{"Authorization", env("AUTHORIZATION")}`) || !strings.Contains(goTest, "time.Sleep(1500 * time.Millisecond)") {
+		t.Errorf("unexpected Go test %s", goTest)
+	}
+}
+
+func TestCurlQuotesMethod(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter(FormatCurl, &out, Options{Script: ScriptOptions{Auth: AuthStrip}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := newHttpEntry("1")
+	entry.Request["method"] = "GET|touch${IFS}/tmp/pwned&`id`"
+	if err := writer.Write(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "curl -sS -i -X 'GET|touch${IFS}/tmp/pwned&`id`' ") {
+		t.Errorf("expected the method in single quotes %s", out.String())
+	}
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+const goTestHeader = `package replay
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env returns the value of the environment variable an auth header is read from.
+func env(name string) string {
+	return os.Getenv(name)
+}
+
+// replay sends a request and checks it's answered with the status it was captured with.
+func replay(t *testing.T, client *http.Client, method string, url string, headers [][2]string, body string, expectedStatus int) {
+	t.Helper()
+
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, header := range headers {
+		request.Header.Add(header[0], header[1])
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		t.Errorf("%s %s failed: %v", method, url, err)
+		return
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode != expectedStatus {
+		t.Errorf("%s %s responded %d, expected %d", method, url, response.StatusCode, expectedStatus)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	client := &http.Client{Timeout: 30 * time.Second}
+`
+
+// goTestWriter writes a Go test that replays every request and checks their status.
+type goTestWriter struct {
+	out     *bufio.Writer
+	builder *scriptBuilder
+}
+
+func newGoTestWriter(out io.Writer, opts Options) (*goTestWriter, error) {
+	writer := &goTestWriter{out: bufio.NewWriter(out), builder: newScriptBuilder(FormatGoTest, opts)}
+	for _, line := range getScriptHeaderComment(opts) {
+		if _, err := fmt.Fprintf(writer.out, "// %s\n", line); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := writer.out.WriteString("\n" + goTestHeader); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (writer *goTestWriter) Write(entry *hub.Entry) error {
+	request, err := writer.builder.build(entry)
+	if err != nil {
+		return err
+	}
+
+	var headers []string
+	for _, header := range request.Headers {
+		value := strconv.Quote(header.Value)
+		if header.Env != "" {
+			value = fmt.Sprintf("env(%s)", strconv.Quote(header.Env))
+		}
+		headers = append(headers, fmt.Sprintf("{%s, %s}", strconv.Quote(header.Name), value))
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "\n\t// %s\n", request.Comment)
+	if request.Delay > 0 {
+		fmt.Fprintf(&builder, "\ttime.Sleep(%d * time.Millisecond)\n", request.Delay.Milliseconds())
+	}
+	fmt.Fprintf(&builder, "\treplay(t, client, %s, %s, [][2]string{%s}, %s, %d)\n", strconv.Quote(request.Method), strconv.Quote(request.Url), strings.Join(headers, ", "), strconv.Quote(request.Body), request.ExpectedStatus)
+
+	_, err = writer.out.WriteString(builder.String())
+	return err
+}
+
+func (writer *goTestWriter) Close() error {
+	if _, err := writer.out.WriteString("}\n"); err != nil {
+		return err
+	}
+	return writer.out.Flush()
+}
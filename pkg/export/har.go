@@ -0,0 +1,147 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+const harVersion = "1.2"
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harTimings struct {
+	Send    int64 `json:"send"`
+	Wait    int64 `json:"wait"`
+	Receive int64 `json:"receive"`
+}
+
+type harEntry struct {
+	StartedDateTime string            `json:"startedDateTime"`
+	Time            int64             `json:"time"`
+	Request         *hub.HttpRequest  `json:"request"`
+	Response        *hub.HttpResponse `json:"response"`
+	Cache           struct{}          `json:"cache"`
+	Timings         harTimings        `json:"timings"`
+	ServerIPAddress string            `json:"serverIPAddress,omitempty"`
+	Connection      string            `json:"connection,omitempty"`
+	Comment         string            `json:"comment,omitempty"`
+}
+
+// harWriter streams a HAR 1.2 log, the log is opened right away and its entries are written as they come.
+type harWriter struct {
+	out      *bufio.Writer
+	redactor *Redactor
+	entries  int
+}
+
+func newHarWriter(out io.Writer, opts Options) (*harWriter, error) {
+	creator, err := json.Marshal(harCreator{Name: opts.CreatorName, Version: opts.CreatorVersion})
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &harWriter{out: bufio.NewWriter(out), redactor: opts.Redactor}
+	if _, err := fmt.Fprintf(writer.out, `{"log":{"version":%q,"creator":%s,"entries":[`, harVersion, creator); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (writer *harWriter) Write(entry *hub.Entry) error {
+	request, response, err := getHttp(entry, writer.redactor, FormatHar)
+	if err != nil {
+		return err
+	}
+
+	request.Url = getAbsoluteUrl(entry, request)
+	normalizeHarRequest(request)
+	normalizeHarResponse(response)
+
+	harEntry := &harEntry{
+		StartedDateTime: time.UnixMilli(entry.Timestamp).UTC().Format(time.RFC3339Nano),
+		Time:            entry.ElapsedTime,
+		Request:         request,
+		Response:        response,
+		// The hub records how long a response took, not its phases
+		Timings: harTimings{Send: 0, Wait: entry.ElapsedTime, Receive: 0},
+		Comment: getMetadataComment(entry),
+	}
+	if entry.Destination != nil {
+		harEntry.ServerIPAddress = entry.Destination.IP
+		harEntry.Connection = entry.Destination.Port
+	}
+
+	data, err := json.Marshal(harEntry)
+	if err != nil {
+		return err
+	}
+
+	if writer.entries > 0 {
+		if err := writer.out.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	if _, err := writer.out.Write(data); err != nil {
+		return err
+	}
+	writer.entries++
+	return nil
+}
+
+func (writer *harWriter) Close() error {
+	if _, err := writer.out.WriteString("]}}\n"); err != nil {
+		return err
+	}
+	return writer.out.Flush()
+}
+
+// normalizeHarRequest fills the fields HAR requires, which the hub may leave empty.
+func normalizeHarRequest(request *hub.HttpRequest) {
+	if request.HttpVersion == "" {
+		request.HttpVersion = "HTTP/1.1"
+	}
+	if request.Cookies == nil {
+		request.Cookies = hub.NameValues{}
+	}
+	if request.Headers == nil {
+		request.Headers = hub.NameValues{}
+	}
+	if request.QueryString == nil {
+		request.QueryString = hub.NameValues{}
+	}
+	if request.HeadersSize == 0 {
+		request.HeadersSize = -1
+	}
+	if request.BodySize == 0 {
+		request.BodySize = int64(len(request.Body()))
+	}
+}
+
+func normalizeHarResponse(response *hub.HttpResponse) {
+	if response.HttpVersion == "" {
+		response.HttpVersion = "HTTP/1.1"
+	}
+	if response.Cookies == nil {
+		response.Cookies = hub.NameValues{}
+	}
+	if response.Headers == nil {
+		response.Headers = hub.NameValues{}
+	}
+	if response.Content == nil {
+		response.Content = &hub.Content{}
+	}
+	if response.Content.MimeType == "" {
+		response.Content.MimeType = response.Headers.Get("Content-Type")
+	}
+	if response.HeadersSize == 0 {
+		response.HeadersSize = -1
+	}
+}
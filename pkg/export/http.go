@@ -0,0 +1,76 @@
+package export
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+// getHttp returns the redacted request and response of an HTTP entry.
+func getHttp(entry *hub.Entry, redactor *Redactor, format string) (*hub.HttpRequest, *hub.HttpResponse, error) {
+	if !entry.IsHttp() {
+		return nil, nil, &UnsupportedEntryError{Id: entry.Id, Protocol: entry.Protocol.Name, Format: format}
+	}
+
+	request, err := entry.GetHttpRequest()
+	if err != nil {
+		return nil, nil, err
+	}
+	response, err := entry.GetHttpResponse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	redactor.RedactRequest(request)
+	redactor.RedactResponse(response)
+	return request, response, nil
+}
+
+// getAbsoluteUrl returns the url of a request, requests the hub recorded with a path only are given the host
+// header, or the destination, as their host.
+func getAbsoluteUrl(entry *hub.Entry, request *hub.HttpRequest) string {
+	parsedUrl, err := url.Parse(request.Url)
+	if err == nil && parsedUrl.IsAbs() {
+		return request.Url
+	}
+
+	host := request.Headers.Get("Host")
+	if host == "" && entry.Destination != nil {
+		host = fmt.Sprintf("%s:%s", entry.Destination.IP, entry.Destination.Port)
+	}
+	path := request.Url
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return fmt.Sprintf("http://%s%s", host, path)
+}
+
+func getEndpointDescription(endpoint *hub.TCP) string {
+	if endpoint == nil {
+		return ""
+	}
+	if endpoint.Name != "" {
+		return fmt.Sprintf("%s (%s:%s)", endpoint.Name, endpoint.IP, endpoint.Port)
+	}
+	return fmt.Sprintf("%s:%s", endpoint.IP, endpoint.Port)
+}
+
+// getMetadataComment describes where in the cluster an entry was captured.
+func getMetadataComment(entry *hub.Entry) string {
+	parts := []string{fmt.Sprintf("kubeshark entry %s", entry.Id)}
+	if entry.Namespace != "" {
+		parts = append(parts, fmt.Sprintf("namespace %s", entry.Namespace))
+	}
+	if source := getEndpointDescription(entry.Source); source != "" {
+		parts = append(parts, fmt.Sprintf("source %s", source))
+	}
+	if destination := getEndpointDescription(entry.Destination); destination != "" {
+		parts = append(parts, fmt.Sprintf("destination %s", destination))
+	}
+	if entry.Outgoing {
+		parts = append(parts, "outgoing")
+	}
+	return strings.Join(parts, ", ")
+}
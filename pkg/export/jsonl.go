@@ -0,0 +1,54 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+// jsonlWriter writes an entry per line as the hub returns it. With a redactor the request and response of HTTP
+// entries are redacted, and the entries of other protocols are left out since the redaction rules don't cover them.
+type jsonlWriter struct {
+	encoder  *json.Encoder
+	redactor *Redactor
+}
+
+func newJsonlWriter(out io.Writer, opts Options) *jsonlWriter {
+	return &jsonlWriter{encoder: json.NewEncoder(out), redactor: opts.Redactor}
+}
+
+func (writer *jsonlWriter) Write(entry *hub.Entry) error {
+	if writer.redactor != nil {
+		request, response, err := getHttp(entry, writer.redactor, FormatJsonl)
+		if err != nil {
+			return err
+		}
+
+		redactedEntry := *entry
+		if redactedEntry.Request, err = toMap(request); err != nil {
+			return err
+		}
+		if redactedEntry.Response, err = toMap(response); err != nil {
+			return err
+		}
+		entry = &redactedEntry
+	}
+
+	return writer.encoder.Encode(entry)
+}
+
+func (writer *jsonlWriter) Close() error {
+	return nil
+}
+
+func toMap(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	err = json.Unmarshal(data, &result)
+	return result, err
+}
@@ -0,0 +1,82 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+// k6Writer writes a k6 script whose iterations replay every request and check their status.
+type k6Writer struct {
+	out     *bufio.Writer
+	builder *scriptBuilder
+}
+
+func newK6Writer(out io.Writer, opts Options) (*k6Writer, error) {
+	writer := &k6Writer{out: bufio.NewWriter(out), builder: newScriptBuilder(FormatK6, opts)}
+	for _, line := range getScriptHeaderComment(opts) {
+		if _, err := fmt.Fprintf(writer.out, "// %s\n", line); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := writer.out.WriteString("import http from 'k6/http';\nimport { check, sleep } from 'k6';\n\nexport default function () {\n  let res;\n"); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (writer *k6Writer) Write(entry *hub.Entry) error {
+	request, err := writer.builder.build(entry)
+	if err != nil {
+		return err
+	}
+
+	var headers []string
+	for _, header := range request.Headers {
+		value := jsString(header.Value)
+		if header.Env != "" {
+			value = fmt.Sprintf("__ENV.%s", header.Env)
+		}
+		headers = append(headers, fmt.Sprintf("%s: %s", jsString(header.Name), value))
+	}
+	body := "null"
+	if request.Body != "" {
+		body = jsString(request.Body)
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "\n  // %s\n", request.Comment)
+	if request.Delay > 0 {
+		fmt.Fprintf(&builder, "  sleep(%.3f);\n", request.Delay.Seconds())
+	}
+	headersObject := "{}"
+	if len(headers) > 0 {
+		headersObject = fmt.Sprintf("{ %s }", strings.Join(headers, ", "))
+	}
+	fmt.Fprintf(&builder, "  res = http.request(%s, %s, %s, { headers: %s });\n", jsString(request.Method), jsString(request.Url), body, headersObject)
+	fmt.Fprintf(&builder, "  check(res, { 'status is %d': (r) => r.status === %d });\n", request.ExpectedStatus, request.ExpectedStatus)
+
+	_, err = writer.out.WriteString(builder.String())
+	return err
+}
+
+func (writer *k6Writer) Close() error {
+	if _, err := writer.out.WriteString("}\n"); err != nil {
+		return err
+	}
+	return writer.out.Flush()
+}
+
+// jsString returns a JavaScript string literal, JSON strings are valid ones.
+func jsString(value string) string {
+	var literal bytes.Buffer
+	encoder := json.NewEncoder(&literal)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+	return strings.TrimSuffix(literal.String(), "\n")
+}
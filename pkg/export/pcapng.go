@@ -0,0 +1,301 @@
+package export
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+// The hub keeps the HTTP messages it parsed rather than packets, the pcapng writer rebuilds a TCP segment
+// stream for every request and response, which Wireshark and tcpdump dissect as HTTP/1.1.
+
+const (
+	pcapngSectionHeaderBlock    = 0x0A0D0D0A
+	pcapngInterfaceBlock        = 0x00000001
+	pcapngEnhancedPacketBlock   = 0x00000006
+	pcapngByteOrderMagic        = 0x1A2B3C4D
+	pcapngOptionEnd             = 0
+	pcapngOptionComment         = 1
+	pcapngOptionShbUserAppl     = 4
+	pcapngLinkTypeRaw           = 101
+	pcapngMaxSegmentPayload     = 1460
+	pcapngIpv4HeaderLength      = 20
+	pcapngIpv6HeaderLength      = 40
+	pcapngTcpHeaderLength       = 20
+	pcapngTcpFlagsPushAck       = 0x18
+	pcapngTcpWindow             = 65535
+	pcapngDefaultTtl            = 64
+	pcapngIpProtocolTcp         = 6
+	pcapngInitialSequenceNumber = 1
+)
+
+type tcpFlow struct {
+	clientSeq uint32
+	serverSeq uint32
+}
+
+type pcapngWriter struct {
+	out      *bufio.Writer
+	redactor *Redactor
+	// The next sequence numbers of the connections written so far, so entries of the same connection continue it
+	flows map[string]*tcpFlow
+}
+
+func newPcapngWriter(out io.Writer, opts Options) (*pcapngWriter, error) {
+	writer := &pcapngWriter{out: bufio.NewWriter(out), redactor: opts.Redactor, flows: make(map[string]*tcpFlow)}
+
+	userAppl := strings.TrimSpace(fmt.Sprintf("%s %s", opts.CreatorName, opts.CreatorVersion))
+	sectionHeader := make([]byte, 16)
+	binary.LittleEndian.PutUint32(sectionHeader[0:], pcapngByteOrderMagic)
+	binary.LittleEndian.PutUint16(sectionHeader[4:], 1)
+	binary.LittleEndian.PutUint16(sectionHeader[6:], 0)
+	// The length of the section is unknown while streaming
+	binary.LittleEndian.PutUint64(sectionHeader[8:], ^uint64(0))
+	if err := writer.writeBlock(pcapngSectionHeaderBlock, sectionHeader, pcapngOption(pcapngOptionShbUserAppl, userAppl)); err != nil {
+		return nil, err
+	}
+
+	interfaceDescription := make([]byte, 8)
+	binary.LittleEndian.PutUint16(interfaceDescription[0:], pcapngLinkTypeRaw)
+	if err := writer.writeBlock(pcapngInterfaceBlock, interfaceDescription, nil); err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+func (writer *pcapngWriter) Write(entry *hub.Entry) error {
+	request, response, err := getHttp(entry, writer.redactor, FormatPcapng)
+	if err != nil {
+		return err
+	}
+	if entry.Source == nil || entry.Destination == nil {
+		return &UnsupportedEntryError{Id: entry.Id, Protocol: "an entry without addresses", Format: FormatPcapng}
+	}
+
+	client, err := parseEndpoint(entry.Source)
+	if err != nil {
+		return err
+	}
+	server, err := parseEndpoint(entry.Destination)
+	if err != nil {
+		return err
+	}
+
+	flowKey := fmt.Sprintf("%s-%s", client, server)
+	flow, ok := writer.flows[flowKey]
+	if !ok {
+		flow = &tcpFlow{clientSeq: pcapngInitialSequenceNumber, serverSeq: pcapngInitialSequenceNumber}
+		writer.flows[flowKey] = flow
+	}
+
+	comment := getMetadataComment(entry)
+	startTime := time.UnixMilli(entry.Timestamp)
+	if err := writer.writeSegments(client, server, &flow.clientSeq, flow.serverSeq, startTime, serializeRequest(entry, request), comment); err != nil {
+		return err
+	}
+	endTime := startTime.Add(time.Duration(entry.ElapsedTime) * time.Millisecond)
+	return writer.writeSegments(server, client, &flow.serverSeq, flow.clientSeq, endTime, serializeResponse(response), comment)
+}
+
+func (writer *pcapngWriter) Close() error {
+	return writer.out.Flush()
+}
+
+func (writer *pcapngWriter) writeSegments(from *net.TCPAddr, to *net.TCPAddr, seq *uint32, ack uint32, timestamp time.Time, payload []byte, comment string) error {
+	for offset := 0; offset < len(payload); offset += pcapngMaxSegmentPayload {
+		end := offset + pcapngMaxSegmentPayload
+		if end > len(payload) {
+			end = len(payload)
+		}
+
+		packet := buildTcpPacket(from, to, *seq, ack, payload[offset:end])
+		*seq += uint32(end - offset)
+
+		// Only the first segment of a message carries the comment
+		var options []byte
+		if offset == 0 {
+			options = pcapngOption(pcapngOptionComment, comment)
+		}
+		if err := writer.writePacket(timestamp, packet, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (writer *pcapngWriter) writePacket(timestamp time.Time, packet []byte, options []byte) error {
+	microseconds := uint64(timestamp.UnixNano() / int64(time.Microsecond))
+	header := make([]byte, 20)
+	binary.LittleEndian.PutUint32(header[0:], 0)
+	binary.LittleEndian.PutUint32(header[4:], uint32(microseconds>>32))
+	binary.LittleEndian.PutUint32(header[8:], uint32(microseconds))
+	binary.LittleEndian.PutUint32(header[12:], uint32(len(packet)))
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(packet)))
+
+	return writer.writeBlock(pcapngEnhancedPacketBlock, append(header, pad32(packet)...), options)
+}
+
+// writeBlock writes a block, its body must be padded to 32 bits, options end with an end of options option.
+func (writer *pcapngWriter) writeBlock(blockType uint32, body []byte, options []byte) error {
+	if len(options) > 0 {
+		options = append(options, 0, 0, 0, 0)
+	}
+
+	totalLength := 12 + len(body) + len(options)
+	block := make([]byte, totalLength)
+	binary.LittleEndian.PutUint32(block[0:], blockType)
+	binary.LittleEndian.PutUint32(block[4:], uint32(totalLength))
+	copy(block[8:], body)
+	copy(block[8+len(body):], options)
+	binary.LittleEndian.PutUint32(block[totalLength-4:], uint32(totalLength))
+
+	_, err := writer.out.Write(block)
+	return err
+}
+
+func pcapngOption(code uint16, value string) []byte {
+	if value == "" {
+		return nil
+	}
+
+	option := make([]byte, 4)
+	binary.LittleEndian.PutUint16(option[0:], code)
+	binary.LittleEndian.PutUint16(option[2:], uint16(len(value)))
+	return append(option, pad32([]byte(value))...)
+}
+
+func pad32(data []byte) []byte {
+	if padding := (4 - len(data)%4) % 4; padding > 0 {
+		return append(data, make([]byte, padding)...)
+	}
+	return data
+}
+
+func parseEndpoint(endpoint *hub.TCP) (*net.TCPAddr, error) {
+	ip := net.ParseIP(endpoint.IP)
+	if ip == nil {
+		return nil, fmt.Errorf("%s is not a valid IP", endpoint.IP)
+	}
+	port, err := strconv.ParseUint(endpoint.Port, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid port", endpoint.Port)
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+func buildTcpPacket(from *net.TCPAddr, to *net.TCPAddr, seq uint32, ack uint32, payload []byte) []byte {
+	segment := make([]byte, pcapngTcpHeaderLength+len(payload))
+	binary.BigEndian.PutUint16(segment[0:], uint16(from.Port))
+	binary.BigEndian.PutUint16(segment[2:], uint16(to.Port))
+	binary.BigEndian.PutUint32(segment[4:], seq)
+	binary.BigEndian.PutUint32(segment[8:], ack)
+	segment[12] = (pcapngTcpHeaderLength / 4) << 4
+	segment[13] = pcapngTcpFlagsPushAck
+	binary.BigEndian.PutUint16(segment[14:], pcapngTcpWindow)
+	copy(segment[pcapngTcpHeaderLength:], payload)
+
+	if fromIp, toIp := from.IP.To4(), to.IP.To4(); fromIp != nil && toIp != nil {
+		pseudoHeader := make([]byte, 12)
+		copy(pseudoHeader[0:], fromIp)
+		copy(pseudoHeader[4:], toIp)
+		pseudoHeader[9] = pcapngIpProtocolTcp
+		binary.BigEndian.PutUint16(pseudoHeader[10:], uint16(len(segment)))
+		binary.BigEndian.PutUint16(segment[16:], checksum(pseudoHeader, segment))
+
+		header := make([]byte, pcapngIpv4HeaderLength)
+		header[0] = 0x45
+		binary.BigEndian.PutUint16(header[2:], uint16(pcapngIpv4HeaderLength+len(segment)))
+		binary.BigEndian.PutUint16(header[6:], 0x4000)
+		header[8] = pcapngDefaultTtl
+		header[9] = pcapngIpProtocolTcp
+		copy(header[12:], fromIp)
+		copy(header[16:], toIp)
+		binary.BigEndian.PutUint16(header[10:], checksum(nil, header))
+		return append(header, segment...)
+	}
+
+	fromIp, toIp := from.IP.To16(), to.IP.To16()
+	pseudoHeader := make([]byte, 40)
+	copy(pseudoHeader[0:], fromIp)
+	copy(pseudoHeader[16:], toIp)
+	binary.BigEndian.PutUint32(pseudoHeader[32:], uint32(len(segment)))
+	pseudoHeader[39] = pcapngIpProtocolTcp
+	binary.BigEndian.PutUint16(segment[16:], checksum(pseudoHeader, segment))
+
+	header := make([]byte, pcapngIpv6HeaderLength)
+	header[0] = 0x60
+	binary.BigEndian.PutUint16(header[4:], uint16(len(segment)))
+	header[6] = pcapngIpProtocolTcp
+	header[7] = pcapngDefaultTtl
+	copy(header[8:], fromIp)
+	copy(header[24:], toIp)
+	return append(header, segment...)
+}
+
+// checksum is the internet checksum of data, preceded by the even sized pseudo header of TCP.
+func checksum(pseudoHeader []byte, data []byte) uint16 {
+	var sum uint32
+	add := func(bytes []byte) {
+		for i := 0; i+1 < len(bytes); i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(bytes[i:]))
+		}
+		if len(bytes)%2 == 1 {
+			sum += uint32(bytes[len(bytes)-1]) << 8
+		}
+	}
+	add(pseudoHeader)
+	add(data)
+
+	for sum>>16 != 0 {
+		sum = (sum & 0xffff) + (sum >> 16)
+	}
+	return ^uint16(sum)
+}
+
+// The bodies are written decoded, so the headers describing how they were framed or encoded are replaced.
+var framingHeaders = []string{"Content-Length", "Transfer-Encoding", "Content-Encoding"}
+
+func writeHeaders(builder *strings.Builder, headers hub.NameValues, bodyLength int) {
+	for _, header := range headers {
+		if containsFold(framingHeaders, header.Name) {
+			continue
+		}
+		fmt.Fprintf(builder, "%s: %s\r\n", header.Name, header.Value)
+	}
+	fmt.Fprintf(builder, "Content-Length: %d\r\n\r\n", bodyLength)
+}
+
+func serializeRequest(entry *hub.Entry, request *hub.HttpRequest) []byte {
+	target := request.Url
+	if parsedUrl, err := url.Parse(getAbsoluteUrl(entry, request)); err == nil {
+		target = parsedUrl.RequestURI()
+	}
+
+	body := request.Body()
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%s %s HTTP/1.1\r\n", request.Method, target)
+	if request.Headers.Get("Host") == "" && entry.Destination != nil {
+		fmt.Fprintf(&builder, "Host: %s:%s\r\n", entry.Destination.IP, entry.Destination.Port)
+	}
+	writeHeaders(&builder, request.Headers, len(body))
+	builder.Write(body)
+	return []byte(builder.String())
+}
+
+func serializeResponse(response *hub.HttpResponse) []byte {
+	body, _ := response.Body()
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "HTTP/1.1 %d %s\r\n", response.Status, response.StatusText)
+	writeHeaders(&builder, response.Headers, len(body))
+	builder.Write(body)
+	return []byte(builder.String())
+}
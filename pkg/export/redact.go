@@ -0,0 +1,152 @@
+package export
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+const RedactedValue = "[REDACTED]"
+
+// Redactor hides the values the redaction rules of the tap name, header and query param names are compared
+// case insensitively, body fields are JSON keys at any depth.
+type Redactor struct {
+	RequestHeaders     []string
+	ResponseHeaders    []string
+	RequestBody        []string
+	ResponseBody       []string
+	RequestQueryParams []string
+}
+
+func containsFold(names []string, name string) bool {
+	for _, candidate := range names {
+		if strings.EqualFold(candidate, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactNameValues(nameValues hub.NameValues, names []string) {
+	for i := range nameValues {
+		if containsFold(names, nameValues[i].Name) {
+			nameValues[i].Value = RedactedValue
+		}
+	}
+}
+
+// redactCookies redacts the cookies parsed from header along with it, they hold the same values.
+func redactCookies(cookies hub.NameValues, header string, names []string) {
+	if !containsFold(names, header) {
+		return
+	}
+	for i := range cookies {
+		cookies[i].Value = RedactedValue
+	}
+}
+
+func (redactor *Redactor) RedactRequest(request *hub.HttpRequest) {
+	if redactor == nil {
+		return
+	}
+
+	redactNameValues(request.Headers, redactor.RequestHeaders)
+	redactCookies(request.Cookies, "Cookie", redactor.RequestHeaders)
+	redactNameValues(request.QueryString, redactor.RequestQueryParams)
+	request.Url = redactUrl(request.Url, redactor.RequestQueryParams)
+	if request.PostData != nil {
+		request.PostData.Text = redactJsonBody(request.PostData.Text, redactor.RequestBody)
+	}
+}
+
+func (redactor *Redactor) RedactResponse(response *hub.HttpResponse) {
+	if redactor == nil {
+		return
+	}
+
+	redactNameValues(response.Headers, redactor.ResponseHeaders)
+	redactCookies(response.Cookies, "Set-Cookie", redactor.ResponseHeaders)
+	if response.Content == nil || len(redactor.ResponseBody) == 0 {
+		return
+	}
+
+	body, err := response.Body()
+	if err != nil {
+		return
+	}
+	if redacted := redactJsonBody(string(body), redactor.ResponseBody); redacted != string(body) {
+		response.Content.Text = redacted
+		response.Content.Encoding = ""
+	}
+}
+
+func redactUrl(rawUrl string, names []string) string {
+	if len(names) == 0 {
+		return rawUrl
+	}
+
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil || parsedUrl.RawQuery == "" {
+		return rawUrl
+	}
+
+	query := parsedUrl.Query()
+	redacted := false
+	for name := range query {
+		if containsFold(names, name) {
+			query[name] = []string{RedactedValue}
+			redacted = true
+		}
+	}
+	if !redacted {
+		return rawUrl
+	}
+	parsedUrl.RawQuery = query.Encode()
+	return parsedUrl.String()
+}
+
+// redactJsonBody returns body with the values of the keys in names redacted, bodies that aren't JSON are
+// returned as they are.
+func redactJsonBody(body string, names []string) string {
+	if len(names) == 0 || body == "" {
+		return body
+	}
+
+	var document interface{}
+	if err := json.Unmarshal([]byte(body), &document); err != nil {
+		return body
+	}
+	if !redactJsonValue(document, names) {
+		return body
+	}
+
+	redacted, err := json.Marshal(document)
+	if err != nil {
+		return body
+	}
+	return string(redacted)
+}
+
+func redactJsonValue(value interface{}, names []string) bool {
+	redacted := false
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			if containsFold(names, key) {
+				typed[key] = RedactedValue
+				redacted = true
+			} else if redactJsonValue(child, names) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for _, child := range typed {
+			if redactJsonValue(child, names) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
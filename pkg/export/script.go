@@ -0,0 +1,161 @@
+package export
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+// The ways scripts handle the headers that authenticate requests.
+const (
+	// AuthTemplate reads the values of auth headers from environment variables named after the headers
+	AuthTemplate = "template"
+	AuthStrip    = "strip"
+	AuthKeep     = "keep"
+)
+
+var AuthModes = []string{AuthTemplate, AuthStrip, AuthKeep}
+
+// AuthHeaders are the headers scripts template or strip, names are compared case insensitively.
+var AuthHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key", "X-Auth-Token"}
+
+// TargetAny is the target host that matches the requests to any host.
+const TargetAny = "*"
+
+// ScriptOptions configure the scripts that replay the exported requests.
+type ScriptOptions struct {
+	// Targets rewrite the host of requests to a base url, by their host with or without a port, or TargetAny
+	Targets map[string]string
+	Auth    string
+	// Timing keeps the delays between requests as they were captured
+	Timing bool
+}
+
+// scriptRequest is a request as scripts replay it.
+type scriptRequest struct {
+	Method  string
+	Url     string
+	Headers []scriptHeader
+	Body    string
+	// Delay is the time since the previous request
+	Delay          time.Duration
+	ExpectedStatus int
+	Comment        string
+}
+
+// scriptHeader is a header whose value is either the captured one, or read from the environment variable Env.
+type scriptHeader struct {
+	Name  string
+	Value string
+	Env   string
+}
+
+// scriptHeaderExcluded are set by the clients the scripts use.
+var scriptHeaderExcluded = []string{"Host", "Content-Length", "Transfer-Encoding", "Connection", "Accept-Encoding"}
+
+// scriptBuilder turns entries into the requests of a script, in the order they were captured.
+type scriptBuilder struct {
+	format        string
+	opts          ScriptOptions
+	redactor      *Redactor
+	lastTimestamp int64
+}
+
+func newScriptBuilder(format string, opts Options) *scriptBuilder {
+	return &scriptBuilder{format: format, opts: opts.Script, redactor: opts.Redactor}
+}
+
+func (builder *scriptBuilder) build(entry *hub.Entry) (*scriptRequest, error) {
+	request, response, err := getHttp(entry, builder.redactor, builder.format)
+	if err != nil {
+		return nil, err
+	}
+
+	requestUrl, err := builder.rewriteUrl(getAbsoluteUrl(entry, request))
+	if err != nil {
+		return nil, err
+	}
+
+	scriptRequest := &scriptRequest{
+		Method:         request.Method,
+		Url:            requestUrl,
+		Body:           string(request.Body()),
+		ExpectedStatus: response.Status,
+		Comment:        getMetadataComment(entry),
+	}
+	if builder.opts.Timing && builder.lastTimestamp != 0 && entry.Timestamp > builder.lastTimestamp {
+		scriptRequest.Delay = time.Duration(entry.Timestamp-builder.lastTimestamp) * time.Millisecond
+	}
+	builder.lastTimestamp = entry.Timestamp
+
+	for _, header := range request.Headers {
+		if containsFold(scriptHeaderExcluded, header.Name) {
+			continue
+		}
+		if !containsFold(AuthHeaders, header.Name) {
+			scriptRequest.Headers = append(scriptRequest.Headers, scriptHeader{Name: header.Name, Value: header.Value})
+			continue
+		}
+
+		switch builder.opts.Auth {
+		case AuthKeep:
+			scriptRequest.Headers = append(scriptRequest.Headers, scriptHeader{Name: header.Name, Value: header.Value})
+		case AuthStrip:
+		default:
+			scriptRequest.Headers = append(scriptRequest.Headers, scriptHeader{Name: header.Name, Env: getAuthEnv(header.Name)})
+		}
+	}
+
+	return scriptRequest, nil
+}
+
+// rewriteUrl replaces the scheme and host of a url with those of its target, the path of the target prefixes
+// the path of the url.
+func (builder *scriptBuilder) rewriteUrl(rawUrl string) (string, error) {
+	parsedUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", err
+	}
+
+	target, ok := builder.opts.Targets[parsedUrl.Host]
+	if !ok {
+		target, ok = builder.opts.Targets[parsedUrl.Hostname()]
+	}
+	if !ok {
+		target, ok = builder.opts.Targets[TargetAny]
+	}
+	if !ok {
+		return rawUrl, nil
+	}
+
+	targetUrl, err := url.Parse(target)
+	if err != nil {
+		return "", fmt.Errorf("invalid target %s, %w", target, err)
+	}
+	parsedUrl.Scheme = targetUrl.Scheme
+	parsedUrl.Host = targetUrl.Host
+	parsedUrl.Path = strings.TrimSuffix(targetUrl.Path, "/") + parsedUrl.Path
+	parsedUrl.RawPath = ""
+	return parsedUrl.String(), nil
+}
+
+// getAuthEnv returns the environment variable of an auth header, e.g. X_API_KEY for X-Api-Key.
+func getAuthEnv(headerName string) string {
+	return strings.ToUpper(strings.ReplaceAll(headerName, "-", "_"))
+}
+
+// getScriptHeaderComment describes how a script handles auth headers, for the top of the script.
+func getScriptHeaderComment(opts Options) []string {
+	lines := []string{fmt.Sprintf("Generated by %s %s, replays the exported requests in the order they were captured.", opts.CreatorName, opts.CreatorVersion)}
+	if opts.Script.Auth == AuthTemplate || opts.Script.Auth == "" {
+		var envs []string
+		for _, header := range AuthHeaders {
+			envs = append(envs, getAuthEnv(header))
+		}
+		lines = append(lines, fmt.Sprintf("Auth headers are read from the environment variables %s.", strings.Join(envs, ", ")))
+	}
+	return lines
+}
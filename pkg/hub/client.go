@@ -0,0 +1,174 @@
+// Package hub is a client for the API of the Kubeshark Hub.
+package hub
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultRetries    = 3
+	DefaultRetryDelay = 500 * time.Millisecond
+	DefaultTimeout    = 30 * time.Second
+)
+
+// Client calls the Hub API at a base url, e.g. the local proxy the CLI started or an exposed hub.
+type Client struct {
+	url        *url.URL
+	httpClient *http.Client
+	header     http.Header
+	tlsConfig  *tls.Config
+	retries    int
+	retryDelay time.Duration
+}
+
+type Option func(client *Client)
+
+// WithHTTPClient replaces the default http client, e.g. to trust the certificate of the local proxy.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) {
+		client.httpClient = httpClient
+	}
+}
+
+// WithTLSConfig sets the TLS config of the requests and the live stream, e.g. to trust the certificate of the
+// local proxy. It's ignored by requests when the http client is replaced.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(client *Client) {
+		client.tlsConfig = tlsConfig
+	}
+}
+
+// WithHeader adds a header to every request, e.g. the token of the local proxy.
+func WithHeader(name string, value string) Option {
+	return func(client *Client) {
+		client.header.Set(name, value)
+	}
+}
+
+// WithRetries sets how many times a request is retried after a network error or an unavailable hub, the
+// delay doubles after every retry.
+func WithRetries(retries int, delay time.Duration) Option {
+	return func(client *Client) {
+		client.retries = retries
+		client.retryDelay = delay
+	}
+}
+
+func NewClient(hubUrl string, opts ...Option) (*Client, error) {
+	parsedUrl, err := url.Parse(strings.TrimSuffix(hubUrl, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid hub url %s, %w", hubUrl, err)
+	}
+	if parsedUrl.Scheme != "http" && parsedUrl.Scheme != "https" {
+		return nil, fmt.Errorf("invalid hub url %s, the scheme must be http or https", hubUrl)
+	}
+
+	client := &Client{
+		url:        parsedUrl,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		header:     http.Header{},
+		retries:    DefaultRetries,
+		retryDelay: DefaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	if client.tlsConfig != nil && client.httpClient.Transport == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = client.tlsConfig
+		client.httpClient.Transport = transport
+	}
+
+	return client, nil
+}
+
+// Url returns the base url of the hub.
+func (client *Client) Url() string {
+	return client.url.String()
+}
+
+// Echo checks the hub is reachable.
+func (client *Client) Echo(ctx context.Context) error {
+	response, err := client.do(ctx, http.MethodGet, "/echo", nil)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	return nil
+}
+
+func (client *Client) endpoint(path string, query url.Values) string {
+	endpoint := *client.url
+	endpoint.Path = client.url.Path + path
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String()
+}
+
+// do sends a request and retries it while the hub is unreachable or unavailable. The caller closes the body
+// of the returned response, responses other than 200 are returned as a *StatusError.
+func (client *Client) do(ctx context.Context, method string, path string, query url.Values) (*http.Response, error) {
+	delay := client.retryDelay
+	for attempt := 0; ; attempt++ {
+		response, err := client.doOnce(ctx, method, path, query)
+		if err == nil || attempt >= client.retries || !isRetryable(ctx, err) {
+			return response, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (client *Client) doOnce(ctx context.Context, method string, path string, query url.Values) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, client.endpoint(path, query), nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range client.header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Accept", "application/json")
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(response.Body, maxErrorBodyLength))
+		return nil, &StatusError{
+			Method:     method,
+			Path:       path,
+			StatusCode: response.StatusCode,
+			Body:       strings.TrimSpace(string(body)),
+		}
+	}
+
+	return response, nil
+}
+
+func (client *Client) getJson(ctx context.Context, path string, query url.Values, result interface{}) error {
+	response, err := client.do(ctx, http.MethodGet, path, query)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return &DecodeError{Path: path, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func newFakeHub(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL, WithRetries(2, time.Millisecond), WithHeader("X-Test", "token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func writeJson(t *testing.T, w http.ResponseWriter, value interface{}) {
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestQueryEntries(t *testing.T) {
+	client := newFakeHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/entries" || r.Header.Get("X-Test") != "token" {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		if query.Get("query") != `http and response.status == 500` || query.Get("leftOff") != "latest" || query.Get("direction") != "-1" || query.Get("limit") != "10" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		writeJson(t, w, EntriesResponse{Data: []*BaseEntry{{Id: "1", Status: 500}}, Meta: QueryMetadata{LeftOff: "1"}})
+	}))
+
+	response, err := client.QueryEntries(context.Background(), EntriesQuery{Query: `http and response.status == 500`, Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Data) != 1 || response.Data[0].Id != "1" || response.Meta.LeftOff != "1" {
+		t.Errorf("unexpected response %+v", response)
+	}
+}
+
+func TestGetEntryNotFound(t *testing.T) {
+	client := newFakeHub(t, http.NotFoundHandler())
+
+	_, err := client.GetEntry(context.Background(), "missing", "")
+	if !IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	attempts := 0
+	client := newFakeHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writeJson(t, w, TapStatus{Pods: []PodInfo{{Namespace: "default", Name: "front"}}})
+	}))
+
+	pods, err := client.GetTappedPods(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 || len(pods) != 1 || pods[0].Name != "front" {
+		t.Errorf("unexpected pods %+v after %d attempts", pods, attempts)
+	}
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	attempts := 0
+	client := newFakeHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+
+	if _, err := client.GetServiceMap(context.Background()); !IsUnauthorized(err) {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+	if attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestStreamEntries(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	client := newFakeHub(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		if _, query, err := conn.ReadMessage(); err != nil || string(query) != "http" {
+			t.Errorf("unexpected query %q, err: %v", query, err)
+			return
+		}
+		_ = conn.WriteJSON(map[string]interface{}{"messageType": MessageTypeQueryMetadata, "data": map[string]interface{}{"current": 1}})
+		_ = conn.WriteJSON(map[string]interface{}{"messageType": MessageTypeEntry, "data": BaseEntry{Id: "7", Method: "GET"}})
+		_, _, _ = conn.ReadMessage()
+	}))
+
+	stream, err := client.StreamEntries(context.Background(), "http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+
+	entry, err := stream.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Id != "7" || entry.Method != "GET" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+}
@@ -0,0 +1,123 @@
+package hub
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+type Direction int
+
+const (
+	// DirectionBackward pages from the newest entries to the oldest ones
+	DirectionBackward Direction = -1
+	DirectionForward  Direction = 1
+)
+
+const DefaultEntriesLimit = 100
+
+// EntriesQuery selects a page of entries with a KFL filter. A page continues from the LeftOff of the previous
+// one, an empty LeftOff starts from the newest entry when paging backward and from the oldest one otherwise.
+type EntriesQuery struct {
+	Query     string
+	LeftOff   string
+	Direction Direction
+	Limit     int
+	TimeoutMs int
+}
+
+func (query *EntriesQuery) values() url.Values {
+	values := url.Values{}
+	values.Set("query", query.Query)
+
+	leftOff := query.LeftOff
+	if leftOff == "" {
+		leftOff = "latest"
+		if query.Direction == DirectionForward {
+			leftOff = "0"
+		}
+	}
+	values.Set("leftOff", leftOff)
+
+	direction := query.Direction
+	if direction == 0 {
+		direction = DirectionBackward
+	}
+	values.Set("direction", strconv.Itoa(int(direction)))
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultEntriesLimit
+	}
+	values.Set("limit", strconv.Itoa(limit))
+
+	if query.TimeoutMs > 0 {
+		values.Set("timeoutMs", strconv.Itoa(query.TimeoutMs))
+	}
+
+	return values
+}
+
+// QueryEntries returns a page of the entries that match a KFL filter.
+func (client *Client) QueryEntries(ctx context.Context, query EntriesQuery) (*EntriesResponse, error) {
+	var response EntriesResponse
+	if err := client.getJson(ctx, "/entries", query.values(), &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetEntry returns a single entry, query highlights the parts of the entry that match a KFL filter.
+func (client *Client) GetEntry(ctx context.Context, id string, query string) (*EntryWrapper, error) {
+	values := url.Values{}
+	if query != "" {
+		values.Set("query", query)
+	}
+
+	var entry EntryWrapper
+	if err := client.getJson(ctx, "/entries/"+url.PathEscape(id), values, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// WalkBaseEntries pages through the entries that match a KFL filter from the oldest one, and calls walk with every
+// entry summary, until walk returns an error. A single page is held in memory.
+func (client *Client) WalkBaseEntries(ctx context.Context, query string, walk func(entry *BaseEntry) error) error {
+	var leftOff string
+	for {
+		page, err := client.QueryEntries(ctx, EntriesQuery{
+			Query:     query,
+			LeftOff:   leftOff,
+			Direction: DirectionForward,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, baseEntry := range page.Data {
+			if err := walk(baseEntry); err != nil {
+				return err
+			}
+		}
+
+		if len(page.Data) == 0 || page.Meta.LeftOff == "" || page.Meta.LeftOff == leftOff {
+			return nil
+		}
+		leftOff = page.Meta.LeftOff
+	}
+}
+
+// WalkEntries is WalkBaseEntries with the full entries.
+func (client *Client) WalkEntries(ctx context.Context, query string, walk func(entry *Entry) error) error {
+	return client.WalkBaseEntries(ctx, query, func(baseEntry *BaseEntry) error {
+		entry, err := client.GetEntry(ctx, baseEntry.Id, "")
+		if err != nil {
+			return err
+		}
+		if entry.Data == nil {
+			return nil
+		}
+		return walk(entry.Data)
+	})
+}
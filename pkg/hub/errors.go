@@ -0,0 +1,75 @@
+package hub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const maxErrorBodyLength = 4096
+
+// StatusError is returned when the hub answers with a status other than 200.
+type StatusError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+func (err *StatusError) Error() string {
+	if err.Body == "" {
+		return fmt.Sprintf("%s %s: hub returned status %d", err.Method, err.Path, err.StatusCode)
+	}
+	return fmt.Sprintf("%s %s: hub returned status %d: %s", err.Method, err.Path, err.StatusCode, err.Body)
+}
+
+// DecodeError is returned when the response of the hub isn't what the client expects, usually because the
+// hub runs a different version.
+type DecodeError struct {
+	Path string
+	Err  error
+}
+
+func (err *DecodeError) Error() string {
+	return fmt.Sprintf("failed decoding the response of %s: %v", err.Path, err.Err)
+}
+
+func (err *DecodeError) Unwrap() error {
+	return err.Err
+}
+
+func hasStatus(err error, statusCodes ...int) bool {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	for _, statusCode := range statusCodes {
+		if statusErr.StatusCode == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether the hub, or the proxy in front of it, rejected the credentials.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized, http.StatusForbidden)
+}
+
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return hasStatus(err, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+	}
+	// Anything but a status is a failure to reach the hub
+	return true
+}
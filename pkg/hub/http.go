@@ -0,0 +1,154 @@
+package hub
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const ProtocolHttp = "http"
+
+// The request and response of HTTP entries follow HAR.
+
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NameValues are headers, query params or cookies. The hub keeps them as a list, as HAR does, or as an object
+// KFL can index by name, both decode to a list.
+type NameValues []NameValue
+
+func (nameValues *NameValues) UnmarshalJSON(data []byte) error {
+	var list []NameValue
+	if err := json.Unmarshal(data, &list); err == nil {
+		*nameValues = list
+		return nil
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("expected a list or an object of names and values, %w", err)
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list = make([]NameValue, 0, len(object))
+	for _, name := range names {
+		switch value := object[name].(type) {
+		case []interface{}:
+			for _, item := range value {
+				list = append(list, NameValue{Name: name, Value: fmt.Sprint(item)})
+			}
+		default:
+			list = append(list, NameValue{Name: name, Value: fmt.Sprint(value)})
+		}
+	}
+	*nameValues = list
+	return nil
+}
+
+// Get returns the first value of name, names are compared case insensitively as header names are.
+func (nameValues NameValues) Get(name string) string {
+	for _, nameValue := range nameValues {
+		if strings.EqualFold(nameValue.Name, name) {
+			return nameValue.Value
+		}
+	}
+	return ""
+}
+
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HttpRequest struct {
+	Method      string     `json:"method"`
+	Url         string     `json:"url"`
+	HttpVersion string     `json:"httpVersion"`
+	Cookies     NameValues `json:"cookies"`
+	Headers     NameValues `json:"headers"`
+	QueryString NameValues `json:"queryString"`
+	PostData    *PostData  `json:"postData,omitempty"`
+	HeadersSize int64      `json:"headersSize"`
+	BodySize    int64      `json:"bodySize"`
+}
+
+func (request *HttpRequest) Body() []byte {
+	if request.PostData == nil {
+		return nil
+	}
+	return []byte(request.PostData.Text)
+}
+
+type Content struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HttpResponse struct {
+	Status      int        `json:"status"`
+	StatusText  string     `json:"statusText"`
+	HttpVersion string     `json:"httpVersion"`
+	Cookies     NameValues `json:"cookies"`
+	Headers     NameValues `json:"headers"`
+	Content     *Content   `json:"content,omitempty"`
+	RedirectUrl string     `json:"redirectURL"`
+	HeadersSize int64      `json:"headersSize"`
+	BodySize    int64      `json:"bodySize"`
+}
+
+// Body returns the decoded body of the response.
+func (response *HttpResponse) Body() ([]byte, error) {
+	if response.Content == nil {
+		return nil, nil
+	}
+	if response.Content.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(response.Content.Text)
+	}
+	return []byte(response.Content.Text), nil
+}
+
+func (entry *Entry) IsHttp() bool {
+	return entry.Protocol.Name == ProtocolHttp
+}
+
+func (entry *Entry) GetHttpRequest() (*HttpRequest, error) {
+	var request HttpRequest
+	if err := convertPart(entry, entry.Request, &request); err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (entry *Entry) GetHttpResponse() (*HttpResponse, error) {
+	var response HttpResponse
+	if err := convertPart(entry, entry.Response, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func convertPart(entry *Entry, part map[string]interface{}, result interface{}) error {
+	if !entry.IsHttp() {
+		return fmt.Errorf("entry %s is %s, not %s", entry.Id, entry.Protocol.Name, ProtocolHttp)
+	}
+
+	data, err := json.Marshal(part)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return &DecodeError{Path: "/entries/" + entry.Id, Err: err}
+	}
+	return nil
+}
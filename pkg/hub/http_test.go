@@ -0,0 +1,28 @@
+package hub
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNameValuesUnmarshal(t *testing.T) {
+	tests := []struct {
+		Name string
+		Json string
+	}{
+		{Name: "list", Json: `[{"name":"Accept","value":"*/*"},{"name":"X-Id","value":"1"}]`},
+		{Name: "object", Json: `{"X-Id":"1","Accept":"*/*"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var nameValues NameValues
+			if err := json.Unmarshal([]byte(test.Json), &nameValues); err != nil {
+				t.Fatal(err)
+			}
+			if len(nameValues) != 2 || nameValues.Get("accept") != "*/*" || nameValues.Get("x-id") != "1" {
+				t.Errorf("unexpected name values %+v", nameValues)
+			}
+		})
+	}
+}
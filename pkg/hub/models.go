@@ -0,0 +1,139 @@
+package hub
+
+import "encoding/json"
+
+type Protocol struct {
+	Name            string   `json:"name"`
+	LongName        string   `json:"longName"`
+	Abbreviation    string   `json:"abbr"`
+	Version         string   `json:"version"`
+	BackgroundColor string   `json:"backgroundColor"`
+	ForegroundColor string   `json:"foregroundColor"`
+	FontSize        int      `json:"fontSize"`
+	ReferenceLink   string   `json:"referenceLink"`
+	Ports           []string `json:"ports"`
+	Priority        int      `json:"priority"`
+}
+
+type TCP struct {
+	IP   string `json:"ip"`
+	Port string `json:"port"`
+	Name string `json:"name"`
+}
+
+// BaseEntry is the summary of an entry, as listed by queries and streamed live.
+type BaseEntry struct {
+	Id           string   `json:"id"`
+	Protocol     Protocol `json:"proto"`
+	Capture      string   `json:"capture"`
+	Summary      string   `json:"summary"`
+	SummaryQuery string   `json:"summaryQuery"`
+	Status       int      `json:"status"`
+	StatusQuery  string   `json:"statusQuery"`
+	Method       string   `json:"method"`
+	MethodQuery  string   `json:"methodQuery"`
+	Timestamp    int64    `json:"timestamp"`
+	Source       *TCP     `json:"src"`
+	Destination  *TCP     `json:"dst"`
+	IsOutgoing   bool     `json:"isOutgoing"`
+	Latency      int64    `json:"latency"`
+}
+
+// Entry is a full entry, its request and response are protocol specific.
+type Entry struct {
+	Id          string                 `json:"id"`
+	Protocol    Protocol               `json:"protocol"`
+	Capture     string                 `json:"capture"`
+	Source      *TCP                   `json:"src"`
+	Destination *TCP                   `json:"dst"`
+	Namespace   string                 `json:"namespace"`
+	Outgoing    bool                   `json:"outgoing"`
+	Timestamp   int64                  `json:"timestamp"`
+	StartTime   string                 `json:"startTime"`
+	Request     map[string]interface{} `json:"request"`
+	Response    map[string]interface{} `json:"response"`
+	ElapsedTime int64                  `json:"elapsedTime"`
+}
+
+// EntryWrapper is a single entry as the hub returns it, with its representation for display.
+type EntryWrapper struct {
+	Protocol       Protocol        `json:"protocol"`
+	Representation string          `json:"representation"`
+	Data           *Entry          `json:"data"`
+	Base           *BaseEntry      `json:"base"`
+	IsRulesEnabled bool            `json:"isRulesEnabled"`
+	RulesMatched   json.RawMessage `json:"rulesMatched,omitempty"`
+}
+
+// QueryMetadata describes the page of entries a query returned.
+type QueryMetadata struct {
+	Current            int    `json:"current"`
+	Total              int    `json:"total"`
+	NumberOfWritten    int    `json:"numberOfWritten"`
+	LeftOff            string `json:"leftOff"`
+	TruncatedTimestamp int64  `json:"truncatedTimestamp"`
+}
+
+type EntriesResponse struct {
+	Data []*BaseEntry  `json:"data"`
+	Meta QueryMetadata `json:"meta"`
+}
+
+type PodInfo struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	NodeName  string `json:"nodeName"`
+}
+
+type TLSLinkInfo struct {
+	SourceIP                string `json:"sourceIP"`
+	DestinationAddress      string `json:"destinationAddress"`
+	ResolvedDestinationName string `json:"resolvedDestinationName"`
+	ResolvedSourceName      string `json:"resolvedSourceName"`
+}
+
+type TapStatus struct {
+	Pods     []PodInfo     `json:"pods"`
+	TLSLinks []TLSLinkInfo `json:"tlsLinks"`
+}
+
+type TapperStatus struct {
+	TapperName string `json:"tapperName"`
+	NodeName   string `json:"nodeName"`
+	Status     string `json:"status"`
+}
+
+type ServiceMapNode struct {
+	Id       int       `json:"id"`
+	Name     string    `json:"name"`
+	Entry    *TCP      `json:"entry"`
+	Resolved bool      `json:"resolved"`
+	Count    int       `json:"count"`
+	Protocol *Protocol `json:"protocol,omitempty"`
+}
+
+type ServiceMapEdge struct {
+	Source      ServiceMapNode `json:"source"`
+	Destination ServiceMapNode `json:"destination"`
+	Count       int            `json:"count"`
+	Protocol    *Protocol      `json:"protocol"`
+}
+
+type ServiceMapStatus struct {
+	Status                string `json:"status"`
+	EntriesProcessedCount int    `json:"entriesProcessedCount"`
+	NodeCount             int    `json:"nodeCount"`
+	EdgeCount             int    `json:"edgeCount"`
+}
+
+type ServiceMap struct {
+	Status ServiceMapStatus `json:"status"`
+	Nodes  []ServiceMapNode `json:"nodes"`
+	Edges  []ServiceMapEdge `json:"edges"`
+}
+
+// OASService is a service the hub builds an OpenAPI spec for from its traffic.
+type OASService struct {
+	Service string `json:"service"`
+	Entries int    `json:"entries"`
+}
@@ -0,0 +1,34 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+// GetOASServices lists the services the hub has OpenAPI specs for.
+func (client *Client) GetOASServices(ctx context.Context) ([]OASService, error) {
+	var services []OASService
+	if err := client.getJson(ctx, "/oas/", nil, &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// GetOASSpec returns the OpenAPI spec of a service as the hub built it.
+func (client *Client) GetOASSpec(ctx context.Context, service string) (json.RawMessage, error) {
+	var spec json.RawMessage
+	if err := client.getJson(ctx, "/oas/"+url.PathEscape(service), nil, &spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// GetAllOASSpecs returns the OpenAPI specs of all the services, by service.
+func (client *Client) GetAllOASSpecs(ctx context.Context) (map[string]json.RawMessage, error) {
+	var specs map[string]json.RawMessage
+	if err := client.getJson(ctx, "/oas/all", nil, &specs); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
@@ -0,0 +1,46 @@
+package hub
+
+import "context"
+
+// GetTapStatus returns the tapped pods and the TLS links the tappers found.
+func (client *Client) GetTapStatus(ctx context.Context) (*TapStatus, error) {
+	var status TapStatus
+	if err := client.getJson(ctx, "/status/tap", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetTappedPods returns the pods the tappers capture.
+func (client *Client) GetTappedPods(ctx context.Context) ([]PodInfo, error) {
+	status, err := client.GetTapStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return status.Pods, nil
+}
+
+// GetTapperStatus returns the last status each tapper reported.
+func (client *Client) GetTapperStatus(ctx context.Context) ([]TapperStatus, error) {
+	var statuses []TapperStatus
+	if err := client.getJson(ctx, "/status/tapperStatus", nil, &statuses); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+func (client *Client) GetServiceMap(ctx context.Context) (*ServiceMap, error) {
+	var serviceMap ServiceMap
+	if err := client.getJson(ctx, "/servicemap/get", nil, &serviceMap); err != nil {
+		return nil, err
+	}
+	return &serviceMap, nil
+}
+
+func (client *Client) GetServiceMapStatus(ctx context.Context) (*ServiceMapStatus, error) {
+	var status ServiceMapStatus
+	if err := client.getJson(ctx, "/servicemap/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
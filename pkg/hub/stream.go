@@ -0,0 +1,85 @@
+package hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	MessageTypeEntry         = "entry"
+	MessageTypeQueryMetadata = "queryMetadata"
+	MessageTypeToast         = "toast"
+)
+
+type streamMessage struct {
+	MessageType string          `json:"messageType"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// EntryStream receives the entries that match a KFL filter as the hub captures them.
+type EntryStream struct {
+	conn      *websocket.Conn
+	closeOnce sync.Once
+	stop      chan struct{}
+}
+
+// StreamEntries opens the live stream of the entries that match query, cancelling ctx closes the stream.
+func (client *Client) StreamEntries(ctx context.Context, query string) (*EntryStream, error) {
+	conn, err := client.dialWebsocket(ctx, "/ws")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(query)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed sending the query to the hub, %w", err)
+	}
+
+	stream := &EntryStream{conn: conn, stop: make(chan struct{})}
+	go func() {
+		select {
+		case <-ctx.Done():
+			stream.Close()
+		case <-stream.stop:
+		}
+	}()
+
+	return stream, nil
+}
+
+// Next blocks until the next entry arrives, it returns an error once the stream is closed.
+func (stream *EntryStream) Next() (*BaseEntry, error) {
+	for {
+		_, data, err := stream.conn.ReadMessage()
+		if err != nil {
+			return nil, err
+		}
+
+		var message streamMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			return nil, &DecodeError{Path: "/ws", Err: err}
+		}
+		if message.MessageType != MessageTypeEntry {
+			continue
+		}
+
+		var entry BaseEntry
+		if err := json.Unmarshal(message.Data, &entry); err != nil {
+			return nil, &DecodeError{Path: "/ws", Err: err}
+		}
+		return &entry, nil
+	}
+}
+
+func (stream *EntryStream) Close() error {
+	var err error
+	stream.closeOnce.Do(func() {
+		close(stream.stop)
+		err = stream.conn.Close()
+	})
+	return err
+}
@@ -0,0 +1,155 @@
+package hub
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// The workers send the pairs they capture to the hub over its tapper websocket, which the hub then dissects and
+// stores like live traffic. Imports use the same path.
+
+const messageTypeTappedEntry = "tappedEntry"
+
+// HttpProtocol is the protocol the hub dissects HTTP/1.x pairs with.
+var HttpProtocol = Protocol{
+	Name:            ProtocolHttp,
+	LongName:        "Hypertext Transfer Protocol -- HTTP/1.1",
+	Abbreviation:    "HTTP",
+	Version:         "1.1",
+	BackgroundColor: "#205cf5",
+	ForegroundColor: "#ffffff",
+	FontSize:        12,
+	ReferenceLink:   "https://datatracker.ietf.org/doc/html/rfc2616",
+	Ports:           []string{"80", "443", "8080"},
+	Priority:        0,
+}
+
+type ConnectionInfo struct {
+	ClientIP   string `json:"ClientIP"`
+	ClientPort string `json:"ClientPort"`
+	ServerIP   string `json:"ServerIP"`
+	ServerPort string `json:"ServerPort"`
+	IsOutgoing bool   `json:"IsOutgoing"`
+}
+
+type GenericMessage struct {
+	IsRequest   bool        `json:"isRequest"`
+	CaptureTime time.Time   `json:"captureTime"`
+	CaptureSize int         `json:"captureSize"`
+	Payload     interface{} `json:"payload"`
+}
+
+type RequestResponsePair struct {
+	Request  GenericMessage `json:"request"`
+	Response GenericMessage `json:"response"`
+}
+
+// TappedEntry is a request and response pair as the workers send it.
+type TappedEntry struct {
+	Protocol       Protocol             `json:"Protocol"`
+	Capture        string               `json:"Capture"`
+	Timestamp      int64                `json:"Timestamp"`
+	ConnectionInfo *ConnectionInfo      `json:"ConnectionInfo"`
+	Pair           *RequestResponsePair `json:"Pair"`
+	Namespace      string               `json:"Namespace"`
+}
+
+type httpPayload struct {
+	Details interface{} `json:"details"`
+}
+
+// NewHttpTappedEntry returns the pair of an HTTP request and its response, sent at startTime and answered
+// after elapsed.
+func NewHttpTappedEntry(connectionInfo *ConnectionInfo, namespace string, capture string, startTime time.Time, elapsed time.Duration, request *HttpRequest, response *HttpResponse) *TappedEntry {
+	return &TappedEntry{
+		Protocol:       HttpProtocol,
+		Capture:        capture,
+		Timestamp:      startTime.UnixMilli(),
+		ConnectionInfo: connectionInfo,
+		Pair: &RequestResponsePair{
+			Request: GenericMessage{
+				IsRequest:   true,
+				CaptureTime: startTime,
+				CaptureSize: len(request.Body()),
+				Payload:     httpPayload{Details: request},
+			},
+			Response: GenericMessage{
+				CaptureTime: startTime.Add(elapsed),
+				CaptureSize: getBodySize(response),
+				Payload:     httpPayload{Details: response},
+			},
+		},
+		Namespace: namespace,
+	}
+}
+
+// getBodySize returns the body size of a response, computed from its content when it's unknown, which HAR
+// records as -1.
+func getBodySize(response *HttpResponse) int {
+	if response.BodySize >= 0 {
+		return int(response.BodySize)
+	}
+	if body, err := response.Body(); err == nil {
+		return len(body)
+	}
+	return 0
+}
+
+type tappedEntryMessage struct {
+	MessageType string       `json:"messageType"`
+	Data        *TappedEntry `json:"data"`
+}
+
+// TapperStream sends pairs to the hub as a worker does.
+type TapperStream struct {
+	conn *websocket.Conn
+}
+
+func (client *Client) OpenTapperStream(ctx context.Context) (*TapperStream, error) {
+	conn, err := client.dialWebsocket(ctx, "/wsTapper")
+	if err != nil {
+		return nil, err
+	}
+	return &TapperStream{conn: conn}, nil
+}
+
+func (stream *TapperStream) Send(entry *TappedEntry) error {
+	if err := stream.conn.WriteJSON(&tappedEntryMessage{MessageType: messageTypeTappedEntry, Data: entry}); err != nil {
+		return fmt.Errorf("failed sending a pair to the hub, %w", err)
+	}
+	return nil
+}
+
+// Close ends the stream gracefully, so the hub receives every pair sent before.
+func (stream *TapperStream) Close() error {
+	deadline := time.Now().Add(time.Second)
+	_ = stream.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), deadline)
+	return stream.conn.Close()
+}
+
+func (client *Client) dialWebsocket(ctx context.Context, path string) (*websocket.Conn, error) {
+	websocketUrl := *client.url
+	switch websocketUrl.Scheme {
+	case "https":
+		websocketUrl.Scheme = "wss"
+	default:
+		websocketUrl.Scheme = "ws"
+	}
+	websocketUrl.Path = client.url.Path + path
+
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = client.tlsConfig
+
+	conn, response, err := dialer.DialContext(ctx, websocketUrl.String(), client.header.Clone())
+	if err != nil {
+		if response != nil && response.StatusCode != http.StatusSwitchingProtocols {
+			return nil, &StatusError{Method: http.MethodGet, Path: path, StatusCode: response.StatusCode}
+		}
+		return nil, fmt.Errorf("failed connecting to %s of the hub, %w", path, err)
+	}
+	return conn, nil
+}
@@ -0,0 +1,28 @@
+package hub
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewHttpTappedEntryCaptureSize(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Response *HttpResponse
+		Expected int
+	}{
+		{Name: "known", Response: &HttpResponse{BodySize: 4, Content: &Content{Text: "created"}}, Expected: 4},
+		{Name: "unknown", Response: &HttpResponse{BodySize: -1, Content: &Content{Text: "Y3JlYXRlZA==", Encoding: "base64"}}, Expected: 7},
+		{Name: "unknown without content", Response: &HttpResponse{BodySize: -1}, Expected: 0},
+		{Name: "invalid content", Response: &HttpResponse{BodySize: -1, Content: &Content{Text: "!", Encoding: "base64"}}, Expected: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			entry := NewHttpTappedEntry(&ConnectionInfo{}, "", "", time.Now(), time.Millisecond, &HttpRequest{}, test.Response)
+			if size := entry.Pair.Response.CaptureSize; size != test.Expected {
+				t.Errorf("unexpected capture size - expected: %v, actual: %v", test.Expected, size)
+			}
+		})
+	}
+}
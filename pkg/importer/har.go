@@ -0,0 +1,150 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+type harEntry struct {
+	StartedDateTime string            `json:"startedDateTime"`
+	Time            float64           `json:"time"`
+	Request         *hub.HttpRequest  `json:"request"`
+	Response        *hub.HttpResponse `json:"response"`
+	ServerIPAddress string            `json:"serverIPAddress"`
+}
+
+// harReader streams the entries of a HAR file, so large files aren't held in memory.
+type harReader struct {
+	decoder   *json.Decoder
+	inEntries bool
+	done      bool
+}
+
+func newHarReader(in io.Reader) *harReader {
+	return &harReader{decoder: json.NewDecoder(in)}
+}
+
+func (reader *harReader) Next() (*Pair, error) {
+	if reader.done {
+		return nil, io.EOF
+	}
+	if !reader.inEntries {
+		if err := reader.seekEntries(); err != nil {
+			return nil, err
+		}
+		reader.inEntries = true
+	}
+
+	if !reader.decoder.More() {
+		reader.done = true
+		return nil, io.EOF
+	}
+
+	var entry harEntry
+	if err := reader.decoder.Decode(&entry); err != nil {
+		return nil, fmt.Errorf("invalid HAR entry, %w", err)
+	}
+	return convertHarEntry(&entry)
+}
+
+// seekEntries moves the decoder into the log.entries array.
+func (reader *harReader) seekEntries() error {
+	for _, key := range []string{"log", "entries"} {
+		if err := reader.expectDelim('{'); err != nil {
+			return err
+		}
+		if err := reader.seekKey(key); err != nil {
+			return err
+		}
+	}
+	return reader.expectDelim('[')
+}
+
+func (reader *harReader) seekKey(key string) error {
+	for reader.decoder.More() {
+		token, err := reader.decoder.Token()
+		if err != nil {
+			return fmt.Errorf("invalid HAR file, %w", err)
+		}
+		if token == key {
+			return nil
+		}
+
+		var skipped json.RawMessage
+		if err := reader.decoder.Decode(&skipped); err != nil {
+			return fmt.Errorf("invalid HAR file, %w", err)
+		}
+	}
+	return fmt.Errorf("invalid HAR file, %s not found", key)
+}
+
+func (reader *harReader) expectDelim(delim json.Delim) error {
+	token, err := reader.decoder.Token()
+	if err != nil {
+		return fmt.Errorf("invalid HAR file, %w", err)
+	}
+	if token != delim {
+		return fmt.Errorf("invalid HAR file, expected %v but found %v", delim, token)
+	}
+	return nil
+}
+
+func convertHarEntry(entry *harEntry) (*Pair, error) {
+	if entry.Request == nil || entry.Response == nil {
+		return nil, fmt.Errorf("invalid HAR entry, both a request and a response are required")
+	}
+
+	startTime, err := time.Parse(time.RFC3339Nano, entry.StartedDateTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid startedDateTime of HAR entry, %w", err)
+	}
+
+	requestUrl, err := url.Parse(entry.Request.Url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url of HAR entry, %w", err)
+	}
+
+	// HAR files hold the address of the server only, when the browser recorded it
+	serverIP := entry.ServerIPAddress
+	if serverIP == "" {
+		serverIP = requestUrl.Hostname()
+	}
+	if net.ParseIP(serverIP) == nil {
+		serverIP = ""
+	}
+	serverPort := requestUrl.Port()
+	if serverPort == "" {
+		serverPort = defaultPort(requestUrl.Scheme)
+	}
+
+	// The hub keeps the path of requests and their host in the Host header, as the workers see them
+	if requestUrl.IsAbs() {
+		if entry.Request.Headers.Get("Host") == "" {
+			entry.Request.Headers = append(entry.Request.Headers, hub.NameValue{Name: "Host", Value: requestUrl.Host})
+		}
+		entry.Request.Url = requestUrl.RequestURI()
+	}
+	encodeResponseContent(entry.Response)
+
+	return &Pair{
+		ServerIP:   serverIP,
+		ServerPort: serverPort,
+		StartTime:  startTime,
+		Elapsed:    time.Duration(entry.Time * float64(time.Millisecond)),
+		Request:    entry.Request,
+		Response:   entry.Response,
+	}, nil
+}
+
+func defaultPort(scheme string) string {
+	if scheme == "https" {
+		return "443"
+	}
+	return "80"
+}
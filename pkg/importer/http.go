@@ -0,0 +1,105 @@
+package importer
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+// encodeResponseContent encodes response bodies in base64, as the workers send them.
+func encodeResponseContent(response *hub.HttpResponse) {
+	if response.Content == nil {
+		response.Content = &hub.Content{}
+	}
+	if response.Content.Encoding == "base64" {
+		return
+	}
+	response.Content.Text = base64.StdEncoding.EncodeToString([]byte(response.Content.Text))
+	response.Content.Encoding = "base64"
+}
+
+func getHttpRequest(request *http.Request) (*hub.HttpRequest, error) {
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := getHeaders(request.Header)
+	if request.Host != "" && request.Header.Get("Host") == "" {
+		headers = append(hub.NameValues{{Name: "Host", Value: request.Host}}, headers...)
+	}
+
+	httpRequest := &hub.HttpRequest{
+		Method:      request.Method,
+		Url:         request.RequestURI,
+		HttpVersion: request.Proto,
+		Cookies:     hub.NameValues{},
+		Headers:     headers,
+		QueryString: hub.NameValues{},
+		HeadersSize: -1,
+		BodySize:    int64(len(body)),
+	}
+	for _, cookie := range request.Cookies() {
+		httpRequest.Cookies = append(httpRequest.Cookies, hub.NameValue{Name: cookie.Name, Value: cookie.Value})
+	}
+	query := request.URL.Query()
+	for _, name := range sortedKeys(query) {
+		for _, value := range query[name] {
+			httpRequest.QueryString = append(httpRequest.QueryString, hub.NameValue{Name: name, Value: value})
+		}
+	}
+	if len(body) > 0 {
+		httpRequest.PostData = &hub.PostData{MimeType: request.Header.Get("Content-Type"), Text: string(body)}
+	}
+	return httpRequest, nil
+}
+
+func getHttpResponse(response *http.Response) (*hub.HttpResponse, error) {
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResponse := &hub.HttpResponse{
+		Status:      response.StatusCode,
+		StatusText:  http.StatusText(response.StatusCode),
+		HttpVersion: response.Proto,
+		Cookies:     hub.NameValues{},
+		Headers:     getHeaders(response.Header),
+		Content: &hub.Content{
+			Size:     int64(len(body)),
+			MimeType: response.Header.Get("Content-Type"),
+			Text:     string(body),
+		},
+		RedirectUrl: response.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    int64(len(body)),
+	}
+	for _, cookie := range response.Cookies() {
+		httpResponse.Cookies = append(httpResponse.Cookies, hub.NameValue{Name: cookie.Name, Value: cookie.Value})
+	}
+	encodeResponseContent(httpResponse)
+	return httpResponse, nil
+}
+
+func getHeaders(header http.Header) hub.NameValues {
+	headers := hub.NameValues{}
+	for _, name := range sortedKeys(header) {
+		for _, value := range header[name] {
+			headers = append(headers, hub.NameValue{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+func sortedKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,70 @@
+// Package importer reads the HTTP traffic of HAR and pcap files as request and response pairs the hub can ingest.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+const (
+	FormatHar  = "har"
+	FormatPcap = "pcap"
+)
+
+var Formats = []string{FormatHar, FormatPcap}
+
+// sniffLength is enough to skip the whitespace HAR files may start with.
+const sniffLength = 512
+
+// Pair is an HTTP request and its response. The client address is unknown for HAR files.
+type Pair struct {
+	ClientIP   string
+	ClientPort string
+	ServerIP   string
+	ServerPort string
+	StartTime  time.Time
+	Elapsed    time.Duration
+	Request    *hub.HttpRequest
+	Response   *hub.HttpResponse
+}
+
+// Reader returns the pairs of a file one at a time, and io.EOF after the last one.
+type Reader interface {
+	Next() (*Pair, error)
+}
+
+// NewReader detects whether in is a HAR file, or a pcap or pcapng capture.
+func NewReader(in io.Reader) (Reader, string, error) {
+	buffered := bufio.NewReader(in)
+	head, err := buffered.Peek(sniffLength)
+	if err != nil && err != io.EOF {
+		return nil, "", err
+	}
+
+	if len(head) >= 4 && isPcapMagic(head[:4]) {
+		reader, err := newPcapReader(buffered)
+		return reader, FormatPcap, err
+	}
+	if bytes.HasPrefix(bytes.TrimLeft(head, " \t\r\n\ufeff"), []byte("{")) {
+		return newHarReader(buffered), FormatHar, nil
+	}
+	return nil, "", fmt.Errorf("unknown file format, expected one of %v", Formats)
+}
+
+func isPcapMagic(magic []byte) bool {
+	switch binary.LittleEndian.Uint32(magic) {
+	case pcapMagicMicroseconds, pcapMagicNanoseconds, pcapngSectionHeaderBlock:
+		return true
+	}
+	switch binary.BigEndian.Uint32(magic) {
+	case pcapMagicMicroseconds, pcapMagicNanoseconds:
+		return true
+	}
+	return false
+}
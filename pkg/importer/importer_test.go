@@ -0,0 +1,126 @@
+package importer
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kubeshark/kubeshark/pkg/export"
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+func newHttpEntry(id string, port string) *hub.Entry {
+	return &hub.Entry{
+		Id:          id,
+		Protocol:    hub.Protocol{Name: hub.ProtocolHttp},
+		Source:      &hub.TCP{IP: "10.0.0.1", Port: port},
+		Destination: &hub.TCP{IP: "10.0.0.2", Port: "80"},
+		Timestamp:   1660000000000,
+		ElapsedTime: 12,
+		Request: map[string]interface{}{
+			"method":   "POST",
+			"url":      "/orders?id=" + id,
+			"headers":  map[string]interface{}{"Host": "checkout"},
+			"postData": map[string]interface{}{"mimeType": "application/json", "text": strings.Repeat("x", 3000)},
+		},
+		Response: map[string]interface{}{
+			"status":  201,
+			"headers": map[string]interface{}{"Content-Type": "text/plain"},
+			"content": map[string]interface{}{"mimeType": "text/plain", "text": "created " + id},
+		},
+	}
+}
+
+func readAll(t *testing.T, in io.Reader, expectedFormat string) []*Pair {
+	reader, format, err := NewReader(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != expectedFormat {
+		t.Fatalf("unexpected format - expected: %v, actual: %v", expectedFormat, format)
+	}
+
+	var pairs []*Pair
+	for {
+		pair, err := reader.Next()
+		if err == io.EOF {
+			return pairs
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		pairs = append(pairs, pair)
+	}
+}
+
+func exportEntries(t *testing.T, format string) *bytes.Buffer {
+	var out bytes.Buffer
+	writer, err := export.NewWriter(format, &out, export.Options{CreatorName: "kubeshark", CreatorVersion: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range []*hub.Entry{newHttpEntry("1", "43210"), newHttpEntry("2", "43211")} {
+		if err := writer.Write(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &out
+}
+
+func checkPair(t *testing.T, pair *Pair, id string) {
+	if pair.Request.Method != "POST" || pair.Request.Url != "/orders?id="+id || pair.Request.Headers.Get("Host") != "checkout" {
+		t.Errorf("unexpected request %+v", pair.Request)
+	}
+	if len(pair.Request.Body()) != 3000 {
+		t.Errorf("unexpected request body length %d", len(pair.Request.Body()))
+	}
+	if body, err := pair.Response.Body(); err != nil || string(body) != "created "+id || pair.Response.Status != 201 {
+		t.Errorf("unexpected response %+v, body: %s, error: %v", pair.Response, body, err)
+	}
+	if pair.ServerIP != "10.0.0.2" || pair.ServerPort != "80" {
+		t.Errorf("unexpected server %s:%s", pair.ServerIP, pair.ServerPort)
+	}
+}
+
+func TestImportPcapng(t *testing.T) {
+	pairs := readAll(t, exportEntries(t, export.FormatPcapng), FormatPcap)
+	if len(pairs) != 2 {
+		t.Fatalf("unexpected number of pairs %d", len(pairs))
+	}
+	for i, pair := range pairs {
+		if pair.ClientIP != "10.0.0.1" {
+			t.Errorf("unexpected client %s:%s", pair.ClientIP, pair.ClientPort)
+		}
+		if pair.StartTime.UnixMilli() != 1660000000000 {
+			t.Errorf("unexpected start time %v", pair.StartTime)
+		}
+		if pair.Elapsed.Milliseconds() != 12 {
+			t.Errorf("unexpected elapsed time %v", pair.Elapsed)
+		}
+		if i == 0 {
+			checkPair(t, pair, map[string]string{"43210": "1", "43211": "2"}[pair.ClientPort])
+		}
+	}
+}
+
+func TestImportHar(t *testing.T) {
+	pairs := readAll(t, exportEntries(t, export.FormatHar), FormatHar)
+	if len(pairs) != 2 {
+		t.Fatalf("unexpected number of pairs %d", len(pairs))
+	}
+	checkPair(t, pairs[0], "1")
+	checkPair(t, pairs[1], "2")
+	if pairs[0].Elapsed.Milliseconds() != 12 {
+		t.Errorf("unexpected elapsed time %v", pairs[0].Elapsed)
+	}
+}
+
+func TestImportUnknownFormat(t *testing.T) {
+	if _, _, err := NewReader(strings.NewReader("not a capture")); err == nil {
+		t.Error("expected an unknown format to fail")
+	}
+}
@@ -0,0 +1,397 @@
+package importer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"time"
+)
+
+const (
+	pcapMagicMicroseconds    = 0xa1b2c3d4
+	pcapMagicNanoseconds     = 0xa1b23c4d
+	pcapngSectionHeaderBlock = 0x0a0d0d0a
+	pcapngByteOrderMagic     = 0x1a2b3c4d
+
+	pcapngInterfaceDescriptionBlock = 0x00000001
+	pcapngSimplePacketBlock         = 0x00000003
+	pcapngEnhancedPacketBlock       = 0x00000006
+	pcapngOptionTimestampResolution = 9
+
+	linkTypeNull     = 0
+	linkTypeEthernet = 1
+	linkTypeRaw      = 101
+	linkTypeLinuxSll = 113
+	linkTypeIPv4     = 228
+	linkTypeIPv6     = 229
+
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86dd
+	etherTypeVlan = 0x8100
+	etherTypeQinQ = 0x88a8
+
+	ipProtocolTcp = 6
+
+	// maxBlockLength guards against corrupted files claiming huge blocks
+	maxBlockLength = 64 * 1024 * 1024
+)
+
+type packet struct {
+	timestamp time.Time
+	linkType  uint32
+	data      []byte
+}
+
+// packetSource reads the packets of a pcap or a pcapng capture.
+type packetSource interface {
+	next() (*packet, error)
+}
+
+type pcapSource struct {
+	in         io.Reader
+	byteOrder  binary.ByteOrder
+	nanosecond bool
+	linkType   uint32
+}
+
+func newPacketSource(in io.Reader) (packetSource, error) {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(in, header[:4]); err != nil {
+		return nil, fmt.Errorf("invalid capture, %w", err)
+	}
+
+	if binary.LittleEndian.Uint32(header) == pcapngSectionHeaderBlock {
+		source := &pcapngSource{in: in}
+		if err := source.readSectionHeader(); err != nil {
+			return nil, err
+		}
+		return source, nil
+	}
+
+	if _, err := io.ReadFull(in, header[4:]); err != nil {
+		return nil, fmt.Errorf("invalid pcap header, %w", err)
+	}
+	source := &pcapSource{in: in}
+	for _, byteOrder := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		switch byteOrder.Uint32(header) {
+		case pcapMagicMicroseconds:
+			source.byteOrder = byteOrder
+		case pcapMagicNanoseconds:
+			source.byteOrder = byteOrder
+			source.nanosecond = true
+		}
+	}
+	if source.byteOrder == nil {
+		return nil, errors.New("invalid pcap header")
+	}
+	// The upper bits of the link type may hold the FCS length
+	source.linkType = source.byteOrder.Uint32(header[20:]) & 0x0fffffff
+	return source, nil
+}
+
+func (source *pcapSource) next() (*packet, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(source.in, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("truncated pcap record, %w", err)
+		}
+		return nil, err
+	}
+
+	seconds := int64(source.byteOrder.Uint32(header[0:]))
+	fraction := int64(source.byteOrder.Uint32(header[4:]))
+	capturedLength := source.byteOrder.Uint32(header[8:])
+	if capturedLength > maxBlockLength {
+		return nil, fmt.Errorf("invalid pcap record length %d", capturedLength)
+	}
+
+	data := make([]byte, capturedLength)
+	if _, err := io.ReadFull(source.in, data); err != nil {
+		return nil, fmt.Errorf("truncated pcap record, %w", err)
+	}
+
+	if !source.nanosecond {
+		fraction *= int64(time.Microsecond)
+	}
+	return &packet{timestamp: time.Unix(seconds, fraction), linkType: source.linkType, data: data}, nil
+}
+
+type pcapngInterface struct {
+	linkType uint32
+	// units is the number of timestamp units in a second
+	units uint64
+}
+
+type pcapngSource struct {
+	in         io.Reader
+	byteOrder  binary.ByteOrder
+	interfaces []pcapngInterface
+}
+
+// readSectionHeader reads the rest of a section header block, whose type was read already.
+func (source *pcapngSource) readSectionHeader() error {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(source.in, header); err != nil {
+		return fmt.Errorf("invalid pcapng section header, %w", err)
+	}
+
+	return source.readSection(header[:4], header[4:])
+}
+
+// readBody reads the rest of a block, whose first read bytes were read already.
+func (source *pcapngSource) readBody(length uint32, read uint32) ([]byte, error) {
+	if length < read+4 || length > maxBlockLength {
+		return nil, fmt.Errorf("invalid pcapng block length %d", length)
+	}
+
+	rest := make([]byte, length-read)
+	if _, err := io.ReadFull(source.in, rest); err != nil {
+		return nil, fmt.Errorf("truncated pcapng block, %w", err)
+	}
+	// Drop the trailing length
+	return rest[:len(rest)-4], nil
+}
+
+func (source *pcapngSource) next() (*packet, error) {
+	for {
+		header := make([]byte, 8)
+		if _, err := io.ReadFull(source.in, header); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return nil, fmt.Errorf("truncated pcapng block, %w", err)
+			}
+			return nil, err
+		}
+
+		if binary.LittleEndian.Uint32(header) == pcapngSectionHeaderBlock {
+			// The byte order magic follows the length, whose byte order isn't known yet
+			rest := make([]byte, 4)
+			if _, err := io.ReadFull(source.in, rest); err != nil {
+				return nil, fmt.Errorf("invalid pcapng section header, %w", err)
+			}
+			if err := source.readSection(header[4:], rest); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		blockType := source.byteOrder.Uint32(header)
+		body, err := source.readBody(source.byteOrder.Uint32(header[4:]), 8)
+		if err != nil {
+			return nil, err
+		}
+
+		switch blockType {
+		case pcapngInterfaceDescriptionBlock:
+			if err := source.addInterface(body); err != nil {
+				return nil, err
+			}
+		case pcapngEnhancedPacketBlock:
+			return source.enhancedPacket(body)
+		case pcapngSimplePacketBlock:
+			return source.simplePacket(body)
+		}
+	}
+}
+
+// readSection starts a section, given the length and byte order magic of its header block.
+func (source *pcapngSource) readSection(length []byte, magic []byte) error {
+	switch {
+	case binary.LittleEndian.Uint32(magic) == pcapngByteOrderMagic:
+		source.byteOrder = binary.LittleEndian
+	case binary.BigEndian.Uint32(magic) == pcapngByteOrderMagic:
+		source.byteOrder = binary.BigEndian
+	default:
+		return errors.New("invalid pcapng byte order magic")
+	}
+	// Interface ids are scoped to their section
+	source.interfaces = nil
+
+	_, err := source.readBody(source.byteOrder.Uint32(length), 12)
+	return err
+}
+
+func (source *pcapngSource) addInterface(body []byte) error {
+	if len(body) < 8 {
+		return errors.New("invalid pcapng interface description block")
+	}
+
+	iface := pcapngInterface{linkType: uint32(source.byteOrder.Uint16(body)), units: 1000000}
+	for options := body[8:]; len(options) >= 4; {
+		code := source.byteOrder.Uint16(options)
+		length := int(source.byteOrder.Uint16(options[2:]))
+		padded := 4 + ((length + 3) &^ 3)
+		if code == 0 || len(options) < padded {
+			break
+		}
+		if code == pcapngOptionTimestampResolution && length >= 1 {
+			iface.units = getTimestampUnits(options[4])
+		}
+		options = options[padded:]
+	}
+
+	source.interfaces = append(source.interfaces, iface)
+	return nil
+}
+
+// getTimestampUnits returns the units in a second of an if_tsresol option, a power of 2 when its high bit is set
+// and of 10 otherwise.
+func getTimestampUnits(resolution byte) uint64 {
+	exponent := float64(resolution & 0x7f)
+	if resolution&0x80 != 0 {
+		return uint64(math.Pow(2, exponent))
+	}
+	return uint64(math.Pow(10, exponent))
+}
+
+func (source *pcapngSource) enhancedPacket(body []byte) (*packet, error) {
+	if len(body) < 20 {
+		return nil, errors.New("invalid pcapng enhanced packet block")
+	}
+
+	interfaceId := source.byteOrder.Uint32(body)
+	if int(interfaceId) >= len(source.interfaces) {
+		return nil, fmt.Errorf("pcapng packet of unknown interface %d", interfaceId)
+	}
+	iface := source.interfaces[interfaceId]
+
+	timestamp := uint64(source.byteOrder.Uint32(body[4:]))<<32 | uint64(source.byteOrder.Uint32(body[8:]))
+	capturedLength := source.byteOrder.Uint32(body[12:])
+	if int(capturedLength) > len(body)-20 {
+		return nil, errors.New("invalid pcapng enhanced packet length")
+	}
+
+	seconds := timestamp / iface.units
+	nanoseconds := (timestamp % iface.units) * uint64(time.Second) / iface.units
+	return &packet{
+		timestamp: time.Unix(int64(seconds), int64(nanoseconds)),
+		linkType:  iface.linkType,
+		data:      body[20 : 20+capturedLength],
+	}, nil
+}
+
+func (source *pcapngSource) simplePacket(body []byte) (*packet, error) {
+	if len(body) < 4 || len(source.interfaces) == 0 {
+		return nil, errors.New("invalid pcapng simple packet block")
+	}
+
+	// Simple packets have no timestamp
+	return &packet{linkType: source.interfaces[0].linkType, data: body[4:]}, nil
+}
+
+type tcpSegment struct {
+	timestamp time.Time
+	src       net.IP
+	dst       net.IP
+	srcPort   uint16
+	dstPort   uint16
+	seq       uint32
+	syn       bool
+	ack       bool
+	payload   []byte
+}
+
+// decodeTcp returns the TCP segment of a packet, or nil for other packets.
+func decodeTcp(p *packet) *tcpSegment {
+	network, etherType := getNetworkLayer(p)
+	if network == nil {
+		return nil
+	}
+
+	var src, dst net.IP
+	var transport []byte
+	switch etherType {
+	case etherTypeIPv4:
+		if len(network) < 20 || network[0]>>4 != 4 || network[9] != ipProtocolTcp {
+			return nil
+		}
+		headerLength := int(network[0]&0x0f) * 4
+		totalLength := int(binary.BigEndian.Uint16(network[2:]))
+		// Fragments other than the first can't be decoded alone
+		if binary.BigEndian.Uint16(network[6:])&0x1fff != 0 || headerLength < 20 || totalLength < headerLength || totalLength > len(network) {
+			return nil
+		}
+		src, dst = net.IP(network[12:16]), net.IP(network[16:20])
+		transport = network[headerLength:totalLength]
+	case etherTypeIPv6:
+		if len(network) < 40 || network[0]>>4 != 6 || network[6] != ipProtocolTcp {
+			return nil
+		}
+		payloadLength := int(binary.BigEndian.Uint16(network[4:]))
+		if 40+payloadLength > len(network) {
+			return nil
+		}
+		src, dst = net.IP(network[8:24]), net.IP(network[24:40])
+		transport = network[40 : 40+payloadLength]
+	default:
+		return nil
+	}
+
+	if len(transport) < 20 {
+		return nil
+	}
+	dataOffset := int(transport[12]>>4) * 4
+	if dataOffset < 20 || dataOffset > len(transport) {
+		return nil
+	}
+	flags := transport[13]
+
+	return &tcpSegment{
+		timestamp: p.timestamp,
+		src:       src,
+		dst:       dst,
+		srcPort:   binary.BigEndian.Uint16(transport[0:]),
+		dstPort:   binary.BigEndian.Uint16(transport[2:]),
+		seq:       binary.BigEndian.Uint32(transport[4:]),
+		syn:       flags&0x02 != 0,
+		ack:       flags&0x10 != 0,
+		payload:   transport[dataOffset:],
+	}
+}
+
+// getNetworkLayer strips the link layer of a packet.
+func getNetworkLayer(p *packet) ([]byte, uint16) {
+	data := p.data
+	switch p.linkType {
+	case linkTypeEthernet:
+		if len(data) < 14 {
+			return nil, 0
+		}
+		etherType := binary.BigEndian.Uint16(data[12:])
+		data = data[14:]
+		for (etherType == etherTypeVlan || etherType == etherTypeQinQ) && len(data) >= 4 {
+			etherType = binary.BigEndian.Uint16(data[2:])
+			data = data[4:]
+		}
+		return data, etherType
+	case linkTypeLinuxSll:
+		if len(data) < 16 {
+			return nil, 0
+		}
+		return data[16:], binary.BigEndian.Uint16(data[14:])
+	case linkTypeNull:
+		// The address family is in the byte order of the capturing host, IPv4 is 2 everywhere
+		if len(data) < 4 {
+			return nil, 0
+		}
+		return getRawNetworkLayer(data[4:])
+	case linkTypeRaw, linkTypeIPv4, linkTypeIPv6:
+		return getRawNetworkLayer(data)
+	}
+	return nil, 0
+}
+
+func getRawNetworkLayer(data []byte) ([]byte, uint16) {
+	if len(data) == 0 {
+		return nil, 0
+	}
+	switch data[0] >> 4 {
+	case 4:
+		return data, etherTypeIPv4
+	case 6:
+		return data, etherTypeIPv6
+	}
+	return nil, 0
+}
@@ -0,0 +1,323 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var httpMethods = []string{"GET", "HEAD", "POST", "PUT", "DELETE", "CONNECT", "OPTIONS", "TRACE", "PATCH"}
+
+// halfStream holds the segments one side of a connection sent.
+type halfStream struct {
+	segments []*tcpSegment
+	synSeq   uint32
+	syn      bool
+	// opened is whether this side opened the connection
+	opened bool
+}
+
+type connection struct {
+	endpoints [2]string
+	ips       [2]net.IP
+	ports     [2]uint16
+	streams   [2]*halfStream
+}
+
+// chunk marks where the data of a segment starts in its reassembled stream.
+type chunk struct {
+	offset    int
+	timestamp time.Time
+}
+
+// pcapReader reassembles the TCP connections of a capture and parses the HTTP/1.x pairs they carry. Connections
+// are reassembled in memory once the whole capture was read, since their segments may come in any order.
+type pcapReader struct {
+	pairs []*Pair
+}
+
+func newPcapReader(in io.Reader) (*pcapReader, error) {
+	source, err := newPacketSource(in)
+	if err != nil {
+		return nil, err
+	}
+
+	connections := map[string]*connection{}
+	var finished []*connection
+	for {
+		p, err := source.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		segment := decodeTcp(p)
+		if segment == nil {
+			continue
+		}
+
+		src := net.JoinHostPort(segment.src.String(), strconv.Itoa(int(segment.srcPort)))
+		dst := net.JoinHostPort(segment.dst.String(), strconv.Itoa(int(segment.dstPort)))
+		key := src + "|" + dst
+		if src > dst {
+			key = dst + "|" + src
+		}
+
+		conn := connections[key]
+		// A reused address pair opens a new connection
+		if conn != nil && segment.syn && !segment.ack && conn.hasData() {
+			finished = append(finished, conn)
+			conn = nil
+		}
+		if conn == nil {
+			conn = &connection{
+				endpoints: [2]string{src, dst},
+				ips:       [2]net.IP{segment.src, segment.dst},
+				ports:     [2]uint16{segment.srcPort, segment.dstPort},
+				streams:   [2]*halfStream{{}, {}},
+			}
+			connections[key] = conn
+		}
+		conn.add(src, segment)
+	}
+
+	for _, conn := range connections {
+		finished = append(finished, conn)
+	}
+
+	reader := &pcapReader{}
+	for _, conn := range finished {
+		reader.pairs = append(reader.pairs, conn.getPairs()...)
+	}
+	sort.SliceStable(reader.pairs, func(i, j int) bool {
+		return reader.pairs[i].StartTime.Before(reader.pairs[j].StartTime)
+	})
+	return reader, nil
+}
+
+func (reader *pcapReader) Next() (*Pair, error) {
+	if len(reader.pairs) == 0 {
+		return nil, io.EOF
+	}
+	pair := reader.pairs[0]
+	reader.pairs = reader.pairs[1:]
+	return pair, nil
+}
+
+func (conn *connection) hasData() bool {
+	return len(conn.streams[0].segments) > 0 || len(conn.streams[1].segments) > 0
+}
+
+func (conn *connection) add(src string, segment *tcpSegment) {
+	stream := conn.streams[0]
+	if src != conn.endpoints[0] {
+		stream = conn.streams[1]
+	}
+
+	if segment.syn {
+		stream.syn = true
+		stream.synSeq = segment.seq
+		stream.opened = !segment.ack
+	}
+	if len(segment.payload) > 0 {
+		stream.segments = append(stream.segments, segment)
+	}
+}
+
+// getPairs parses the requests the client sent and the responses the server returned, in order.
+func (conn *connection) getPairs() []*Pair {
+	client := conn.getClient()
+	if client < 0 {
+		return nil
+	}
+	server := 1 - client
+
+	requestData, requestChunks := conn.streams[client].reassemble()
+	responseData, responseChunks := conn.streams[server].reassemble()
+
+	var requests []*http.Request
+	var requestTimes []time.Time
+	readMessages(requestData, requestChunks, func(in *bufio.Reader) error {
+		request, err := http.ReadRequest(in)
+		if err != nil {
+			return err
+		}
+		// The body must be read before the next request
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			return err
+		}
+		request.Body = io.NopCloser(bytes.NewReader(body))
+		requests = append(requests, request)
+		return nil
+	}, func(timestamp time.Time) {
+		requestTimes = append(requestTimes, timestamp)
+	})
+
+	var pairs []*Pair
+	var responseTime time.Time
+	readMessages(responseData, responseChunks, func(in *bufio.Reader) error {
+		if len(pairs) >= len(requests) {
+			return io.EOF
+		}
+		request := requests[len(pairs)]
+		response, err := http.ReadResponse(in, request)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+
+		// Interim responses precede the response of the same request
+		if response.StatusCode >= 100 && response.StatusCode < 200 && response.StatusCode != http.StatusSwitchingProtocols {
+			_, err := io.Copy(io.Discard, response.Body)
+			return err
+		}
+
+		pair, err := conn.newPair(client, request, response)
+		if err != nil {
+			return err
+		}
+		pair.StartTime = requestTimes[len(pairs)]
+		if responseTime.After(pair.StartTime) {
+			pair.Elapsed = responseTime.Sub(pair.StartTime)
+		}
+		pairs = append(pairs, pair)
+		return nil
+	}, func(timestamp time.Time) {
+		responseTime = timestamp
+	})
+
+	return pairs
+}
+
+func (conn *connection) newPair(client int, request *http.Request, response *http.Response) (*Pair, error) {
+	httpRequest, err := getHttpRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	httpResponse, err := getHttpResponse(response)
+	if err != nil {
+		return nil, err
+	}
+
+	server := 1 - client
+	return &Pair{
+		ClientIP:   conn.ips[client].String(),
+		ClientPort: strconv.Itoa(int(conn.ports[client])),
+		ServerIP:   conn.ips[server].String(),
+		ServerPort: strconv.Itoa(int(conn.ports[server])),
+		Request:    httpRequest,
+		Response:   httpResponse,
+	}, nil
+}
+
+// getClient returns the side that opened the connection, or that sent a request first when the handshake wasn't
+// captured, or -1 for connections that don't carry HTTP/1.x.
+func (conn *connection) getClient() int {
+	for i, stream := range conn.streams {
+		if stream.opened {
+			return i
+		}
+	}
+	for i, stream := range conn.streams {
+		if data, _ := stream.reassemble(); startsWithMethod(data) {
+			return i
+		}
+	}
+	return -1
+}
+
+func startsWithMethod(data []byte) bool {
+	for _, method := range httpMethods {
+		if bytes.HasPrefix(data, []byte(method+" ")) {
+			return true
+		}
+	}
+	return false
+}
+
+// reassemble orders the segments of a stream by their sequence numbers, dropping retransmissions. The stream ends
+// at the first gap, since what follows a lost segment can't be parsed.
+func (stream *halfStream) reassemble() ([]byte, []chunk) {
+	if len(stream.segments) == 0 {
+		return nil, nil
+	}
+
+	// Without the handshake the stream starts at the earliest segment captured
+	base := stream.segments[0].seq
+	for _, segment := range stream.segments {
+		if int32(segment.seq-base) < 0 {
+			base = segment.seq
+		}
+	}
+	if stream.syn {
+		base = stream.synSeq + 1
+	}
+
+	type placedSegment struct {
+		offset  uint32
+		segment *tcpSegment
+	}
+	placed := make([]placedSegment, 0, len(stream.segments))
+	for _, segment := range stream.segments {
+		// Sequence numbers wrap around, segments before the base are retransmissions of the handshake
+		offset := segment.seq - base
+		if offset > 1<<31 {
+			continue
+		}
+		placed = append(placed, placedSegment{offset: offset, segment: segment})
+	}
+	sort.SliceStable(placed, func(i, j int) bool {
+		return placed[i].offset < placed[j].offset
+	})
+
+	var data []byte
+	var chunks []chunk
+	for _, p := range placed {
+		end := int(p.offset) + len(p.segment.payload)
+		if int(p.offset) > len(data) {
+			break
+		}
+		if end <= len(data) {
+			continue
+		}
+		chunks = append(chunks, chunk{offset: len(data), timestamp: p.segment.timestamp})
+		data = append(data, p.segment.payload[len(data)-int(p.offset):]...)
+	}
+	return data, chunks
+}
+
+// readMessages calls read until the data ends or can't be parsed, after telling start when every message
+// starts.
+func readMessages(data []byte, chunks []chunk, read func(in *bufio.Reader) error, start func(timestamp time.Time)) {
+	bytesReader := bytes.NewReader(data)
+	in := bufio.NewReader(bytesReader)
+	for {
+		offset := len(data) - bytesReader.Len() - in.Buffered()
+		if offset >= len(data) {
+			return
+		}
+		start(getChunkTime(chunks, offset))
+		if err := read(in); err != nil {
+			return
+		}
+	}
+}
+
+// getChunkTime returns when the segment holding offset was captured.
+func getChunkTime(chunks []chunk, offset int) time.Time {
+	index := sort.Search(len(chunks), func(i int) bool {
+		return chunks[i].offset > offset
+	})
+	if index == 0 {
+		return time.Time{}
+	}
+	return chunks[index-1].timestamp
+}
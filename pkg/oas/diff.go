@@ -0,0 +1,308 @@
+package oas
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The kinds of differences of the observed traffic from a documented spec.
+const (
+	UndocumentedEndpoint    = "undocumented endpoint"
+	UndocumentedParameter   = "undocumented parameter"
+	UndocumentedResponse    = "undocumented response"
+	UndocumentedContentType = "undocumented content type"
+	UndocumentedProperty    = "undocumented property"
+	TypeMismatch            = "type mismatch"
+)
+
+// Difference is a part of the observed traffic the documented spec doesn't describe, or describes otherwise.
+type Difference struct {
+	Kind string
+	// Endpoint is the observed endpoint
+	Endpoint Endpoint
+	Detail   string
+}
+
+func (difference Difference) String() string {
+	if difference.Detail == "" {
+		return fmt.Sprintf("%s: %s", difference.Kind, difference.Endpoint)
+	}
+	return fmt.Sprintf("%s: %s, %s", difference.Kind, difference.Endpoint, difference.Detail)
+}
+
+type DiffResult struct {
+	Differences []Difference
+	// Unobserved are the documented endpoints the traffic didn't reach, they aren't differences as the traffic
+	// may not cover the whole API
+	Unobserved []Endpoint
+}
+
+// differ compares the operations of an observed spec to those of a documented one.
+type differ struct {
+	documented  *Spec
+	observed    *Spec
+	differences []Difference
+}
+
+// Diff compares the spec the hub inferred from the traffic to a documented spec. Observed paths match documented
+// path templates, relative to the paths of the documented servers. Only what the traffic shows is compared: the
+// query parameters, the response codes, the content types and the properties and types of the bodies.
+func Diff(documented *Spec, observed *Spec) (*DiffResult, error) {
+	documentedEndpoints, documentedOperations, err := documented.Operations()
+	if err != nil {
+		return nil, err
+	}
+	observedEndpoints, observedOperations, err := observed.Operations()
+	if err != nil {
+		return nil, err
+	}
+
+	differ := &differ{documented: documented, observed: observed}
+	reached := map[Endpoint]bool{}
+	for _, observedEndpoint := range observedEndpoints {
+		documentedEndpoint, ok := matchEndpoint(documentedEndpoints, documented.BasePaths(), observedEndpoint)
+		if !ok {
+			differ.add(UndocumentedEndpoint, observedEndpoint, "")
+			continue
+		}
+
+		reached[documentedEndpoint] = true
+		differ.compareOperation(observedEndpoint, documentedOperations[documentedEndpoint], observedOperations[observedEndpoint])
+	}
+
+	result := &DiffResult{Differences: differ.differences}
+	for _, documentedEndpoint := range documentedEndpoints {
+		if !reached[documentedEndpoint] {
+			result.Unobserved = append(result.Unobserved, documentedEndpoint)
+		}
+	}
+	return result, nil
+}
+
+func (differ *differ) add(kind string, endpoint Endpoint, detail string) {
+	differ.differences = append(differ.differences, Difference{Kind: kind, Endpoint: endpoint, Detail: detail})
+}
+
+// matchEndpoint returns the documented endpoint of an observed one, the one with the most literal path segments
+// in common when several path templates match.
+func matchEndpoint(documentedEndpoints []Endpoint, basePaths []string, observed Endpoint) (Endpoint, bool) {
+	var match Endpoint
+	bestScore := -1
+	for _, basePath := range basePaths {
+		if !strings.HasPrefix(observed.Path, basePath+"/") {
+			continue
+		}
+		observedPath := strings.TrimPrefix(observed.Path, basePath)
+
+		for _, documented := range documentedEndpoints {
+			if documented.Method != observed.Method {
+				continue
+			}
+			if score, ok := matchPath(documented.Path, observedPath); ok && score > bestScore {
+				match, bestScore = documented, score
+			}
+		}
+	}
+	return match, bestScore >= 0
+}
+
+// matchPath matches an observed path to a path template, and returns how many of their segments are equal.
+func matchPath(template string, path string) (int, bool) {
+	templateSegments := strings.Split(strings.Trim(template, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(templateSegments) != len(pathSegments) {
+		return 0, false
+	}
+
+	score := 0
+	for i, templateSegment := range templateSegments {
+		switch {
+		case templateSegment == pathSegments[i]:
+			score++
+		case isPathParameter(templateSegment):
+		default:
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+func isPathParameter(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
+
+func (differ *differ) compareOperation(endpoint Endpoint, documented *Operation, observed *Operation) {
+	documentedParameters := map[string]bool{}
+	for _, parameter := range documented.Parameters {
+		if parameter = differ.documented.resolveParameter(parameter); parameter != nil {
+			documentedParameters[parameter.In+"/"+parameter.Name] = true
+		}
+	}
+	for _, parameter := range observed.Parameters {
+		// Observed headers are mostly standard ones, which specs don't list
+		if parameter = differ.observed.resolveParameter(parameter); parameter != nil && parameter.In == "query" && !documentedParameters[parameter.In+"/"+parameter.Name] {
+			differ.add(UndocumentedParameter, endpoint, fmt.Sprintf("query parameter %s", parameter.Name))
+		}
+	}
+
+	if observedBody := differ.observed.resolveRequestBody(observed.RequestBody); observedBody != nil {
+		var documentedContent map[string]MediaType
+		if documentedBody := differ.documented.resolveRequestBody(documented.RequestBody); documentedBody != nil {
+			documentedContent = documentedBody.Content
+		}
+		differ.compareContent(endpoint, "request body", documentedContent, observedBody.Content)
+	}
+
+	for _, code := range getResponseCodes(observed.Responses) {
+		observedResponse := differ.observed.resolveResponse(observed.Responses[code])
+		if observedResponse == nil {
+			continue
+		}
+
+		documentedResponse := differ.documented.resolveResponse(getDocumentedResponse(documented.Responses, code))
+		if documentedResponse == nil {
+			differ.add(UndocumentedResponse, endpoint, fmt.Sprintf("response %s", code))
+			continue
+		}
+		differ.compareContent(endpoint, fmt.Sprintf("response %s", code), documentedResponse.Content, observedResponse.Content)
+	}
+}
+
+// getDocumentedResponse returns the documented response of a code, by the code, its range, e.g. 2XX, or the
+// default response.
+func getDocumentedResponse(responses map[string]*Response, code string) *Response {
+	if response, ok := responses[code]; ok {
+		return response
+	}
+	if len(code) == 3 {
+		if response, ok := responses[strings.ToUpper(code[:1]+"XX")]; ok {
+			return response
+		}
+	}
+	return responses["default"]
+}
+
+func (differ *differ) compareContent(endpoint Endpoint, location string, documented map[string]MediaType, observed map[string]MediaType) {
+	for _, mediaType := range getMediaTypes(observed) {
+		documentedMediaType, ok := documented[mediaType]
+		if !ok {
+			if documentedMediaType, ok = documented[strings.SplitN(mediaType, "/", 2)[0]+"/*"]; !ok {
+				documentedMediaType, ok = documented["*/*"]
+			}
+		}
+		if !ok {
+			differ.add(UndocumentedContentType, endpoint, fmt.Sprintf("%s %s", location, mediaType))
+			continue
+		}
+		differ.compareSchema(endpoint, location, "$", documentedMediaType.Schema, observed[mediaType].Schema)
+	}
+}
+
+func (differ *differ) compareSchema(endpoint Endpoint, location string, path string, documented *Schema, observed *Schema) {
+	documented, observed = differ.documented.resolveSchema(documented), differ.observed.resolveSchema(observed)
+	if documented == nil || observed == nil || len(documented.OneOf) > 0 || len(documented.AnyOf) > 0 {
+		return
+	}
+
+	documentedTypes := documented.Types()
+	for _, subschema := range documented.AllOf {
+		if subschema = differ.documented.resolveSchema(subschema); subschema != nil {
+			documentedTypes = append(documentedTypes, subschema.Types()...)
+		}
+	}
+	if len(documentedTypes) > 0 {
+		for _, observedType := range observed.Types() {
+			if !isTypeDocumented(documentedTypes, observedType) {
+				differ.add(TypeMismatch, endpoint, fmt.Sprintf("%s %s is %s, documented as %s", location, path, observedType, strings.Join(documentedTypes, " or ")))
+				return
+			}
+		}
+	}
+
+	if observed.Items != nil {
+		differ.compareSchema(endpoint, location, path+"[]", documented.Items, observed.Items)
+	}
+
+	if len(observed.Properties) == 0 {
+		return
+	}
+	documentedProperties, closed := differ.getDocumentedProperties(documented)
+	for _, name := range getPropertyNames(observed.Properties) {
+		propertyPath := fmt.Sprintf("%s.%s", path, name)
+		documentedProperty, ok := documentedProperties[name]
+		if !ok {
+			if closed {
+				differ.add(UndocumentedProperty, endpoint, fmt.Sprintf("%s %s", location, propertyPath))
+			}
+			continue
+		}
+		differ.compareSchema(endpoint, location, propertyPath, documentedProperty, observed.Properties[name])
+	}
+}
+
+// getDocumentedProperties returns the properties of a schema and of the schemas it's composed of, and whether
+// properties that aren't listed are undocumented.
+func (differ *differ) getDocumentedProperties(schema *Schema) (map[string]*Schema, bool) {
+	properties := map[string]*Schema{}
+	closed := isClosed(schema)
+	for name, property := range schema.Properties {
+		properties[name] = property
+	}
+
+	for _, subschema := range schema.AllOf {
+		subschema = differ.documented.resolveSchema(subschema)
+		if subschema == nil {
+			continue
+		}
+		if isClosed(subschema) {
+			closed = true
+		}
+		for name, property := range subschema.Properties {
+			properties[name] = property
+		}
+	}
+	return properties, closed
+}
+
+// isClosed tells whether the listed properties of a schema are all of its properties, schemas allow other
+// properties unless additionalProperties is false.
+func isClosed(schema *Schema) bool {
+	return strings.TrimSpace(string(schema.AdditionalProperties)) == "false"
+}
+
+func isTypeDocumented(documentedTypes []string, observedType string) bool {
+	for _, documentedType := range documentedTypes {
+		if documentedType == observedType || (documentedType == "number" && observedType == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func getResponseCodes(responses map[string]*Response) []string {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func getMediaTypes(content map[string]MediaType) []string {
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+	return mediaTypes
+}
+
+func getPropertyNames(properties map[string]*Schema) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
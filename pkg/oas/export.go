@@ -0,0 +1,71 @@
+package oas
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The formats specs are exported in.
+const (
+	FormatYaml = "yaml"
+	FormatJson = "json"
+)
+
+// GetFormat returns the format of a spec file by its extension, YAML unless it's .json.
+func GetFormat(filePath string) string {
+	if strings.EqualFold(filepath.Ext(filePath), ".json") {
+		return FormatJson
+	}
+	return FormatYaml
+}
+
+// GetFileName returns the name of the spec file of a service.
+func GetFileName(service string) string {
+	if index := strings.Index(service, "://"); index >= 0 {
+		service = service[index+3:]
+	}
+	return strings.NewReplacer("/", "_", ":", "_").Replace(service) + ".openapi.yaml"
+}
+
+// Format formats a JSON spec, as indented JSON or as YAML in the order of the JSON keys.
+func Format(spec json.RawMessage, format string) ([]byte, error) {
+	if format == FormatJson {
+		var out bytes.Buffer
+		if err := json.Indent(&out, spec, "", "  "); err != nil {
+			return nil, err
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	}
+
+	// JSON is YAML in flow style, the nodes keep the order of the keys
+	var document yaml.Node
+	if err := yaml.Unmarshal(spec, &document); err != nil {
+		return nil, err
+	}
+	setBlockStyle(&document)
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// setBlockStyle clears the styles of nodes, so the encoder writes them in block style and quotes strings only
+// where needed.
+func setBlockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		setBlockStyle(child)
+	}
+}
@@ -0,0 +1,146 @@
+package oas
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const documentedSpec = `
+openapi: 3.0.3
+info:
+  title: checkout
+  version: "1"
+servers:
+  - url: http://checkout.shop/api
+paths:
+  /orders:
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Order'
+      responses:
+        201:
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order'
+  /orders/{orderId}:
+    parameters:
+      - name: orderId
+        in: path
+    get:
+      parameters:
+        - name: expand
+          in: query
+      responses:
+        2XX:
+          description: found
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Order'
+    delete:
+      responses:
+        "204":
+          description: deleted
+components:
+  schemas:
+    Order:
+      type: object
+      properties:
+        id:
+          type: integer
+        total:
+          type: number
+        items:
+          type: array
+          items:
+            type: object
+            additionalProperties: false
+            properties:
+              sku:
+                type: string
+`
+
+const observedSpec = `{
+  "openapi": "3.1.0",
+  "paths": {
+    "/api/orders": {
+      "post": {
+        "requestBody": {"content": {"application/json": {"schema": {"type": "object", "properties": {"items": {"type": "array", "items": {"type": "object", "properties": {"sku": {"type": "string"}, "quantity": {"type": "integer"}}}}}}}}},
+        "responses": {"201": {"content": {"application/json": {"schema": {"type": "object", "properties": {"id": {"type": "string"}, "total": {"type": "integer"}, "note": {"type": "string"}}}}}}}
+      }
+    },
+    "/api/orders/{id}": {
+      "get": {
+        "parameters": [{"name": "expand", "in": "query"}, {"name": "debug", "in": "query"}, {"name": "User-Agent", "in": "header"}],
+        "responses": {"200": {"content": {"application/json": {"schema": {"type": "object"}}}}, "500": {"content": {"text/plain": {}}}}
+      }
+    },
+    "/api/orders/{id}/refund": {
+      "post": {"responses": {"202": {}}}
+    }
+  }
+}`
+
+func TestDiff(t *testing.T) {
+	documented, err := Parse([]byte(documentedSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	observed, err := Parse([]byte(observedSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Diff(documented, observed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var differences []string
+	for _, difference := range result.Differences {
+		differences = append(differences, difference.String())
+	}
+	expected := []string{
+		"undocumented property: POST /api/orders, request body $.items[].quantity",
+		"type mismatch: POST /api/orders, response 201 $.id is string, documented as integer",
+		"undocumented parameter: GET /api/orders/{id}, query parameter debug",
+		"undocumented response: GET /api/orders/{id}, response 500",
+		"undocumented endpoint: POST /api/orders/{id}/refund",
+	}
+	if !reflect.DeepEqual(differences, expected) {
+		t.Errorf("unexpected differences - expected: %v, actual: %v", expected, differences)
+	}
+
+	if len(result.Unobserved) != 1 || result.Unobserved[0].String() != "DELETE /orders/{orderId}" {
+		t.Errorf("unexpected unobserved endpoints %v", result.Unobserved)
+	}
+}
+
+func TestParseNotOpenApi3(t *testing.T) {
+	if _, err := Parse([]byte(`swagger: "2.0"`)); err == nil {
+		t.Error("expected a swagger 2 spec to fail")
+	}
+}
+
+func TestFormatYaml(t *testing.T) {
+	formatted, err := Format([]byte(observedSpec), FormatYaml)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := Parse(formatted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := spec.Paths["/api/orders/{id}"]["get"]; !ok || spec.OpenApi != "3.1.0" {
+		t.Errorf("unexpected spec formatted as yaml:\n%s", formatted)
+	}
+	if !strings.HasPrefix(string(formatted), "openapi: 3.1.0\npaths:\n  /api/orders:\n") {
+		t.Errorf("expected the keys in their json order:\n%s", formatted)
+	}
+}
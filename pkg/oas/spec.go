@@ -0,0 +1,258 @@
+// Package oas reads OpenAPI 3 specs, and compares the specs the hub infers from the traffic to documented ones.
+package oas
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Methods are the operations of a path item, in the order they're listed.
+var Methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+const componentsRefPrefix = "#/components/"
+
+type Spec struct {
+	OpenApi    string              `json:"openapi"`
+	Servers    []Server            `json:"servers"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Server struct {
+	Url string `json:"url"`
+}
+
+// PathItem holds the operations of a path by method, along with the other fields of the path.
+type PathItem map[string]json.RawMessage
+
+type Components struct {
+	Schemas       map[string]*Schema      `json:"schemas"`
+	Parameters    map[string]*Parameter   `json:"parameters"`
+	RequestBodies map[string]*RequestBody `json:"requestBodies"`
+	Responses     map[string]*Response    `json:"responses"`
+}
+
+type Operation struct {
+	Parameters  []*Parameter         `json:"parameters"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Ref  string `json:"$ref"`
+	Name string `json:"name"`
+	In   string `json:"in"`
+}
+
+type RequestBody struct {
+	Ref     string               `json:"$ref"`
+	Content map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref     string               `json:"$ref"`
+	Content map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref string `json:"$ref"`
+	// Type is a type name, or a list of type names since OpenAPI 3.1
+	Type       json.RawMessage    `json:"type"`
+	Nullable   bool               `json:"nullable"`
+	Properties map[string]*Schema `json:"properties"`
+	Items      *Schema            `json:"items"`
+	AllOf      []*Schema          `json:"allOf"`
+	OneOf      []*Schema          `json:"oneOf"`
+	AnyOf      []*Schema          `json:"anyOf"`
+	// AdditionalProperties allows properties that aren't listed unless it's false
+	AdditionalProperties json.RawMessage `json:"additionalProperties"`
+}
+
+// Types returns the type names of a schema, it's empty when the schema doesn't restrict the type.
+func (schema *Schema) Types() []string {
+	var types []string
+	var typeName string
+	if err := json.Unmarshal(schema.Type, &typeName); err == nil && typeName != "" {
+		types = append(types, typeName)
+	} else {
+		_ = json.Unmarshal(schema.Type, &types)
+	}
+	if schema.Nullable {
+		types = append(types, "null")
+	}
+	return types
+}
+
+// Parse parses a JSON or YAML OpenAPI 3 spec.
+func Parse(data []byte) (*Spec, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("not a JSON or YAML document, %w", err)
+	}
+
+	// JSON is decoded as YAML, whose maps may have keys that aren't strings, e.g. response codes
+	normalized, err := json.Marshal(normalizeYaml(document))
+	if err != nil {
+		return nil, err
+	}
+
+	var spec Spec
+	if err := json.Unmarshal(normalized, &spec); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec, %w", err)
+	}
+	if !strings.HasPrefix(spec.OpenApi, "3.") {
+		return nil, fmt.Errorf("only OpenAPI 3 specs are supported, the openapi version is %q", spec.OpenApi)
+	}
+	return &spec, nil
+}
+
+func normalizeYaml(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, item := range typedValue {
+			typedValue[key] = normalizeYaml(item)
+		}
+		return typedValue
+	case map[interface{}]interface{}:
+		normalized := make(map[string]interface{}, len(typedValue))
+		for key, item := range typedValue {
+			normalized[fmt.Sprint(key)] = normalizeYaml(item)
+		}
+		return normalized
+	case []interface{}:
+		for i, item := range typedValue {
+			typedValue[i] = normalizeYaml(item)
+		}
+		return typedValue
+	default:
+		return value
+	}
+}
+
+// Endpoint is an operation of a spec, by its method and path template.
+type Endpoint struct {
+	Method string
+	Path   string
+}
+
+func (endpoint Endpoint) String() string {
+	return fmt.Sprintf("%s %s", strings.ToUpper(endpoint.Method), endpoint.Path)
+}
+
+// Operations returns the operations of a spec by endpoint, sorted by path and method.
+func (spec *Spec) Operations() ([]Endpoint, map[Endpoint]*Operation, error) {
+	var endpoints []Endpoint
+	operations := map[Endpoint]*Operation{}
+	for path, pathItem := range spec.Paths {
+		for _, method := range Methods {
+			rawOperation, ok := pathItem[method]
+			if !ok {
+				continue
+			}
+
+			var operation Operation
+			if err := json.Unmarshal(rawOperation, &operation); err != nil {
+				return nil, nil, fmt.Errorf("invalid operation %s %s, %w", strings.ToUpper(method), path, err)
+			}
+
+			var pathParameters []*Parameter
+			if rawParameters, ok := pathItem["parameters"]; ok {
+				if err := json.Unmarshal(rawParameters, &pathParameters); err != nil {
+					return nil, nil, fmt.Errorf("invalid parameters of %s, %w", path, err)
+				}
+			}
+			operation.Parameters = append(pathParameters, operation.Parameters...)
+
+			endpoint := Endpoint{Method: method, Path: path}
+			endpoints = append(endpoints, endpoint)
+			operations[endpoint] = &operation
+		}
+	}
+
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Path != endpoints[j].Path {
+			return endpoints[i].Path < endpoints[j].Path
+		}
+		return indexOf(Methods, endpoints[i].Method) < indexOf(Methods, endpoints[j].Method)
+	})
+	return endpoints, operations, nil
+}
+
+// BasePaths returns the paths of the servers of a spec, the paths of the spec are relative to them.
+func (spec *Spec) BasePaths() []string {
+	basePaths := []string{""}
+	for _, server := range spec.Servers {
+		serverUrl, err := url.Parse(server.Url)
+		if err != nil {
+			continue
+		}
+		if basePath := strings.TrimSuffix(serverUrl.Path, "/"); basePath != "" {
+			basePaths = append(basePaths, basePath)
+		}
+	}
+	return basePaths
+}
+
+// Hosts returns the hosts of the servers of a spec, without their ports.
+func (spec *Spec) Hosts() []string {
+	var hosts []string
+	for _, server := range spec.Servers {
+		if serverUrl, err := url.Parse(server.Url); err == nil && serverUrl.Hostname() != "" {
+			hosts = append(hosts, serverUrl.Hostname())
+		}
+	}
+	return hosts
+}
+
+// maxRefDepth bounds the chains of references, which may be cyclic.
+const maxRefDepth = 32
+
+func getComponentName(ref string, kind string) string {
+	return strings.TrimPrefix(ref, componentsRefPrefix+kind+"/")
+}
+
+func (spec *Spec) resolveSchema(schema *Schema) *Schema {
+	for depth := 0; schema != nil && schema.Ref != "" && depth < maxRefDepth; depth++ {
+		schema = spec.Components.Schemas[getComponentName(schema.Ref, "schemas")]
+	}
+	return schema
+}
+
+func (spec *Spec) resolveParameter(parameter *Parameter) *Parameter {
+	for depth := 0; parameter != nil && parameter.Ref != "" && depth < maxRefDepth; depth++ {
+		parameter = spec.Components.Parameters[getComponentName(parameter.Ref, "parameters")]
+	}
+	return parameter
+}
+
+func (spec *Spec) resolveRequestBody(requestBody *RequestBody) *RequestBody {
+	for depth := 0; requestBody != nil && requestBody.Ref != "" && depth < maxRefDepth; depth++ {
+		requestBody = spec.Components.RequestBodies[getComponentName(requestBody.Ref, "requestBodies")]
+	}
+	return requestBody
+}
+
+func (spec *Spec) resolveResponse(response *Response) *Response {
+	for depth := 0; response != nil && response.Ref != "" && depth < maxRefDepth; depth++ {
+		response = spec.Components.Responses[getComponentName(response.Ref, "responses")]
+	}
+	return response
+}
+
+func indexOf(values []string, value string) int {
+	for i, candidate := range values {
+		if candidate == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,140 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+const (
+	// MaxDiffLines bounds the body differences Diff lists
+	MaxDiffLines = 20
+
+	maxDiffValueLength = 60
+)
+
+// Diff lists the differences of a replayed response from the captured one, in the status, the gRPC status, the
+// media type and the body. JSON bodies are compared value by value. It's empty when the responses match.
+func Diff(captured *hub.HttpResponse, replayed *Response) ([]string, error) {
+	var lines []string
+	if captured.Status != replayed.Status {
+		lines = append(lines, fmt.Sprintf("status: %d → %d", captured.Status, replayed.Status))
+	}
+	if capturedGrpcStatus, replayedGrpcStatus := captured.Headers.Get("Grpc-Status"), replayed.Headers.Get("Grpc-Status"); capturedGrpcStatus != replayedGrpcStatus {
+		lines = append(lines, fmt.Sprintf("grpc-status: %s → %s", formatMissing(capturedGrpcStatus), formatMissing(replayedGrpcStatus)))
+	}
+
+	capturedMediaType, replayedMediaType := getMediaType(captured.Headers.Get("Content-Type")), getMediaType(replayed.Headers.Get("Content-Type"))
+	if capturedMediaType != replayedMediaType {
+		lines = append(lines, fmt.Sprintf("content-type: %s → %s", formatMissing(capturedMediaType), formatMissing(replayedMediaType)))
+	}
+
+	capturedBody, err := captured.Body()
+	if err != nil {
+		return nil, err
+	}
+	return append(lines, diffBody(capturedBody, replayed.Body)...), nil
+}
+
+func diffBody(captured []byte, replayed []byte) []string {
+	if bytes.Equal(captured, replayed) {
+		return nil
+	}
+
+	var capturedValue, replayedValue interface{}
+	if json.Unmarshal(captured, &capturedValue) != nil || json.Unmarshal(replayed, &replayedValue) != nil {
+		return []string{fmt.Sprintf("body: %d bytes → %d bytes, the contents differ", len(captured), len(replayed))}
+	}
+
+	var lines []string
+	diffJson("$", capturedValue, replayedValue, &lines)
+	if len(lines) > MaxDiffLines {
+		lines = append(lines[:MaxDiffLines], fmt.Sprintf("... %d more differences", len(lines)-MaxDiffLines))
+	}
+	return lines
+}
+
+// diffJson appends a line for every value that differs between two decoded JSON values, named by its path.
+func diffJson(path string, captured interface{}, replayed interface{}, lines *[]string) {
+	switch capturedValue := captured.(type) {
+	case map[string]interface{}:
+		replayedValue, ok := replayed.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(capturedValue)+len(replayedValue))
+		for key := range capturedValue {
+			keys = append(keys, key)
+		}
+		for key := range replayedValue {
+			if _, ok := capturedValue[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			keyPath := fmt.Sprintf("%s.%s", path, key)
+			capturedField, inCaptured := capturedValue[key]
+			replayedField, inReplayed := replayedValue[key]
+			switch {
+			case !inReplayed:
+				*lines = append(*lines, fmt.Sprintf("%s: removed, was %s", keyPath, formatJson(capturedField)))
+			case !inCaptured:
+				*lines = append(*lines, fmt.Sprintf("%s: added %s", keyPath, formatJson(replayedField)))
+			default:
+				diffJson(keyPath, capturedField, replayedField, lines)
+			}
+		}
+		return
+	case []interface{}:
+		replayedValue, ok := replayed.([]interface{})
+		if !ok {
+			break
+		}
+
+		if len(capturedValue) != len(replayedValue) {
+			*lines = append(*lines, fmt.Sprintf("%s: %d items → %d items", path, len(capturedValue), len(replayedValue)))
+		}
+		for i := 0; i < len(capturedValue) && i < len(replayedValue); i++ {
+			diffJson(fmt.Sprintf("%s[%d]", path, i), capturedValue[i], replayedValue[i], lines)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(captured, replayed) {
+		*lines = append(*lines, fmt.Sprintf("%s: %s → %s", path, formatJson(captured), formatJson(replayed)))
+	}
+}
+
+func formatJson(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	if len(encoded) > maxDiffValueLength {
+		return string(encoded[:maxDiffValueLength]) + "..."
+	}
+	return string(encoded)
+}
+
+func getMediaType(contentType string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(strings.TrimSpace(contentType))
+}
+
+func formatMissing(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
@@ -0,0 +1,223 @@
+// Package replay resends captured HTTP and gRPC requests, and compares their responses to the captured ones.
+package replay
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+	"golang.org/x/net/http2"
+)
+
+// RequestTimeout bounds a replayed request and the read of its response.
+const RequestTimeout = 30 * time.Second
+
+// Request is a captured request to replay.
+type Request struct {
+	Method string
+	// Path is the path and the query of the request
+	Path    string
+	Host    string
+	Headers hub.NameValues
+	Body    []byte
+	// Grpc requests are sent over HTTP/2
+	Grpc bool
+}
+
+// requestExcludedHeaders are set by the client, or are HTTP/2 pseudo headers, which start with a colon.
+var requestExcludedHeaders = []string{"Host", "Content-Length", "Transfer-Encoding", "Connection", "Accept-Encoding", "Keep-Alive", "Upgrade"}
+
+// NewRequest returns the request of an HTTP entry, along with its captured response.
+func NewRequest(entry *hub.Entry) (*Request, *hub.HttpResponse, error) {
+	if !entry.IsHttp() {
+		return nil, nil, fmt.Errorf("entry %s is %s, only HTTP and gRPC entries can be replayed", entry.Id, entry.Protocol.Name)
+	}
+
+	httpRequest, err := entry.GetHttpRequest()
+	if err != nil {
+		return nil, nil, err
+	}
+	httpResponse, err := entry.GetHttpResponse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	request := &Request{
+		Method: httpRequest.Method,
+		Path:   httpRequest.Url,
+		Host:   httpRequest.Headers.Get("Host"),
+		Body:   httpRequest.Body(),
+		Grpc:   strings.HasPrefix(httpRequest.Headers.Get("Content-Type"), "application/grpc"),
+	}
+	if request.Host == "" {
+		request.Host = httpRequest.Headers.Get(":authority")
+	}
+	if parsedUrl, err := url.Parse(httpRequest.Url); err == nil && parsedUrl.IsAbs() {
+		request.Path = parsedUrl.RequestURI()
+		request.Host = parsedUrl.Host
+	}
+	if !strings.HasPrefix(request.Path, "/") {
+		request.Path = "/" + request.Path
+	}
+
+	for _, header := range httpRequest.Headers {
+		if strings.HasPrefix(header.Name, ":") || containsFold(requestExcludedHeaders, header.Name) {
+			continue
+		}
+		request.Headers = append(request.Headers, header)
+	}
+
+	return request, httpResponse, nil
+}
+
+// AuthHeaders are the headers the API server authenticates requests with, it consumes them rather than passing
+// them on to the services it proxies.
+var AuthHeaders = []string{"Authorization", "Proxy-Authorization"}
+
+// HasAuth returns whether a request carries an auth header once the header overrides are applied.
+func (request *Request) HasAuth(headers []Header) bool {
+	for _, name := range AuthHeaders {
+		value := request.Headers.Get(name)
+		for _, header := range headers {
+			if strings.EqualFold(header.Name, name) {
+				value = header.Value
+			}
+		}
+		if value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Header overrides a header of the replayed requests, an empty value removes it.
+type Header struct {
+	Name  string
+	Value string
+}
+
+// ParseHeader parses a header override given as "Name: value".
+func ParseHeader(value string) (Header, error) {
+	split := strings.SplitN(value, ":", 2)
+	if len(split) != 2 || strings.TrimSpace(split[0]) == "" {
+		return Header{}, fmt.Errorf("invalid header %s, use \"Name: value\", or \"Name:\" to remove it", value)
+	}
+	return Header{Name: strings.TrimSpace(split[0]), Value: strings.TrimSpace(split[1])}, nil
+}
+
+// Response is the response to a replayed request, the trailers of gRPC responses are in its headers.
+type Response struct {
+	Status  int
+	Headers http.Header
+	Body    []byte
+	Elapsed time.Duration
+}
+
+// Target is where requests are replayed to.
+type Target struct {
+	// BaseUrl replaces the scheme and the host of requests, its path prefixes theirs
+	BaseUrl string
+	// SendHost sends the captured Host header rather than the host of BaseUrl
+	SendHost bool
+	// DropAuth removes the auth headers of the requests, so they don't reach the API server proxy
+	DropAuth   bool
+	client     *http.Client
+	grpcClient *http.Client
+}
+
+// NewTarget returns a target that sends HTTP/1 requests with transport, the default transport when nil. gRPC
+// requests are sent over HTTP/2, in cleartext unless the scheme of baseUrl is https.
+func NewTarget(baseUrl string, transport http.RoundTripper, sendHost bool) *Target {
+	noRedirect := func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	grpcTransport := &http2.Transport{}
+	if !strings.HasPrefix(baseUrl, "https:") {
+		grpcTransport.AllowHTTP = true
+		grpcTransport.DialTLS = func(network string, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		}
+	}
+
+	return &Target{
+		BaseUrl:    strings.TrimSuffix(baseUrl, "/"),
+		SendHost:   sendHost,
+		client:     &http.Client{Transport: transport, Timeout: RequestTimeout, CheckRedirect: noRedirect},
+		grpcClient: &http.Client{Transport: grpcTransport, Timeout: RequestTimeout, CheckRedirect: noRedirect},
+	}
+}
+
+// Send replays a request with the header overrides, and reads its response.
+func (target *Target) Send(ctx context.Context, request *Request, headers []Header) (*Response, error) {
+	httpRequest, err := http.NewRequestWithContext(ctx, request.Method, target.BaseUrl+request.Path, bytes.NewReader(request.Body))
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range request.Headers {
+		httpRequest.Header.Add(header.Name, header.Value)
+	}
+	if target.SendHost && request.Host != "" {
+		httpRequest.Host = request.Host
+	}
+	for _, header := range headers {
+		switch {
+		case strings.EqualFold(header.Name, "Host"):
+			httpRequest.Host = header.Value
+		case header.Value == "":
+			httpRequest.Header.Del(header.Name)
+		default:
+			httpRequest.Header.Set(header.Name, header.Value)
+		}
+	}
+	if target.DropAuth {
+		for _, name := range AuthHeaders {
+			httpRequest.Header.Del(name)
+		}
+	}
+
+	client := target.client
+	if request.Grpc {
+		client = target.grpcClient
+	}
+
+	start := time.Now()
+	httpResponse, err := client.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	body, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &Response{
+		Status:  httpResponse.StatusCode,
+		Headers: httpResponse.Header.Clone(),
+		Body:    body,
+		Elapsed: time.Since(start),
+	}
+	for name, values := range httpResponse.Trailer {
+		response.Headers[name] = values
+	}
+	return response, nil
+}
+
+func containsFold(names []string, name string) bool {
+	for _, candidate := range names {
+		if strings.EqualFold(candidate, name) {
+			return true
+		}
+	}
+	return false
+}
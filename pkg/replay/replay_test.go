@@ -0,0 +1,144 @@
+package replay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+func newCheckoutEntry() *hub.Entry {
+	return &hub.Entry{
+		Id:          "1",
+		Protocol:    hub.Protocol{Name: hub.ProtocolHttp},
+		Destination: &hub.TCP{IP: "10.0.0.2", Port: "8080", Name: "checkout.shop"},
+		Request: map[string]interface{}{
+			"method": "POST",
+			"url":    "http://checkout.shop/orders?id=1",
+			"headers": map[string]interface{}{
+				"Host":           "checkout.shop",
+				"Content-Length": "14",
+				"Authorization":  "Bearer captured",
+				"X-Request-Id":   "abc",
+			},
+			"postData": map[string]interface{}{"mimeType": "application/json", "text": `{"items":[1]}`},
+		},
+		Response: map[string]interface{}{
+			"status":  201,
+			"headers": map[string]interface{}{"Content-Type": "application/json; charset=utf-8"},
+			"content": map[string]interface{}{"mimeType": "application/json", "text": `{"id":1,"total":10,"items":[{"sku":"a"}]}`},
+		},
+	}
+}
+
+func TestReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != "POST" || r.URL.RequestURI() != "/canary/orders?id=1" || r.Host != "checkout.shop" || string(body) != `{"items":[1]}` {
+			t.Errorf("unexpected request %s %s, host: %s, body: %s", r.Method, r.URL, r.Host, body)
+		}
+		if r.Header.Get("Authorization") != "Bearer replayed" || r.Header.Get("X-Request-Id") != "" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1,"total":12,"items":[{"sku":"a"},{"sku":"b"}],"coupon":null}`))
+	}))
+	defer server.Close()
+
+	request, captured, err := NewRequest(newCheckoutEntry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Path != "/orders?id=1" || request.Host != "checkout.shop" || request.Grpc {
+		t.Fatalf("unexpected request %+v", request)
+	}
+
+	var headers []Header
+	for _, value := range []string{"Authorization: Bearer replayed", "X-Request-Id:"} {
+		header, err := ParseHeader(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		headers = append(headers, header)
+	}
+
+	response, err := NewTarget(server.URL+"/canary/", nil, true).Send(context.Background(), request, headers)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines, err := Diff(captured, response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		`$.coupon: added null`,
+		`$.items: 1 items → 2 items`,
+		`$.total: 10 → 12`,
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("unexpected diff - expected: %v, actual: %v", expected, lines)
+	}
+}
+
+func TestReplayDropAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" || r.Header.Get("X-Request-Id") != "abc" {
+			t.Errorf("unexpected headers %v", r.Header)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	request, _, err := NewRequest(newCheckoutEntry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !request.HasAuth(nil) || request.HasAuth([]Header{{Name: "authorization"}}) {
+		t.Errorf("unexpected auth of request %+v", request)
+	}
+
+	target := NewTarget(server.URL, nil, false)
+	target.DropAuth = true
+	if _, err := target.Send(context.Background(), request, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiffStatusAndText(t *testing.T) {
+	captured := &hub.HttpResponse{Status: 200, Headers: hub.NameValues{{Name: "Content-Type", Value: "text/plain"}}, Content: &hub.Content{Text: "ok"}}
+	replayed := &Response{Status: 503, Headers: http.Header{"Content-Type": {"text/html"}}, Body: []byte("unavailable")}
+
+	lines, err := Diff(captured, replayed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"status: 200 → 503",
+		"content-type: text/plain → text/html",
+		"body: 2 bytes → 11 bytes, the contents differ",
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("unexpected diff - expected: %v, actual: %v", expected, lines)
+	}
+
+	replayed = &Response{Status: 200, Headers: http.Header{"Content-Type": {"text/plain; charset=utf-8"}}, Body: []byte("ok")}
+	if lines, _ := Diff(captured, replayed); len(lines) != 0 {
+		t.Errorf("expected matching responses, got %v", lines)
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	if _, err := ParseHeader("no-colon"); err == nil {
+		t.Error("expected a header without a colon to fail")
+	}
+	if header, err := ParseHeader("X-Canary: true"); err != nil || header.Name != "X-Canary" || header.Value != "true" {
+		t.Errorf("unexpected header %+v, error: %v", header, err)
+	}
+}
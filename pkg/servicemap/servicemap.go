@@ -0,0 +1,161 @@
+// Package servicemap writes the service map the hub builds from the traffic as a graph other tools render,
+// Graphviz DOT, a Mermaid flowchart or JSON.
+package servicemap
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+// Node is a service, a pod or an unresolved address the traffic was sent from or to.
+type Node struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Workload is the controller of the pods of the node, as kind/name
+	Workload string `json:"workload,omitempty"`
+	Address  string `json:"address,omitempty"`
+	Resolved bool   `json:"resolved"`
+	Count    int    `json:"count"`
+
+	key   string
+	hubId int
+}
+
+// Latency summarizes the latencies of the entries of an edge, in milliseconds.
+type Latency struct {
+	AverageMs int64 `json:"averageMs"`
+	P50Ms     int64 `json:"p50Ms"`
+	P95Ms     int64 `json:"p95Ms"`
+}
+
+// Edge is the traffic of a protocol from a node to another, the stats are those of the entries the hub still
+// holds, which the count of the service map can exceed.
+type Edge struct {
+	Source      int      `json:"source"`
+	Destination int      `json:"destination"`
+	Protocol    string   `json:"protocol"`
+	Count       int      `json:"count"`
+	Errors      int      `json:"errors"`
+	ErrorRate   float64  `json:"errorRate"`
+	Latency     *Latency `json:"latency,omitempty"`
+
+	protocolName string
+}
+
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+}
+
+// NewGraph returns the graph of a service map, with the nodes sorted by namespace and name.
+func NewGraph(serviceMap *hub.ServiceMap) *Graph {
+	graph := &Graph{}
+	ids := map[int]int{}
+	for _, serviceMapNode := range serviceMap.Nodes {
+		node := &Node{
+			Name:     serviceMapNode.Name,
+			Resolved: serviceMapNode.Resolved,
+			Count:    serviceMapNode.Count,
+			key:      getNodeKey(serviceMapNode.Name, serviceMapNode.Entry),
+			hubId:    serviceMapNode.Id,
+		}
+		if serviceMapNode.Entry != nil {
+			node.Address = serviceMapNode.Entry.IP
+			if serviceMapNode.Entry.Port != "" {
+				node.Address += ":" + serviceMapNode.Entry.Port
+			}
+		}
+		if node.Name == "" {
+			node.Name = node.Address
+		}
+		if node.Resolved {
+			if split := strings.SplitN(node.Name, ".", 2); len(split) == 2 {
+				node.Name, node.Namespace = split[0], split[1]
+			}
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	sort.SliceStable(graph.Nodes, func(i, j int) bool {
+		if graph.Nodes[i].Namespace != graph.Nodes[j].Namespace {
+			return graph.Nodes[i].Namespace < graph.Nodes[j].Namespace
+		}
+		return graph.Nodes[i].Name < graph.Nodes[j].Name
+	})
+	for i, node := range graph.Nodes {
+		node.Id = i
+		ids[node.hubId] = i
+	}
+
+	for _, serviceMapEdge := range serviceMap.Edges {
+		source, ok := ids[serviceMapEdge.Source.Id]
+		if !ok {
+			continue
+		}
+		destination, ok := ids[serviceMapEdge.Destination.Id]
+		if !ok {
+			continue
+		}
+
+		edge := &Edge{
+			Source:      source,
+			Destination: destination,
+			Count:       serviceMapEdge.Count,
+		}
+		if serviceMapEdge.Protocol != nil {
+			edge.Protocol = getProtocolLabel(serviceMapEdge.Protocol)
+			edge.protocolName = serviceMapEdge.Protocol.Name
+		}
+		graph.Edges = append(graph.Edges, edge)
+	}
+
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].Source != graph.Edges[j].Source {
+			return graph.Edges[i].Source < graph.Edges[j].Source
+		}
+		if graph.Edges[i].Destination != graph.Edges[j].Destination {
+			return graph.Edges[i].Destination < graph.Edges[j].Destination
+		}
+		return graph.Edges[i].Protocol < graph.Edges[j].Protocol
+	})
+	return graph
+}
+
+// SetStats sets the error rates and latencies of the edges from the stats of their entries.
+func (graph *Graph) SetStats(stats *Stats) {
+	for _, edge := range graph.Edges {
+		edgeStats, ok := stats.edges[edgeKey{
+			source:      graph.Nodes[edge.Source].key,
+			destination: graph.Nodes[edge.Destination].key,
+			protocol:    edge.protocolName,
+		}]
+		if !ok || len(edgeStats.latencies) == 0 {
+			continue
+		}
+
+		edge.Errors = edgeStats.errors
+		edge.ErrorRate = float64(edgeStats.errors) / float64(len(edgeStats.latencies))
+		edge.Latency = edgeStats.getLatency()
+	}
+}
+
+// getNodeKey returns the name entries refer to a node by, its resolved name or its address.
+func getNodeKey(name string, endpoint *hub.TCP) string {
+	if name != "" {
+		return name
+	}
+	if endpoint != nil {
+		return endpoint.IP
+	}
+	return ""
+}
+
+func getProtocolLabel(protocol *hub.Protocol) string {
+	if protocol.Abbreviation != "" {
+		return protocol.Abbreviation
+	}
+	return strings.ToUpper(protocol.Name)
+}
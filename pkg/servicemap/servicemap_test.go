@@ -0,0 +1,92 @@
+package servicemap
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+func newTestGraph() *Graph {
+	http := &hub.Protocol{Name: "http", Abbreviation: "HTTP"}
+	frontend := hub.ServiceMapNode{Id: 1, Name: "frontend.shop", Resolved: true, Count: 10, Entry: &hub.TCP{IP: "10.0.0.1", Name: "frontend.shop"}}
+	checkout := hub.ServiceMapNode{Id: 2, Name: "checkout.shop", Resolved: true, Count: 4, Entry: &hub.TCP{IP: "10.0.0.2", Port: "8080", Name: "checkout.shop"}}
+	external := hub.ServiceMapNode{Id: 3, Entry: &hub.TCP{IP: "172.217.0.1", Port: "443"}, Count: 2}
+
+	graph := NewGraph(&hub.ServiceMap{
+		Nodes: []hub.ServiceMapNode{checkout, frontend, external},
+		Edges: []hub.ServiceMapEdge{
+			{Source: frontend, Destination: checkout, Count: 4, Protocol: http},
+			{Source: checkout, Destination: external, Count: 2, Protocol: http},
+		},
+	})
+
+	stats := NewStats()
+	for i, latency := range []int64{10, 20, 30, 100} {
+		status := 200
+		if i == 3 {
+			status = 503
+		}
+		stats.Add(&hub.BaseEntry{
+			Protocol:    *http,
+			Status:      status,
+			Latency:     latency,
+			Source:      &hub.TCP{IP: "10.0.0.1", Name: "frontend.shop"},
+			Destination: &hub.TCP{IP: "10.0.0.2", Port: "8080", Name: "checkout.shop"},
+		})
+	}
+	graph.SetStats(stats)
+	graph.Nodes[1].Workload = "deployment/checkout"
+	return graph
+}
+
+func TestNewGraph(t *testing.T) {
+	graph := newTestGraph()
+
+	var names []string
+	for _, node := range graph.Nodes {
+		names = append(names, node.Namespace+"/"+node.Name)
+	}
+	if strings.Join(names, " ") != "/172.217.0.1:443 shop/checkout shop/frontend" {
+		t.Errorf("unexpected nodes %v", names)
+	}
+
+	edge := graph.Edges[0]
+	if edge.Source != 1 || edge.Destination != 0 || edge.Latency != nil {
+		t.Errorf("unexpected edge without entries %+v", edge)
+	}
+	edge = graph.Edges[1]
+	if edge.Source != 2 || edge.Destination != 1 || edge.Errors != 1 || edge.ErrorRate != 0.25 {
+		t.Errorf("unexpected edge %+v", edge)
+	}
+	if *edge.Latency != (Latency{AverageMs: 40, P50Ms: 20, P95Ms: 100}) {
+		t.Errorf("unexpected latency %+v", edge.Latency)
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var out bytes.Buffer
+	if err := Write(&out, newTestGraph(), FormatMermaid); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `flowchart LR
+  n0["172.217.0.1:443"]
+  subgraph ns1 ["shop"]
+    n1["checkout<br/>deployment/checkout"]
+    n2["frontend"]
+  end
+  n1 -->|"HTTP, 2 requests"| n0
+  n2 -->|"HTTP, 4 requests, 25.0% errors, p95 100ms"| n1
+`
+	if out.String() != expected {
+		t.Errorf("unexpected mermaid - expected:\n%s\nactual:\n%s", expected, out.String())
+	}
+}
+
+func TestQuoteDot(t *testing.T) {
+	if quoted := quoteDot([]string{`say "hi"`, `a\b`}); quoted != `"say \"hi\"\na\\b"` {
+		t.Errorf("unexpected quoted string %s", quoted)
+	}
+}
@@ -0,0 +1,79 @@
+package servicemap
+
+import (
+	"sort"
+
+	"github.com/kubeshark/kubeshark/pkg/hub"
+)
+
+// ErrorStatus is the lowest status counted as an error, the server failed to handle the request.
+const ErrorStatus = 500
+
+type edgeKey struct {
+	source      string
+	destination string
+	protocol    string
+}
+
+type edgeStats struct {
+	errors    int
+	latencies []int64
+}
+
+// getLatency returns the average and the percentiles of the latencies, the nearest rank of each.
+func (stats *edgeStats) getLatency() *Latency {
+	latencies := make([]int64, len(stats.latencies))
+	copy(latencies, stats.latencies)
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+
+	var total int64
+	for _, latency := range latencies {
+		total += latency
+	}
+	return &Latency{
+		AverageMs: total / int64(len(latencies)),
+		P50Ms:     getPercentile(latencies, 50),
+		P95Ms:     getPercentile(latencies, 95),
+	}
+}
+
+func getPercentile(sorted []int64, percentile int) int64 {
+	rank := (len(sorted)*percentile + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Stats accumulates the errors and the latencies of the entries of every edge.
+type Stats struct {
+	edges map[edgeKey]*edgeStats
+}
+
+func NewStats() *Stats {
+	return &Stats{edges: map[edgeKey]*edgeStats{}}
+}
+
+func (stats *Stats) Add(entry *hub.BaseEntry) {
+	if entry.Source == nil || entry.Destination == nil {
+		return
+	}
+
+	key := edgeKey{
+		source:      getNodeKey(entry.Source.Name, entry.Source),
+		destination: getNodeKey(entry.Destination.Name, entry.Destination),
+		protocol:    entry.Protocol.Name,
+	}
+	edge, ok := stats.edges[key]
+	if !ok {
+		edge = &edgeStats{}
+		stats.edges[key] = edge
+	}
+
+	if entry.Status >= ErrorStatus {
+		edge.errors++
+	}
+	edge.latencies = append(edge.latencies, entry.Latency)
+}
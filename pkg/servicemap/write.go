@@ -0,0 +1,151 @@
+package servicemap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	FormatDot     = "dot"
+	FormatMermaid = "mermaid"
+	FormatJson    = "json"
+)
+
+var Formats = []string{FormatDot, FormatMermaid, FormatJson}
+
+// Write writes a graph in a format, the nodes of a namespace are grouped in DOT clusters and Mermaid subgraphs.
+func Write(out io.Writer, graph *Graph, format string) error {
+	switch format {
+	case FormatDot:
+		return writeDot(out, graph)
+	case FormatMermaid:
+		return writeMermaid(out, graph)
+	case FormatJson:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(graph)
+	default:
+		return fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+// getNamespaces returns the nodes of every namespace in the order of the nodes, the nodes without a namespace
+// under "".
+func (graph *Graph) getNamespaces() ([]string, map[string][]*Node) {
+	var namespaces []string
+	nodes := map[string][]*Node{}
+	for _, node := range graph.Nodes {
+		if _, ok := nodes[node.Namespace]; !ok {
+			namespaces = append(namespaces, node.Namespace)
+		}
+		nodes[node.Namespace] = append(nodes[node.Namespace], node)
+	}
+	return namespaces, nodes
+}
+
+func (node *Node) getLabelLines() []string {
+	lines := []string{node.Name}
+	if node.Workload != "" {
+		lines = append(lines, node.Workload)
+	}
+	return lines
+}
+
+func (edge *Edge) getLabelLines() []string {
+	var lines []string
+	if edge.Protocol != "" {
+		lines = append(lines, edge.Protocol)
+	}
+	if edge.Count == 1 {
+		lines = append(lines, "1 request")
+	} else {
+		lines = append(lines, fmt.Sprintf("%d requests", edge.Count))
+	}
+	if edge.Latency != nil {
+		lines = append(lines, fmt.Sprintf("%.1f%% errors", edge.ErrorRate*100), fmt.Sprintf("p95 %dms", edge.Latency.P95Ms))
+	}
+	return lines
+}
+
+func writeDot(out io.Writer, graph *Graph) error {
+	writer := bufio.NewWriter(out)
+	fmt.Fprintln(writer, "digraph kubeshark {")
+	fmt.Fprintln(writer, "  rankdir=LR;")
+	fmt.Fprintln(writer, `  node [shape=box, style=rounded, fontname="Helvetica"];`)
+	fmt.Fprintln(writer, `  edge [fontname="Helvetica", fontsize=10];`)
+
+	namespaces, nodes := graph.getNamespaces()
+	for i, namespace := range namespaces {
+		indent := "  "
+		if namespace != "" {
+			fmt.Fprintf(writer, "\n  subgraph cluster_%d {\n", i)
+			fmt.Fprintf(writer, "    label=%s;\n", quoteDot([]string{namespace}))
+			indent = "    "
+		}
+		for _, node := range nodes[namespace] {
+			fmt.Fprintf(writer, "%sn%d [label=%s];\n", indent, node.Id, quoteDot(node.getLabelLines()))
+		}
+		if namespace != "" {
+			fmt.Fprintln(writer, "  }")
+		}
+	}
+
+	if len(graph.Edges) > 0 {
+		fmt.Fprintln(writer)
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(writer, "  n%d -> n%d [label=%s];\n", edge.Source, edge.Destination, quoteDot(edge.getLabelLines()))
+	}
+
+	fmt.Fprintln(writer, "}")
+	return writer.Flush()
+}
+
+// quoteDot returns a DOT string of lines, centered.
+func quoteDot(lines []string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = escaper.Replace(line)
+	}
+	return `"` + strings.Join(escaped, `\n`) + `"`
+}
+
+func writeMermaid(out io.Writer, graph *Graph) error {
+	writer := bufio.NewWriter(out)
+	fmt.Fprintln(writer, "flowchart LR")
+
+	namespaces, nodes := graph.getNamespaces()
+	for i, namespace := range namespaces {
+		indent := "  "
+		if namespace != "" {
+			fmt.Fprintf(writer, "  subgraph ns%d [%s]\n", i, quoteMermaid([]string{namespace}))
+			indent = "    "
+		}
+		for _, node := range nodes[namespace] {
+			fmt.Fprintf(writer, "%sn%d[%s]\n", indent, node.Id, quoteMermaid(node.getLabelLines()))
+		}
+		if namespace != "" {
+			fmt.Fprintln(writer, "  end")
+		}
+	}
+
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(writer, "  n%d -->|%s| n%d\n", edge.Source, quoteMermaid([]string{strings.Join(edge.getLabelLines(), ", ")}), edge.Destination)
+	}
+
+	return writer.Flush()
+}
+
+// quoteMermaid returns a Mermaid string of lines, quotes are entity codes as Mermaid strings can't escape them.
+func quoteMermaid(lines []string) string {
+	escaper := strings.NewReplacer(`"`, "#quot;", "\n", " ")
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = escaper.Replace(line)
+	}
+	return `"` + strings.Join(escaped, "<br/>") + `"`
+}
@@ -0,0 +1,175 @@
+// Package snapshot reads and writes archives of the traffic a hub recorded, along with the session that
+// recorded it.
+package snapshot
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatVersion is increased when archives change in a way older versions can't load.
+	FormatVersion = 1
+
+	metadataFileName = "metadata.json"
+	dataDirName      = "data"
+)
+
+type Target struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// Metadata describes the session a snapshot was taken of.
+type Metadata struct {
+	FormatVersion int       `json:"formatVersion"`
+	CliVersion    string    `json:"cliVersion"`
+	CreatedAt     time.Time `json:"createdAt"`
+	Instance      string    `json:"instance"`
+	Namespace     string    `json:"namespace"`
+	Targets       []Target  `json:"targets"`
+	// Config is the configuration the hub ran with
+	Config json.RawMessage `json:"config,omitempty"`
+	// From and To are the times of the first and the last entries, they're zero when there are none
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Entries int       `json:"entries"`
+}
+
+// Save writes a gzipped tar archive holding metadata, and the files of data, the tar stream of the data directory
+// of the hub.
+func Save(out io.Writer, metadata *Metadata, data io.Reader) error {
+	gzipWriter := gzip.NewWriter(out)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	metadataJson, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tarWriter.WriteHeader(&tar.Header{
+		Name:    metadataFileName,
+		Mode:    0644,
+		Size:    int64(len(metadataJson)),
+		ModTime: metadata.CreatedAt,
+	}); err != nil {
+		return err
+	}
+	if _, err := tarWriter.Write(metadataJson); err != nil {
+		return err
+	}
+
+	if err := copyTar(tar.NewReader(data), tarWriter, func(name string) string {
+		return path.Join(dataDirName, name)
+	}); err != nil {
+		return fmt.Errorf("failed copying the data directory, %w", err)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// Snapshot is an archive opened for loading, its metadata was read and its data wasn't yet.
+type Snapshot struct {
+	Metadata  *Metadata
+	gzip      *gzip.Reader
+	tarReader *tar.Reader
+}
+
+func Open(in io.Reader) (*Snapshot, error) {
+	gzipReader, err := gzip.NewReader(in)
+	if err != nil {
+		return nil, fmt.Errorf("not a snapshot, %w", err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	header, err := tarReader.Next()
+	if err != nil {
+		return nil, fmt.Errorf("not a snapshot, %w", err)
+	}
+	if header.Name != metadataFileName {
+		return nil, fmt.Errorf("not a snapshot, %s must be its first file", metadataFileName)
+	}
+
+	var metadata Metadata
+	if err := json.NewDecoder(tarReader).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("invalid snapshot metadata, %w", err)
+	}
+	if metadata.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("the snapshot was saved by a newer version (format %d), upgrade kubeshark to load it", metadata.FormatVersion)
+	}
+
+	return &Snapshot{Metadata: &metadata, gzip: gzipReader, tarReader: tarReader}, nil
+}
+
+// WriteData writes the tar stream of the data directory, to be extracted in the data directory of a hub.
+func (snapshot *Snapshot) WriteData(out io.Writer) error {
+	tarWriter := tar.NewWriter(out)
+	prefix := dataDirName + "/"
+	if err := copyTar(snapshot.tarReader, tarWriter, func(name string) string {
+		if !strings.HasPrefix(name, prefix) {
+			return ""
+		}
+		return strings.TrimPrefix(name, prefix)
+	}); err != nil {
+		return err
+	}
+	return tarWriter.Close()
+}
+
+func (snapshot *Snapshot) Close() error {
+	return snapshot.gzip.Close()
+}
+
+// copyTar copies the files of a tar stream under the names rename returns, files it returns an empty name for
+// are dropped.
+func copyTar(in *tar.Reader, out *tar.Writer, rename func(name string) string) error {
+	for {
+		header, err := in.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if name == "." || name == ".." || strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			continue
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeDir {
+			continue
+		}
+		if name = rename(name); name == "" {
+			continue
+		}
+
+		copied := &tar.Header{
+			Typeflag: header.Typeflag,
+			Name:     name,
+			Mode:     header.Mode,
+			Size:     header.Size,
+			ModTime:  header.ModTime,
+		}
+		if header.Typeflag == tar.TypeDir {
+			copied.Name += "/"
+			copied.Size = 0
+		}
+		if err := out.WriteHeader(copied); err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := io.Copy(out, in); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+)
+
+func newDataTar(t *testing.T, files map[string]string) *bytes.Buffer {
+	var data bytes.Buffer
+	tarWriter := tar.NewWriter(&data)
+	if err := tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "./", Mode: 0755}); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &data
+}
+
+func TestSaveAndOpen(t *testing.T) {
+	metadata := &Metadata{
+		FormatVersion: FormatVersion,
+		CreatedAt:     time.Unix(1660000000, 0).UTC(),
+		Namespace:     "kubeshark",
+		Targets:       []Target{{Name: "checkout", Namespace: "default"}},
+		Config:        []byte(`{"serviceMap":true}`),
+		Entries:       2,
+	}
+	data := newDataTar(t, map[string]string{"./data_0.bin": "entries", "../escape": "outside"})
+
+	var archive bytes.Buffer
+	if err := Save(&archive, metadata, data); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := Open(&archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer snapshot.Close()
+
+	var config bytes.Buffer
+	if err := json.Compact(&config, snapshot.Metadata.Config); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Metadata.Namespace != "kubeshark" || len(snapshot.Metadata.Targets) != 1 || config.String() != `{"serviceMap":true}` {
+		t.Errorf("unexpected metadata %+v", snapshot.Metadata)
+	}
+
+	var restored bytes.Buffer
+	if err := snapshot.WriteData(&restored); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{}
+	tarReader := tar.NewReader(&restored)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(tarReader)
+		files[header.Name] = string(content)
+	}
+	if len(files) != 1 || files["data_0.bin"] != "entries" {
+		t.Errorf("unexpected restored files %v", files)
+	}
+}
+
+func TestOpenNewerFormat(t *testing.T) {
+	var archive bytes.Buffer
+	if err := Save(&archive, &Metadata{FormatVersion: FormatVersion + 1}, newDataTar(t, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(&archive); err == nil {
+		t.Error("expected snapshots of a newer format to be rejected")
+	}
+}
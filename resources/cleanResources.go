@@ -11,15 +11,48 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 )
 
-func CleanUpKubesharkResources(ctx context.Context, cancel context.CancelFunc, kubernetesProvider *kubernetes.Provider, isNsRestrictedMode bool, kubesharkResourcesNamespace string) {
-	log.Printf("\nRemoving kubeshark resources")
+type CleanUpOptions struct {
+	KeepData      bool
+	DryRun        bool
+	AllNamespaces bool
+	AllInstances  bool
+}
 
-	var leftoverResources []string
+// CleanUpKubesharkResources removes every object that carries the managed-by label, so objects added by
+// other Kubeshark versions are removed as well. With AllNamespaces it also removes the leftovers of sessions
+// that used other resource namespaces.
+func CleanUpKubesharkResources(ctx context.Context, cancel context.CancelFunc, kubernetesProvider *kubernetes.Provider, isNsRestrictedMode bool, kubesharkResourcesNamespace string, opts CleanUpOptions) {
+	if opts.DryRun {
+		log.Printf("\nListing kubeshark resources")
+	} else {
+		log.Printf("\nRemoving kubeshark resources")
+	}
 
-	if isNsRestrictedMode {
-		leftoverResources = cleanUpRestrictedMode(ctx, kubernetesProvider, kubesharkResourcesNamespace)
+	leftoverResources := make([]string, 0)
+	managedObjects := listManagedObjects(ctx, kubernetesProvider, isNsRestrictedMode, kubesharkResourcesNamespace, opts, &leftoverResources)
+
+	if opts.DryRun {
+		log.Printf("The following resources would be removed.")
+		for _, object := range managedObjects {
+			log.Printf("- %s", object)
+		}
 	} else {
-		leftoverResources = cleanUpNonRestrictedMode(ctx, cancel, kubernetesProvider, kubesharkResourcesNamespace)
+		var removedNamespaces []string
+		for _, object := range managedObjects {
+			if err := kubernetesProvider.RemoveManagedObject(ctx, object); err != nil {
+				handleDeletionError(err, object.String(), &leftoverResources)
+			} else if object.Kind == kubernetes.KindNamespace {
+				removedNamespaces = append(removedNamespaces, object.Name)
+			}
+		}
+
+		for _, namespace := range removedNamespaces {
+			waitUntilNamespaceDeleted(ctx, cancel, kubernetesProvider, namespace)
+		}
+	}
+
+	if opts.KeepData {
+		log.Printf("Kept the recorded traffic in persistent volume claim %s in namespace %s", kubernetes.PersistentVolumeClaimName, kubesharkResourcesNamespace)
 	}
 
 	if len(leftoverResources) > 0 {
@@ -31,41 +64,66 @@ func CleanUpKubesharkResources(ctx context.Context, cancel context.CancelFunc, k
 	}
 }
 
-func cleanUpNonRestrictedMode(ctx context.Context, cancel context.CancelFunc, kubernetesProvider *kubernetes.Provider, kubesharkResourcesNamespace string) []string {
-	leftoverResources := make([]string, 0)
-
-	if err := kubernetesProvider.RemoveNamespace(ctx, kubesharkResourcesNamespace); err != nil {
-		resourceDesc := fmt.Sprintf("Namespace %s", kubesharkResourcesNamespace)
-		handleDeletionError(err, resourceDesc, &leftoverResources)
-	} else {
-		defer waitUntilNamespaceDeleted(ctx, cancel, kubernetesProvider, kubesharkResourcesNamespace)
+func listManagedObjects(ctx context.Context, kubernetesProvider *kubernetes.Provider, isNsRestrictedMode bool, kubesharkResourcesNamespace string, opts CleanUpOptions, leftoverResources *[]string) []kubernetes.ManagedObject {
+	namespace := kubesharkResourcesNamespace
+	namespaceDesc := fmt.Sprintf("namespace %s", kubesharkResourcesNamespace)
+	if opts.AllNamespaces {
+		namespace = kubernetes.K8sAllNamespaces
+		namespaceDesc = "all namespaces"
 	}
 
-	if resources, err := kubernetesProvider.ListManagedClusterRoles(ctx); err != nil {
-		resourceDesc := "ClusterRoles"
-		handleDeletionError(err, resourceDesc, &leftoverResources)
-	} else {
-		for _, resource := range resources.Items {
-			if err := kubernetesProvider.RemoveClusterRole(ctx, resource.Name); err != nil {
-				resourceDesc := fmt.Sprintf("ClusterRole %s", resource.Name)
-				handleDeletionError(err, resourceDesc, &leftoverResources)
-			}
+	kinds := append([]string{}, kubernetes.NamespacedManagedKinds...)
+	if !isNsRestrictedMode {
+		if isOpenShift, err := kubernetesProvider.IsOpenShift(); err != nil {
+			handleDeletionError(err, kubernetes.KindSecurityContextConstraints, leftoverResources)
+		} else if isOpenShift {
+			kinds = append(kinds, kubernetes.KindSecurityContextConstraints)
 		}
+		kinds = append(kinds, kubernetes.ClusterManagedKinds...)
 	}
 
-	if resources, err := kubernetesProvider.ListManagedClusterRoleBindings(ctx); err != nil {
-		resourceDesc := "ClusterRoleBindings"
-		handleDeletionError(err, resourceDesc, &leftoverResources)
-	} else {
-		for _, resource := range resources.Items {
-			if err := kubernetesProvider.RemoveClusterRoleBinding(ctx, resource.Name); err != nil {
-				resourceDesc := fmt.Sprintf("ClusterRoleBinding %s", resource.Name)
-				handleDeletionError(err, resourceDesc, &leftoverResources)
+	// Namespaced kinds are listed first, so the namespaces shared with other instances are known
+	// by the time the namespaces are listed
+	sharedNamespaces := make(map[string]bool)
+	var managedObjects []kubernetes.ManagedObject
+	for _, kind := range kinds {
+		objects, err := kubernetesProvider.ListManagedObjects(ctx, kind, namespace)
+		if err != nil {
+			handleDeletionError(err, fmt.Sprintf("%s objects in %s", kind, namespaceDesc), leftoverResources)
+			continue
+		}
+
+		for _, object := range objects {
+			if !opts.AllInstances && object.Instance != kubernetes.Instance {
+				if object.Namespace != "" {
+					sharedNamespaces[object.Namespace] = true
+				}
+				continue
 			}
+			if shouldKeepManagedObject(object, kubesharkResourcesNamespace, opts, sharedNamespaces) {
+				continue
+			}
+			managedObjects = append(managedObjects, object)
 		}
 	}
 
-	return leftoverResources
+	return managedObjects
+}
+
+func shouldKeepManagedObject(object kubernetes.ManagedObject, kubesharkResourcesNamespace string, opts CleanUpOptions, sharedNamespaces map[string]bool) bool {
+	switch object.Kind {
+	case kubernetes.KindNamespace:
+		// Removing the namespace would remove the persistent volume claim along with it,
+		// and the objects of the other instances that use it
+		return opts.KeepData || sharedNamespaces[object.Name] || (!opts.AllNamespaces && object.Name != kubesharkResourcesNamespace)
+	case kubernetes.KindPersistentVolumeClaim:
+		return opts.KeepData
+	case kubernetes.KindSecret:
+		// The auth secret encrypts the data kept in the persistent volume claim, so they are kept together
+		return opts.KeepData && object.Name == kubernetes.AuthSecretName
+	}
+
+	return false
 }
 
 func waitUntilNamespaceDeleted(ctx context.Context, cancel context.CancelFunc, kubernetesProvider *kubernetes.Provider, kubesharkResourcesNamespace string) {
@@ -86,68 +144,6 @@ func waitUntilNamespaceDeleted(ctx context.Context, cancel context.CancelFunc, k
 	}
 }
 
-func cleanUpRestrictedMode(ctx context.Context, kubernetesProvider *kubernetes.Provider, kubesharkResourcesNamespace string) []string {
-	leftoverResources := make([]string, 0)
-
-	if err := kubernetesProvider.RemoveService(ctx, kubesharkResourcesNamespace, kubernetes.HubServiceName); err != nil {
-		resourceDesc := fmt.Sprintf("Service %s in namespace %s", kubernetes.HubServiceName, kubesharkResourcesNamespace)
-		handleDeletionError(err, resourceDesc, &leftoverResources)
-	}
-
-	if err := kubernetesProvider.RemoveDaemonSet(ctx, kubesharkResourcesNamespace, kubernetes.TapperDaemonSetName); err != nil {
-		resourceDesc := fmt.Sprintf("DaemonSet %s in namespace %s", kubernetes.TapperDaemonSetName, kubesharkResourcesNamespace)
-		handleDeletionError(err, resourceDesc, &leftoverResources)
-	}
-
-	if err := kubernetesProvider.RemoveConfigMap(ctx, kubesharkResourcesNamespace, kubernetes.ConfigMapName); err != nil {
-		resourceDesc := fmt.Sprintf("ConfigMap %s in namespace %s", kubernetes.ConfigMapName, kubesharkResourcesNamespace)
-		handleDeletionError(err, resourceDesc, &leftoverResources)
-	}
-
-	if resources, err := kubernetesProvider.ListManagedServiceAccounts(ctx, kubesharkResourcesNamespace); err != nil {
-		resourceDesc := fmt.Sprintf("ServiceAccounts in namespace %s", kubesharkResourcesNamespace)
-		handleDeletionError(err, resourceDesc, &leftoverResources)
-	} else {
-		for _, resource := range resources.Items {
-			if err := kubernetesProvider.RemoveServiceAccount(ctx, kubesharkResourcesNamespace, resource.Name); err != nil {
-				resourceDesc := fmt.Sprintf("ServiceAccount %s in namespace %s", resource.Name, kubesharkResourcesNamespace)
-				handleDeletionError(err, resourceDesc, &leftoverResources)
-			}
-		}
-	}
-
-	if resources, err := kubernetesProvider.ListManagedRoles(ctx, kubesharkResourcesNamespace); err != nil {
-		resourceDesc := fmt.Sprintf("Roles in namespace %s", kubesharkResourcesNamespace)
-		handleDeletionError(err, resourceDesc, &leftoverResources)
-	} else {
-		for _, resource := range resources.Items {
-			if err := kubernetesProvider.RemoveRole(ctx, kubesharkResourcesNamespace, resource.Name); err != nil {
-				resourceDesc := fmt.Sprintf("Role %s in namespace %s", resource.Name, kubesharkResourcesNamespace)
-				handleDeletionError(err, resourceDesc, &leftoverResources)
-			}
-		}
-	}
-
-	if resources, err := kubernetesProvider.ListManagedRoleBindings(ctx, kubesharkResourcesNamespace); err != nil {
-		resourceDesc := fmt.Sprintf("RoleBindings in namespace %s", kubesharkResourcesNamespace)
-		handleDeletionError(err, resourceDesc, &leftoverResources)
-	} else {
-		for _, resource := range resources.Items {
-			if err := kubernetesProvider.RemoveRoleBinding(ctx, kubesharkResourcesNamespace, resource.Name); err != nil {
-				resourceDesc := fmt.Sprintf("RoleBinding %s in namespace %s", resource.Name, kubesharkResourcesNamespace)
-				handleDeletionError(err, resourceDesc, &leftoverResources)
-			}
-		}
-	}
-
-	if err := kubernetesProvider.RemovePod(ctx, kubesharkResourcesNamespace, kubernetes.HubPodName); err != nil {
-		resourceDesc := fmt.Sprintf("Pod %s in namespace %s", kubernetes.HubPodName, kubesharkResourcesNamespace)
-		handleDeletionError(err, resourceDesc, &leftoverResources)
-	}
-
-	return leftoverResources
-}
-
 func handleDeletionError(err error, resourceDesc string, leftoverResources *[]string) {
 	log.Printf("Error removing %s: %v", resourceDesc, errormessage.FormatError(err))
 	*leftoverResources = append(*leftoverResources, resourceDesc)
@@ -2,10 +2,16 @@ package resources
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/config/configStructs"
 	"github.com/kubeshark/kubeshark/errormessage"
 	"github.com/kubeshark/kubeshark/kubernetes"
 	"github.com/kubeshark/kubeshark/kubeshark"
@@ -13,24 +19,98 @@ import (
 	"github.com/kubeshark/worker/models"
 	"github.com/op/go-logging"
 	core "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
-func CreateTapKubesharkResources(ctx context.Context, kubernetesProvider *kubernetes.Provider, serializedKubesharkConfig string, isNsRestrictedMode bool, kubesharkResourcesNamespace string, maxEntriesDBSizeBytes int64, hubResources models.Resources, imagePullPolicy core.PullPolicy, logLevel logging.Level, profiler bool) (bool, error) {
+// CreateTapKubesharkResources creates the resources tap needs. When it fails or is interrupted, it rolls back
+// the resources it created before returning, resources that existed before the call are left untouched.
+func CreateTapKubesharkResources(ctx context.Context, kubernetesProvider *kubernetes.Provider, sessionHolder *kubernetes.SessionHolder, serializedKubesharkConfig string, isNsRestrictedMode bool, kubesharkResourcesNamespace string, maxEntriesDBSizeBytes int64, hubResources models.Resources, basenineResources models.Resources, frontResources models.Resources, imagePullPolicy core.PullPolicy, logLevel logging.Level, profiler bool, waitForRestore bool) (bool, error) {
+	creationCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go cancelOnTerminationSignal(creationCtx, cancel)
+
+	createdResources := &inventory{}
+	kubesharkServiceAccountExists, err := createTapKubesharkResources(creationCtx, kubernetesProvider, createdResources, sessionHolder, serializedKubesharkConfig, isNsRestrictedMode, kubesharkResourcesNamespace, maxEntriesDBSizeBytes, hubResources, basenineResources, frontResources, imagePullPolicy, logLevel, profiler, waitForRestore)
+	if err != nil {
+		createdResources.rollback()
+	}
+
+	return kubesharkServiceAccountExists, err
+}
+
+func cancelOnTerminationSignal(ctx context.Context, cancel context.CancelFunc) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer signal.Stop(sigChan)
+
+	select {
+	case <-ctx.Done():
+	case <-sigChan:
+		log.Printf("Got termination signal, canceling the creation of resources...")
+		cancel()
+	}
+}
+
+func createTapKubesharkResources(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, sessionHolder *kubernetes.SessionHolder, serializedKubesharkConfig string, isNsRestrictedMode bool, kubesharkResourcesNamespace string, maxEntriesDBSizeBytes int64, hubResources models.Resources, basenineResources models.Resources, frontResources models.Resources, imagePullPolicy core.PullPolicy, logLevel logging.Level, profiler bool, waitForRestore bool) (bool, error) {
+	var namespaceExists bool
 	if !isNsRestrictedMode {
-		if err := createKubesharkNamespace(ctx, kubernetesProvider, kubesharkResourcesNamespace); err != nil {
+		var err error
+		if namespaceExists, err = kubernetesProvider.DoesNamespaceExist(ctx, kubesharkResourcesNamespace); err != nil {
+			return false, err
+		}
+
+		// The lease of the session lives in the namespace, so a missing namespace is created ahead of the session lock
+		if !namespaceExists {
+			if err := createKubesharkNamespace(ctx, kubernetesProvider, createdResources, kubesharkResourcesNamespace, false); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	// The session lock comes before anything that can fail because of another session, so a run that finds a session
+	// running is told who holds it
+	if err := acquireSessionLock(ctx, kubernetesProvider, createdResources, kubesharkResourcesNamespace, sessionHolder, false); err != nil {
+		return false, err
+	}
+
+	if namespaceExists {
+		if err := createKubesharkNamespace(ctx, kubernetesProvider, createdResources, kubesharkResourcesNamespace, true); err != nil {
 			return false, err
 		}
 	}
 
-	if err := createKubesharkConfigmap(ctx, kubernetesProvider, serializedKubesharkConfig, kubesharkResourcesNamespace); err != nil {
+	if err := createKubesharkConfigmap(ctx, kubernetesProvider, createdResources, serializedKubesharkConfig, kubesharkResourcesNamespace); err != nil {
 		return false, err
 	}
 
-	kubesharkServiceAccountExists, err := createRBACIfNecessary(ctx, kubernetesProvider, isNsRestrictedMode, kubesharkResourcesNamespace, []string{"pods", "services", "endpoints"})
+	if config.Config.Tap.Storage.Enabled {
+		if err := createKubesharkPersistentVolumeClaim(ctx, kubernetesProvider, createdResources, kubesharkResourcesNamespace); err != nil {
+			return false, err
+		}
+	}
+
+	if config.Config.Auth.Enabled {
+		if err := createKubesharkAuthSecret(ctx, kubernetesProvider, createdResources, kubesharkResourcesNamespace); err != nil {
+			return false, err
+		}
+	}
+
+	kubesharkServiceAccountExists, err := createRBACIfNecessary(ctx, kubernetesProvider, createdResources, isNsRestrictedMode, kubesharkResourcesNamespace, []string{"pods", "services", "endpoints"})
 	if err != nil {
 		log.Printf(utils.Warning, fmt.Sprintf("Failed to ensure the resources required for IP resolving. Kubeshark will not resolve target IPs to names. error: %v", errormessage.FormatError(err)))
 	}
 
+	if config.Config.Tap.IsReaperNeeded() {
+		if err := createKubesharkReaper(ctx, kubernetesProvider, createdResources, isNsRestrictedMode, kubesharkResourcesNamespace); err != nil {
+			return kubesharkServiceAccountExists, newReaperError(err)
+		}
+	} else if config.Config.Tap.Detach {
+		// The reaper of an earlier attached session would remove the detached session, which is never renewed
+		if err := kubernetesProvider.RemoveReaper(ctx, kubesharkResourcesNamespace, isNsRestrictedMode); err != nil {
+			return kubesharkServiceAccountExists, err
+		}
+	}
+
 	var serviceAccountName string
 	if kubesharkServiceAccountExists {
 		serviceAccountName = kubernetes.ServiceAccountName
@@ -42,15 +122,19 @@ func CreateTapKubesharkResources(ctx context.Context, kubernetesProvider *kubern
 		Namespace:             kubesharkResourcesNamespace,
 		PodName:               kubernetes.HubPodName,
 		PodImage:              "kubeshark/hub:latest",
-		KratosImage:           "",
-		KetoImage:             "",
+		KratosImage:           config.Config.Auth.KratosImage,
+		KetoImage:             config.Config.Auth.KetoImage,
+		AuthResources:         config.Config.Auth.Resources,
+		AuthSecretName:        kubernetes.AuthSecretName,
 		ServiceAccountName:    serviceAccountName,
 		IsNamespaceRestricted: isNsRestrictedMode,
 		MaxEntriesDBSizeBytes: maxEntriesDBSizeBytes,
 		Resources:             hubResources,
+		BasenineResources:     basenineResources,
 		ImagePullPolicy:       imagePullPolicy,
 		LogLevel:              logLevel,
 		Profiler:              profiler,
+		WaitForRestore:        waitForRestore,
 	}
 
 	frontOpts := &kubernetes.HubOptions{
@@ -62,54 +146,144 @@ func CreateTapKubesharkResources(ctx context.Context, kubernetesProvider *kubern
 		ServiceAccountName:    serviceAccountName,
 		IsNamespaceRestricted: isNsRestrictedMode,
 		MaxEntriesDBSizeBytes: maxEntriesDBSizeBytes,
-		Resources:             hubResources,
+		Resources:             frontResources,
 		ImagePullPolicy:       imagePullPolicy,
 		LogLevel:              logLevel,
 		Profiler:              profiler,
 	}
 
-	if err := createKubesharkHubPod(ctx, kubernetesProvider, opts); err != nil {
+	if err := createKubesharkHubPod(ctx, kubernetesProvider, createdResources, opts); err != nil {
 		return kubesharkServiceAccountExists, err
 	}
 
-	if err := createFrontPod(ctx, kubernetesProvider, frontOpts); err != nil {
+	if err := createFrontPod(ctx, kubernetesProvider, createdResources, frontOpts); err != nil {
 		return kubesharkServiceAccountExists, err
 	}
 
-	_, err = kubernetesProvider.CreateService(ctx, kubesharkResourcesNamespace, kubernetes.HubServiceName, kubernetes.HubServiceName, 80, int32(config.Config.Hub.PortForward.DstPort), int32(config.Config.Hub.PortForward.SrcPort))
-	if err != nil {
+	if err := createKubesharkService(ctx, kubernetesProvider, createdResources, kubesharkResourcesNamespace, kubernetes.HubServiceName, kubernetes.HubIngressName, config.Config.Hub.PortForward, config.Config.Hub.Expose); err != nil {
 		return kubesharkServiceAccountExists, err
 	}
 
-	log.Printf("Successfully created service: %s", kubernetes.HubServiceName)
-
-	_, err = kubernetesProvider.CreateService(ctx, kubesharkResourcesNamespace, kubernetes.FrontServiceName, kubernetes.FrontServiceName, 80, int32(config.Config.Front.PortForward.DstPort), int32(config.Config.Front.PortForward.SrcPort))
-	if err != nil {
+	if err := createKubesharkService(ctx, kubernetesProvider, createdResources, kubesharkResourcesNamespace, kubernetes.FrontServiceName, kubernetes.FrontIngressName, config.Config.Front.PortForward, config.Config.Front.Expose); err != nil {
 		return kubesharkServiceAccountExists, err
 	}
 
-	log.Printf("Successfully created service: %s", kubernetes.FrontServiceName)
-
 	return kubesharkServiceAccountExists, nil
 }
 
-func createKubesharkNamespace(ctx context.Context, kubernetesProvider *kubernetes.Provider, kubesharkResourcesNamespace string) error {
-	_, err := kubernetesProvider.CreateNamespace(ctx, kubesharkResourcesNamespace)
+func createKubesharkNamespace(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, kubesharkResourcesNamespace string, exists bool) error {
+	// The namespace is reused when it exists, e.g. after a run that crashed, by another instance or by `clean --keep-data`,
+	// the session lease decides whether this run may go ahead
+	if exists {
+		log.Printf("Using existing namespace: %s", kubesharkResourcesNamespace)
+	}
+
+	createdResources.recordUnlessExisting(exists, "Namespace", "", kubesharkResourcesNamespace, func(ctx context.Context) error {
+		return kubernetesProvider.RemoveNamespace(ctx, kubesharkResourcesNamespace)
+	})
+	_, err := kubernetesProvider.ApplyNamespace(ctx, kubesharkResourcesNamespace)
 	return err
 }
 
-func createKubesharkConfigmap(ctx context.Context, kubernetesProvider *kubernetes.Provider, serializedKubesharkConfig string, kubesharkResourcesNamespace string) error {
-	err := kubernetesProvider.CreateConfigMap(ctx, kubesharkResourcesNamespace, kubernetes.ConfigMapName, serializedKubesharkConfig)
-	return err
+func createKubesharkPersistentVolumeClaim(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, kubesharkResourcesNamespace string) error {
+	_, err := kubernetesProvider.CreatePersistentVolumeClaim(ctx, kubesharkResourcesNamespace, kubernetes.PersistentVolumeClaimName, config.Config.Tap.Storage.Size, config.Config.Tap.Storage.StorageClass)
+	if k8serrors.IsAlreadyExists(err) {
+		log.Printf("Reusing the traffic recorded in persistent volume claim: %s", kubernetes.PersistentVolumeClaimName)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	createdResources.record("PersistentVolumeClaim", kubesharkResourcesNamespace, kubernetes.PersistentVolumeClaimName, func(ctx context.Context) error {
+		return kubernetesProvider.RemovePersistentVolumeClaim(ctx, kubesharkResourcesNamespace, kubernetes.PersistentVolumeClaimName)
+	})
+	log.Printf("Successfully created persistent volume claim: %s", kubernetes.PersistentVolumeClaimName)
+	return nil
+}
+
+// createKubesharkAuthSecret generates the secrets the kratos container signs cookies and encrypts data with,
+// an existing secret is kept so sessions and data stored in a persistent volume claim stay valid.
+func createKubesharkAuthSecret(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, kubesharkResourcesNamespace string) error {
+	data := make(map[string][]byte)
+	for _, key := range []string{"SECRETS_DEFAULT", "SECRETS_COOKIE", "SECRETS_CIPHER"} {
+		// kratos requires the cipher secret to be exactly 32 characters long
+		secret, err := generateRandomSecret(16)
+		if err != nil {
+			return err
+		}
+		data[key] = []byte(secret)
+	}
+
+	_, err := kubernetesProvider.CreateSecret(ctx, kubesharkResourcesNamespace, kubernetes.AuthSecretName, data)
+	if k8serrors.IsAlreadyExists(err) {
+		log.Printf("Using existing secret: %s", kubernetes.AuthSecretName)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	createdResources.record("Secret", kubesharkResourcesNamespace, kubernetes.AuthSecretName, func(ctx context.Context) error {
+		return kubernetesProvider.RemoveSecret(ctx, kubesharkResourcesNamespace, kubernetes.AuthSecretName)
+	})
+	log.Printf("Successfully created secret: %s", kubernetes.AuthSecretName)
+	return nil
 }
 
-func createRBACIfNecessary(ctx context.Context, kubernetesProvider *kubernetes.Provider, isNsRestrictedMode bool, kubesharkResourcesNamespace string, resources []string) (bool, error) {
+func generateRandomSecret(length int) (string, error) {
+	bytes := make([]byte, length)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+func createKubesharkConfigmap(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, serializedKubesharkConfig string, kubesharkResourcesNamespace string) error {
+	exists, err := kubernetesProvider.DoesConfigMapExist(ctx, kubesharkResourcesNamespace, kubernetes.ConfigMapName)
+	if err != nil {
+		return err
+	}
+
+	createdResources.recordUnlessExisting(exists, "ConfigMap", kubesharkResourcesNamespace, kubernetes.ConfigMapName, func(ctx context.Context) error {
+		return kubernetesProvider.RemoveConfigMap(ctx, kubesharkResourcesNamespace, kubernetes.ConfigMapName)
+	})
+	return kubernetesProvider.ApplyConfigMap(ctx, kubesharkResourcesNamespace, kubernetes.ConfigMapName, serializedKubesharkConfig)
+}
+
+func createRBACIfNecessary(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, isNsRestrictedMode bool, kubesharkResourcesNamespace string, resources []string) (bool, error) {
+	if err := recordMissingServiceAccount(ctx, kubernetesProvider, createdResources, kubesharkResourcesNamespace); err != nil {
+		return false, err
+	}
+
 	if !isNsRestrictedMode {
-		if err := kubernetesProvider.CreateKubesharkRBAC(ctx, kubesharkResourcesNamespace, kubernetes.ServiceAccountName, kubernetes.ClusterRoleName, kubernetes.ClusterRoleBindingName, kubeshark.RBACVersion, resources); err != nil {
+		if err := recordMissingClusterRBAC(ctx, kubernetesProvider, createdResources); err != nil {
 			return false, err
 		}
+
+		if err := kubernetesProvider.ApplyKubesharkRBAC(ctx, kubesharkResourcesNamespace, kubernetes.ServiceAccountName, kubernetes.ClusterRoleName, kubernetes.ClusterRoleBindingName, kubeshark.RBACVersion, resources); err != nil {
+			return false, err
+		}
+
+		isOpenShift, err := kubernetesProvider.IsOpenShift()
+		if err != nil {
+			return false, err
+		}
+		if isOpenShift {
+			log.Printf("OpenShift detected, applying security context constraints %s", kubernetes.SecurityContextConstraintsName)
+			exists, err := kubernetesProvider.DoesSecurityContextConstraintsExist(ctx, kubernetes.SecurityContextConstraintsName)
+			if err != nil {
+				return false, err
+			}
+			createdResources.recordUnlessExisting(exists, "SecurityContextConstraints", "", kubernetes.SecurityContextConstraintsName, func(ctx context.Context) error {
+				return kubernetesProvider.RemoveSecurityContextConstraints(ctx, kubernetes.SecurityContextConstraintsName)
+			})
+			if err := kubernetesProvider.ApplyKubesharkSecurityContextConstraints(ctx, kubesharkResourcesNamespace, kubernetes.ServiceAccountName, kubernetes.SecurityContextConstraintsName, kubeshark.RBACVersion); err != nil {
+				return false, err
+			}
+		}
 	} else {
-		if err := kubernetesProvider.CreateKubesharkRBACNamespaceRestricted(ctx, kubesharkResourcesNamespace, kubernetes.ServiceAccountName, kubernetes.RoleName, kubernetes.RoleBindingName, kubeshark.RBACVersion); err != nil {
+		if err := recordMissingNamespacedRBAC(ctx, kubernetesProvider, createdResources, kubesharkResourcesNamespace); err != nil {
+			return false, err
+		}
+
+		if err := kubernetesProvider.ApplyKubesharkRBACNamespaceRestricted(ctx, kubesharkResourcesNamespace, kubernetes.ServiceAccountName, kubernetes.RoleName, kubernetes.RoleBindingName, kubeshark.RBACVersion); err != nil {
 			return false, err
 		}
 	}
@@ -117,26 +291,156 @@ func createRBACIfNecessary(ctx context.Context, kubernetesProvider *kubernetes.P
 	return true, nil
 }
 
-func createKubesharkHubPod(ctx context.Context, kubernetesProvider *kubernetes.Provider, opts *kubernetes.HubOptions) error {
-	pod, err := kubernetesProvider.BuildHubPod(opts, false, "", false)
+func recordMissingServiceAccount(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, kubesharkResourcesNamespace string) error {
+	exists, err := kubernetesProvider.DoesServiceAccountExist(ctx, kubesharkResourcesNamespace, kubernetes.ServiceAccountName)
 	if err != nil {
 		return err
 	}
-	if _, err = kubernetesProvider.CreatePod(ctx, opts.Namespace, pod); err != nil {
+	createdResources.recordUnlessExisting(exists, "ServiceAccount", kubesharkResourcesNamespace, kubernetes.ServiceAccountName, func(ctx context.Context) error {
+		return kubernetesProvider.RemoveServiceAccount(ctx, kubesharkResourcesNamespace, kubernetes.ServiceAccountName)
+	})
+	return nil
+}
+
+func recordMissingClusterRBAC(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory) error {
+	exists, err := kubernetesProvider.DoesClusterRoleExist(ctx, kubernetes.ClusterRoleName)
+	if err != nil {
+		return err
+	}
+	createdResources.recordUnlessExisting(exists, "ClusterRole", "", kubernetes.ClusterRoleName, func(ctx context.Context) error {
+		return kubernetesProvider.RemoveClusterRole(ctx, kubernetes.ClusterRoleName)
+	})
+
+	exists, err = kubernetesProvider.DoesClusterRoleBindingExist(ctx, kubernetes.ClusterRoleBindingName)
+	if err != nil {
 		return err
 	}
-	log.Printf("Successfully created pod: [%s]", pod.Name)
+	createdResources.recordUnlessExisting(exists, "ClusterRoleBinding", "", kubernetes.ClusterRoleBindingName, func(ctx context.Context) error {
+		return kubernetesProvider.RemoveClusterRoleBinding(ctx, kubernetes.ClusterRoleBindingName)
+	})
 	return nil
 }
 
-func createFrontPod(ctx context.Context, kubernetesProvider *kubernetes.Provider, opts *kubernetes.HubOptions) error {
-	pod, err := kubernetesProvider.BuildFrontPod(opts, false, "", false)
+func recordMissingNamespacedRBAC(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, kubesharkResourcesNamespace string) error {
+	exists, err := kubernetesProvider.DoesRoleExist(ctx, kubesharkResourcesNamespace, kubernetes.RoleName)
+	if err != nil {
+		return err
+	}
+	createdResources.recordUnlessExisting(exists, "Role", kubesharkResourcesNamespace, kubernetes.RoleName, func(ctx context.Context) error {
+		return kubernetesProvider.RemoveRole(ctx, kubesharkResourcesNamespace, kubernetes.RoleName)
+	})
+
+	exists, err = kubernetesProvider.DoesRoleBindingExist(ctx, kubesharkResourcesNamespace, kubernetes.RoleBindingName)
 	if err != nil {
 		return err
 	}
-	if _, err = kubernetesProvider.CreatePod(ctx, opts.Namespace, pod); err != nil {
+	createdResources.recordUnlessExisting(exists, "RoleBinding", kubesharkResourcesNamespace, kubernetes.RoleBindingName, func(ctx context.Context) error {
+		return kubernetesProvider.RemoveRoleBinding(ctx, kubesharkResourcesNamespace, kubernetes.RoleBindingName)
+	})
+	return nil
+}
+
+// createKubesharkReaper creates the reaper that removes the instance once the lease of the session expires,
+// it is created after the session lock so it never finds the lease missing.
+func createKubesharkReaper(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, isNsRestrictedMode bool, kubesharkResourcesNamespace string) error {
+	exists, err := kubernetesProvider.DoesReaperExist(ctx, kubesharkResourcesNamespace, isNsRestrictedMode)
+	if err != nil {
 		return err
 	}
-	log.Printf("Successfully created pod: [%s]", pod.Name)
+	createdResources.recordUnlessExisting(exists, "Reaper", kubesharkResourcesNamespace, kubernetes.ReaperName, func(ctx context.Context) error {
+		return kubernetesProvider.RemoveReaper(ctx, kubesharkResourcesNamespace, isNsRestrictedMode)
+	})
+	if err := kubernetesProvider.ApplyReaper(ctx, &kubernetes.ReaperOptions{
+		Namespace:             kubesharkResourcesNamespace,
+		Image:                 config.Config.Tap.Reaper.Image,
+		ImagePullPolicy:       config.Config.ImagePullPolicy(),
+		IsNamespaceRestricted: isNsRestrictedMode,
+		KeepData:              config.Config.Clean.KeepData,
+	}); err != nil {
+		return err
+	}
+	log.Printf("Successfully applied reaper: %s, it removes Kubeshark %s after this run stops renewing lease %s", kubernetes.ReaperName, config.Config.Tap.Reaper.GracePeriod, kubernetes.LeaseName)
 	return nil
 }
+
+func newReaperError(err error) error {
+	return fmt.Errorf("failed to create the reaper, which removes Kubeshark if this run ends without cleaning up, run with --%s or --%s tap.reaper.enabled=false to run without it: %w", configStructs.DetachTapName, config.SetCommandName, err)
+}
+
+func createKubesharkService(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, kubesharkResourcesNamespace string, serviceName string, ingressName string, portForward config.PortForward, expose config.ExposeConfig) error {
+	serviceType := core.ServiceTypeClusterIP
+	switch expose.Type {
+	case config.ExposeTypeNodePort:
+		serviceType = core.ServiceTypeNodePort
+	case config.ExposeTypeLoadBalancer:
+		serviceType = core.ServiceTypeLoadBalancer
+	}
+
+	exists, err := kubernetesProvider.DoesServiceExist(ctx, kubesharkResourcesNamespace, serviceName)
+	if err != nil {
+		return err
+	}
+
+	createdResources.recordUnlessExisting(exists, "Service", kubesharkResourcesNamespace, serviceName, func(ctx context.Context) error {
+		return kubernetesProvider.RemoveService(ctx, kubesharkResourcesNamespace, serviceName)
+	})
+	if _, err := kubernetesProvider.ApplyService(ctx, kubesharkResourcesNamespace, serviceName, serviceName, 80, int32(portForward.DstPort), serviceType, expose.NodePort); err != nil {
+		return err
+	}
+	log.Printf("Successfully applied service: %s", serviceName)
+
+	if expose.Type == config.ExposeTypeIngress {
+		ingressOptions := kubernetes.IngressOptions{
+			Host:          expose.Ingress.Host,
+			TlsSecretName: expose.Ingress.TlsSecretName,
+			ClassName:     expose.Ingress.ClassName,
+			Annotations:   expose.Ingress.Annotations,
+		}
+		exists, err := kubernetesProvider.DoesIngressExist(ctx, kubesharkResourcesNamespace, ingressName)
+		if err != nil {
+			return err
+		}
+
+		createdResources.recordUnlessExisting(exists, "Ingress", kubesharkResourcesNamespace, ingressName, func(ctx context.Context) error {
+			return kubernetesProvider.RemoveIngress(ctx, kubesharkResourcesNamespace, ingressName)
+		})
+		if _, err := kubernetesProvider.ApplyIngress(ctx, kubesharkResourcesNamespace, ingressName, serviceName, int32(portForward.DstPort), ingressOptions); err != nil {
+			return err
+		}
+		log.Printf("Successfully applied ingress: %s", ingressName)
+	}
+
+	return nil
+}
+
+func applyKubesharkPod(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, namespace string, pod *core.Pod) error {
+	exists, err := kubernetesProvider.DoesPodExist(ctx, namespace, pod.Name)
+	if err != nil {
+		return err
+	}
+
+	createdResources.recordUnlessExisting(exists, "Pod", namespace, pod.Name, func(ctx context.Context) error {
+		return kubernetesProvider.RemovePod(ctx, namespace, pod.Name)
+	})
+	if _, err = kubernetesProvider.ApplyPod(ctx, namespace, pod); err != nil {
+		return err
+	}
+	log.Printf("Successfully applied pod: [%s]", pod.Name)
+	return nil
+}
+
+func createKubesharkHubPod(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, opts *kubernetes.HubOptions) error {
+	pod, err := kubernetesProvider.BuildHubPod(opts, config.Config.Tap.Storage.Enabled, kubernetes.PersistentVolumeClaimName, config.Config.Auth.Enabled)
+	if err != nil {
+		return err
+	}
+	return applyKubesharkPod(ctx, kubernetesProvider, createdResources, opts.Namespace, pod)
+}
+
+func createFrontPod(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, opts *kubernetes.HubOptions) error {
+	pod, err := kubernetesProvider.BuildFrontPod(opts, false, "", false)
+	if err != nil {
+		return err
+	}
+	return applyKubesharkPod(ctx, kubernetesProvider, createdResources, opts.Namespace, pod)
+}
@@ -0,0 +1,222 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/creasty/defaults"
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/kubernetes"
+	"github.com/op/go-logging"
+	core "k8s.io/api/core/v1"
+)
+
+const testNamespace = "kubeshark"
+
+// apiServer is an in memory API server that stores the objects it is sent by their path, it serves just
+// enough of the API for the resources of a tap to be created.
+type apiServer struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+func (server *apiServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	writer.Header().Set("Content-Type", "application/json")
+	switch request.URL.Path {
+	case "/api":
+		_, _ = writer.Write([]byte(`{"kind":"APIVersions","versions":["v1"]}`))
+		return
+	case "/apis":
+		_, _ = writer.Write([]byte(`{"kind":"APIGroupList","apiVersion":"v1","groups":[{"name":"batch","versions":[{"groupVersion":"batch/v1","version":"v1"}],"preferredVersion":{"groupVersion":"batch/v1","version":"v1"}}]}`))
+		return
+	case "/apis/batch/v1":
+		_, _ = writer.Write([]byte(`{"kind":"APIResourceList","apiVersion":"v1","groupVersion":"batch/v1","resources":[{"name":"cronjobs","namespaced":true,"kind":"CronJob","verbs":["get","patch","delete"]}]}`))
+		return
+	}
+
+	body, _ := io.ReadAll(request.Body)
+	path := request.URL.Path
+	switch request.Method {
+	case http.MethodGet:
+		if object, ok := server.objects[path]; ok {
+			_, _ = writer.Write(object)
+			return
+		}
+		writeStatus(writer, http.StatusNotFound, "NotFound")
+	case http.MethodPost:
+		var object struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(body, &object); err != nil {
+			writeStatus(writer, http.StatusBadRequest, "BadRequest")
+			return
+		}
+		path = fmt.Sprintf("%s/%s", path, object.Metadata.Name)
+		if _, ok := server.objects[path]; ok {
+			writeStatus(writer, http.StatusConflict, "AlreadyExists")
+			return
+		}
+		server.objects[path] = body
+		writer.WriteHeader(http.StatusCreated)
+		_, _ = writer.Write(body)
+	case http.MethodPut, http.MethodPatch:
+		server.objects[path] = body
+		_, _ = writer.Write(body)
+	case http.MethodDelete:
+		if _, ok := server.objects[path]; !ok {
+			writeStatus(writer, http.StatusNotFound, "NotFound")
+			return
+		}
+		delete(server.objects, path)
+		writeStatus(writer, http.StatusOK, "")
+	}
+}
+
+func (server *apiServer) hasObject(path string) bool {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	_, ok := server.objects[path]
+	return ok
+}
+
+func writeStatus(writer http.ResponseWriter, code int, reason string) {
+	status := "Success"
+	if code >= http.StatusBadRequest {
+		status = "Failure"
+	}
+	writer.WriteHeader(code)
+	_, _ = writer.Write([]byte(fmt.Sprintf(`{"kind":"Status","apiVersion":"v1","status":%q,"reason":%q,"code":%d}`, status, reason, code)))
+}
+
+// newTestProvider returns a provider of an in memory API server that holds the given objects.
+func newTestProvider(t *testing.T, objects map[string]string) (*kubernetes.Provider, *apiServer) {
+	if err := defaults.Set(&config.Config); err != nil {
+		t.Fatal(err)
+	}
+	kubernetes.SetInstance(kubernetes.DefaultInstance)
+	t.Cleanup(func() {
+		kubernetes.SetInstance(kubernetes.DefaultInstance)
+	})
+
+	server := &apiServer{objects: make(map[string][]byte)}
+	for path, object := range objects {
+		server.objects[path] = []byte(object)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	kubeConfigPath := filepath.Join(t.TempDir(), "config")
+	kubeConfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: %s
+users:
+- name: admin
+  user:
+    token: test
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: admin
+current-context: test
+`, httpServer.URL)
+	if err := os.WriteFile(kubeConfigPath, []byte(kubeConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	kubernetesProvider, err := kubernetes.NewProvider(kubeConfigPath, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kubernetesProvider, server
+}
+
+func createTestTapResources(kubernetesProvider *kubernetes.Provider, createdResources *inventory, holder *kubernetes.SessionHolder) error {
+	_, err := createTapKubesharkResources(context.Background(), kubernetesProvider, createdResources, holder, "", false, testNamespace, 0, config.Config.Tap.HubResources, config.Config.Tap.GetBasenineResources(), config.Config.Tap.FrontResources, core.PullIfNotPresent, logging.INFO, false, false)
+	return err
+}
+
+func TestCreateTapResourcesInExistingNamespace(t *testing.T) {
+	namespacePath := fmt.Sprintf("/api/v1/namespaces/%s", testNamespace)
+	kubernetesProvider, server := newTestProvider(t, map[string]string{
+		namespacePath: fmt.Sprintf(`{"kind":"Namespace","apiVersion":"v1","metadata":{"name":%q}}`, testNamespace),
+	})
+	config.Config.Tap.Storage.Enabled = false
+
+	createdResources := &inventory{}
+	if err := createTestTapResources(kubernetesProvider, createdResources, newSessionHolder("admin", false)); err != nil {
+		t.Fatalf("unexpected error creating the resources in an existing namespace: %v", err)
+	}
+
+	for _, item := range createdResources.items {
+		if strings.HasPrefix(item.description, "Namespace ") {
+			t.Errorf("unexpected existing namespace recorded for the rollback")
+		}
+	}
+	if !server.hasObject(fmt.Sprintf("%s/pods/%s", namespacePath, kubernetes.HubPodName)) {
+		t.Errorf("expected pod %s to be applied", kubernetes.HubPodName)
+	}
+}
+
+func TestCreateTapResourcesOfTwoInstancesInOneNamespace(t *testing.T) {
+	kubernetesProvider, server := newTestProvider(t, nil)
+
+	var hubPodNames []string
+	for _, instance := range []string{kubernetes.DefaultInstance, "ks2"} {
+		kubernetes.SetInstance(instance)
+		if err := createTestTapResources(kubernetesProvider, &inventory{}, newSessionHolder("admin", false)); err != nil {
+			t.Fatalf("unexpected error creating the resources of instance %s: %v", instance, err)
+		}
+		hubPodNames = append(hubPodNames, kubernetes.HubPodName)
+	}
+
+	if hubPodNames[0] == hubPodNames[1] {
+		t.Fatalf("unexpected hub pod %s shared by the instances", hubPodNames[0])
+	}
+	for _, hubPodName := range hubPodNames {
+		if !server.hasObject(fmt.Sprintf("/api/v1/namespaces/%s/pods/%s", testNamespace, hubPodName)) {
+			t.Errorf("expected pod %s to be applied", hubPodName)
+		}
+	}
+}
+
+func TestCreateTapResourcesOfHeldSession(t *testing.T) {
+	kubernetesProvider, _ := newTestProvider(t, nil)
+
+	holder := newSessionHolder("admin", false)
+	if err := createTestTapResources(kubernetesProvider, &inventory{}, holder); err != nil {
+		t.Fatalf("unexpected error creating the resources: %v", err)
+	}
+
+	createdResources := &inventory{}
+	err := createTestTapResources(kubernetesProvider, createdResources, newSessionHolder("developer", false))
+	var sessionHeldErr *SessionHeldError
+	if !errors.As(err, &sessionHeldErr) {
+		t.Fatalf("unexpected error creating the resources of a held session - expected: %T, actual: %v", sessionHeldErr, err)
+	}
+	if sessionHeldErr.Holder.Identity() != holder.Identity() {
+		t.Errorf("unexpected holder - expected: %s, actual: %s", holder.Identity(), sessionHeldErr.Holder.Identity())
+	}
+	if len(createdResources.items) != 0 {
+		t.Errorf("unexpected %d resources recorded for the rollback of the held session", len(createdResources.items))
+	}
+}
@@ -0,0 +1,72 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/kubeshark/kubeshark/utils"
+)
+
+const rollbackTimeout = time.Minute
+
+type inventoryItem struct {
+	description string
+	remove      func(ctx context.Context) error
+}
+
+// inventory records the objects a run created, so a failed run can remove exactly those.
+// Objects that existed before the run are never recorded, so a rollback never touches them.
+type inventory struct {
+	items []inventoryItem
+}
+
+func (inventory *inventory) record(kind string, namespace string, name string, remove func(ctx context.Context) error) {
+	description := fmt.Sprintf("%s %s", kind, name)
+	if namespace != "" {
+		description = fmt.Sprintf("%s in namespace %s", description, namespace)
+	}
+
+	inventory.items = append(inventory.items, inventoryItem{
+		description: description,
+		remove:      remove,
+	})
+}
+
+// recordUnlessExisting records an object that is about to be applied, unless it existed before the run.
+// Recording ahead of the apply is safe, removing an object whose apply failed is a no-op.
+func (inventory *inventory) recordUnlessExisting(exists bool, kind string, namespace string, name string, remove func(ctx context.Context) error) {
+	if !exists {
+		inventory.record(kind, namespace, name, remove)
+	}
+}
+
+// rollback removes the recorded objects in reverse creation order and reports the ones it couldn't remove.
+// It doesn't use the creation context, which is already canceled when the run was interrupted.
+func (inventory *inventory) rollback() {
+	if len(inventory.items) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+
+	log.Printf("Rolling back the %d resources created by this run", len(inventory.items))
+
+	leftoverResources := make([]string, 0)
+	for i := len(inventory.items) - 1; i >= 0; i-- {
+		item := inventory.items[i]
+		if err := item.remove(ctx); err != nil {
+			handleDeletionError(err, item.description, &leftoverResources)
+		}
+	}
+
+	if len(leftoverResources) > 0 {
+		errMsg := "Failed to roll back the following resources."
+		for _, resource := range leftoverResources {
+			errMsg += "\n- " + resource
+		}
+		log.Printf(utils.Error, errMsg)
+	}
+}
@@ -0,0 +1,179 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"strconv"
+	"time"
+
+	"github.com/kubeshark/kubeshark/config"
+	"github.com/kubeshark/kubeshark/kubernetes"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// SessionHeldError is returned when the session of the instance is held by someone else.
+type SessionHeldError struct {
+	Namespace string
+	Holder    *kubernetes.SessionHolder
+}
+
+func (err *SessionHeldError) Error() string {
+	return fmt.Sprintf("the Kubeshark session in namespace %s is held by %s", err.Namespace, err.Holder)
+}
+
+// ForeignSession is a session of another holder that is still running.
+type ForeignSession struct {
+	Namespace string
+	Instance  string
+	Holder    *kubernetes.SessionHolder
+}
+
+func NewSessionHolder(kubernetesProvider *kubernetes.Provider, detached bool) *kubernetes.SessionHolder {
+	return newSessionHolder(kubernetesProvider.GetKubeUser(), detached)
+}
+
+// newSessionHolder returns the holder of this run, its session is the process id and a random suffix so two
+// runs of a user on a host never take over each other's session.
+func newSessionHolder(kubeUser string, detached bool) *kubernetes.SessionHolder {
+	userName := os.Getenv("USER")
+	if currentUser, err := user.Current(); err == nil {
+		userName = currentUser.Username
+	}
+	hostName, _ := os.Hostname()
+	startTime := time.Now()
+	suffix, err := generateRandomSecret(4)
+	if err != nil {
+		suffix = strconv.FormatInt(startTime.UnixNano(), 36)
+	}
+
+	return &kubernetes.SessionHolder{
+		User:      userName,
+		Host:      hostName,
+		Session:   fmt.Sprintf("%d-%s", os.Getpid(), suffix),
+		KubeUser:  kubeUser,
+		StartTime: startTime,
+		Detached:  detached,
+	}
+}
+
+// acquireSessionLock takes the lease that locks the session of the instance. A session can be taken over when
+// it is held by the same run or its holder stopped renewing the lease, and a detached session when attaching.
+func acquireSessionLock(ctx context.Context, kubernetesProvider *kubernetes.Provider, createdResources *inventory, kubesharkResourcesNamespace string, holder *kubernetes.SessionHolder, attach bool) error {
+	duration := config.Config.Tap.Reaper.GetGracePeriod()
+
+	lease, err := kubernetesProvider.GetLease(ctx, kubesharkResourcesNamespace, kubernetes.LeaseName)
+	if k8serrors.IsNotFound(err) {
+		if attach {
+			return fmt.Errorf("there is no Kubeshark session to attach to in namespace %s", kubesharkResourcesNamespace)
+		}
+
+		if _, err := kubernetesProvider.CreateLease(ctx, kubesharkResourcesNamespace, kubernetes.LeaseName, holder, duration); k8serrors.IsAlreadyExists(err) {
+			return getSessionHeldError(ctx, kubernetesProvider, kubesharkResourcesNamespace)
+		} else if err != nil {
+			return err
+		}
+		createdResources.record("Lease", kubesharkResourcesNamespace, kubernetes.LeaseName, func(ctx context.Context) error {
+			return kubernetesProvider.RemoveLease(ctx, kubesharkResourcesNamespace, kubernetes.LeaseName)
+		})
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	currentHolder := kubernetes.GetLeaseHolder(lease)
+	switch {
+	case currentHolder.Identity() == holder.Identity():
+	case kubernetes.IsLeaseExpired(lease):
+		log.Printf("Taking over the session of %s, it stopped renewing lease %s", currentHolder, kubernetes.LeaseName)
+	case attach && currentHolder.Detached:
+		log.Printf("Attaching to the session of %s", currentHolder)
+	default:
+		return &SessionHeldError{Namespace: kubesharkResourcesNamespace, Holder: currentHolder}
+	}
+
+	if _, err := kubernetesProvider.TakeOverLease(ctx, lease, holder, duration); k8serrors.IsConflict(err) {
+		return getSessionHeldError(ctx, kubernetesProvider, kubesharkResourcesNamespace)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// getSessionHeldError reports the holder of a lease another session acquired while this one tried to.
+func getSessionHeldError(ctx context.Context, kubernetesProvider *kubernetes.Provider, kubesharkResourcesNamespace string) error {
+	lease, err := kubernetesProvider.GetLease(ctx, kubesharkResourcesNamespace, kubernetes.LeaseName)
+	if err != nil {
+		return err
+	}
+	return &SessionHeldError{Namespace: kubesharkResourcesNamespace, Holder: kubernetes.GetLeaseHolder(lease)}
+}
+
+// AttachTapSession takes over a detached session, or a session whose holder stopped renewing its lease,
+// without creating its resources again.
+func AttachTapSession(ctx context.Context, kubernetesProvider *kubernetes.Provider, isNsRestrictedMode bool, kubesharkResourcesNamespace string, holder *kubernetes.SessionHolder) error {
+	if err := acquireSessionLock(ctx, kubernetesProvider, &inventory{}, kubesharkResourcesNamespace, holder, true); err != nil {
+		return err
+	}
+
+	if config.Config.Tap.IsReaperNeeded() {
+		if err := createKubesharkReaper(ctx, kubernetesProvider, &inventory{}, isNsRestrictedMode, kubesharkResourcesNamespace); err != nil {
+			return newReaperError(err)
+		}
+	}
+	return nil
+}
+
+// IsSessionHeldBy reports whether the session of the instance is still held by holder, a session without a
+// lease was started by an earlier version and belongs to whoever cleans it up.
+func IsSessionHeldBy(ctx context.Context, kubernetesProvider *kubernetes.Provider, kubesharkResourcesNamespace string, holder *kubernetes.SessionHolder) (bool, error) {
+	lease, err := kubernetesProvider.GetLease(ctx, kubesharkResourcesNamespace, kubernetes.LeaseName)
+	if k8serrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return kubernetes.GetLeaseHolder(lease).Identity() == holder.Identity(), nil
+}
+
+// ListForeignSessions lists the running sessions of other holders among those clean would remove.
+func ListForeignSessions(ctx context.Context, kubernetesProvider *kubernetes.Provider, kubesharkResourcesNamespace string, opts CleanUpOptions, holder *kubernetes.SessionHolder) ([]ForeignSession, error) {
+	namespace := kubesharkResourcesNamespace
+	if opts.AllNamespaces {
+		namespace = kubernetes.K8sAllNamespaces
+	}
+
+	leases, err := kubernetesProvider.ListManagedObjects(ctx, kubernetes.KindLease, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var foreignSessions []ForeignSession
+	for _, object := range leases {
+		if !opts.AllInstances && object.Instance != kubernetes.Instance {
+			continue
+		}
+
+		lease, err := kubernetesProvider.GetLease(ctx, object.Namespace, object.Name)
+		if k8serrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		leaseHolder := kubernetes.GetLeaseHolder(lease)
+		if leaseHolder.Identity() == holder.Identity() || kubernetes.IsLeaseExpired(lease) {
+			continue
+		}
+		foreignSessions = append(foreignSessions, ForeignSession{
+			Namespace: object.Namespace,
+			Instance:  object.Instance,
+			Holder:    leaseHolder,
+		})
+	}
+
+	return foreignSessions, nil
+}
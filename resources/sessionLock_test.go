@@ -0,0 +1,17 @@
+package resources
+
+import (
+	"testing"
+)
+
+func TestNewSessionHolder(t *testing.T) {
+	holder := newSessionHolder("admin", false)
+	otherHolder := newSessionHolder("admin", false)
+
+	if holder.User != otherHolder.User || holder.Host != otherHolder.Host {
+		t.Fatalf("unexpected holders of different users or hosts - %v, %v", holder, otherHolder)
+	}
+	if holder.Identity() == otherHolder.Identity() {
+		t.Errorf("unexpected identity shared by two runs - %v", holder.Identity())
+	}
+}